@@ -1,5 +1,11 @@
 package config
 
+// Telegram modes of receiving updates.
+const (
+	TelegramWebhook = "webhook"
+	TelegramPolling = "polling"
+)
+
 type (
 	// Config -.
 	Config struct {
@@ -10,6 +16,8 @@ type (
 		Telegram `yaml:"telegram"`
 		Vk       `yaml:"vk"`
 		Grabber  `yaml:"grabber"`
+		Delivery `yaml:"delivery"`
+		Digest   `yaml:"digest"`
 	}
 
 	// App -.
@@ -36,17 +44,42 @@ type (
 
 	// Telegram -.
 	Telegram struct {
-		BaseURL string `env-required:"true" yaml:"base_url" env:"TELEGRAM_BASE_URL"`
-		Token   string `env-required:"true" env:"TELEGRAM_TOKEN"`
+		BaseURL        string   `env-required:"true" yaml:"base_url"        env:"TELEGRAM_BASE_URL"`
+		ChannelsURL    string   `env-required:"true" yaml:"channels_url"    env:"TELEGRAM_CHANNELS_URL"`
+		Mode           string   `env-required:"true" yaml:"mode"            env:"TELEGRAM_MODE"`
+		ParseMode      string   `env-required:"true" yaml:"parse_mode"      env:"TELEGRAM_PARSE_MODE"`
+		PollTimeout    int64    `env-required:"true" yaml:"poll_timeout"    env:"TELEGRAM_POLL_TIMEOUT"`
+		AllowedUpdates []string `env-required:"true" yaml:"allowed_updates" env:"TELEGRAM_ALLOWED_UPDATES"`
+		WebhookURL     string   `yaml:"webhook_url"    env:"TELEGRAM_WEBHOOK_URL"`
+		DeleteWebhook  bool     `yaml:"delete_webhook" env:"TELEGRAM_DELETE_WEBHOOK"`
+		SecretToken    string   `env:"TELEGRAM_SECRET_TOKEN"`
+		Token          string   `env-required:"true" env:"TELEGRAM_TOKEN"`
 	}
 
 	// Vk -.
 	Vk struct {
-		Token string `env-required:"true" env:"VK_TOKEN"`
+		Token        string `env-required:"true" env:"VK_TOKEN"`
+		FloodDelay   int64  `env-required:"true" yaml:"flood_delay"   env:"VK_FLOOD_DELAY"`
+		FloodRetries int    `env-required:"true" yaml:"flood_retries" env:"VK_FLOOD_RETRIES"`
 	}
 
 	// Grabber -.
 	Grabber struct {
-		Sleep int64 `env-required:"true" yaml:"sleep" env:"GRABBER_SLEEP"`
+		Sleep       int64   `env-required:"true" yaml:"sleep"        env:"GRABBER_SLEEP"`
+		Workers     int     `env-required:"true" yaml:"workers"      env:"GRABBER_WORKERS"`
+		MinInterval int64   `env-required:"true" yaml:"min_interval" env:"GRABBER_MIN_INTERVAL"`
+		MaxInterval int64   `env-required:"true" yaml:"max_interval" env:"GRABBER_MAX_INTERVAL"`
+		Jitter      float64 `env-required:"true" yaml:"jitter"       env:"GRABBER_JITTER"`
+	}
+
+	// Delivery -.
+	Delivery struct {
+		Sleep       int64 `env-required:"true" yaml:"sleep"        env:"DELIVERY_SLEEP"`
+		MaxAttempts int   `env-required:"true" yaml:"max_attempts" env:"DELIVERY_MAX_ATTEMPTS"`
+	}
+
+	// Digest -.
+	Digest struct {
+		Sleep int64 `env-required:"true" yaml:"sleep" env:"DIGEST_SLEEP"`
 	}
 )
@@ -10,6 +10,7 @@ require (
 	github.com/joho/godotenv v1.4.0
 	github.com/rs/zerolog v1.26.0
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/text v0.3.7
 	gopkg.in/khaiql/dbcleaner.v2 v2.3.0
 	gorm.io/driver/postgres v1.2.1
 	gorm.io/gorm v1.22.2
@@ -60,7 +61,6 @@ require (
 	go.uber.org/atomic v1.9.0 // indirect
 	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519 // indirect
 	golang.org/x/sys v0.0.0-20211106132015-ebca88c72f68 // indirect
-	golang.org/x/text v0.3.7 // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jokius/news-telegram-bot/config"
 	v1 "github.com/jokius/news-telegram-bot/internal/controller/http/v1"
+	"github.com/jokius/news-telegram-bot/internal/controller/telegram"
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/locales"
 	"github.com/jokius/news-telegram-bot/internal/usecase"
 	"github.com/jokius/news-telegram-bot/internal/usecase/repo"
 	"github.com/jokius/news-telegram-bot/internal/usecase/service"
@@ -18,7 +22,9 @@ import (
 	"github.com/jokius/news-telegram-bot/pkg/httpclient"
 	"github.com/jokius/news-telegram-bot/pkg/httpserver"
 	"github.com/jokius/news-telegram-bot/pkg/logger"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
 	"github.com/jokius/news-telegram-bot/pkg/postgres"
+	"github.com/jokius/news-telegram-bot/pkg/ratelimit"
 )
 
 // Run creates objects via constructors.
@@ -34,29 +40,95 @@ func Run(cfg *config.Config) {
 		l.Fatal(fmt.Errorf("app - Run - postgres.New: %w", err))
 	}
 
-	// Use case
+	// Sources
 	client := httpclient.NewClient()
-	source := service.NewVkSource(cfg.Vk.Token, client)
-	messenger := service.NewMessenger(cfg.Telegram.Token, cfg.Telegram.BaseURL, client, source, l)
+	vkSource := service.NewVkSource(cfg.Vk.Token, time.Duration(cfg.Vk.FloodDelay)*time.Second, cfg.Vk.FloodRetries,
+		client)
+	rssSource := service.NewRssSource(client)
+	telegramSource := service.NewTelegramSource(cfg.Telegram.ChannelsURL, client)
+	sources := service.NewSourceRegistry(vkSource, telegramSource, rssSource)
+
+	// Use case
+	renderer, err := markup.New(cfg.Telegram.ParseMode)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - markup.New: %w", err))
+	}
+
+	translator, err := locales.New()
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - locales.New: %w", err))
+	}
+
+	userRepo := repo.NewUserRepo(pg)
+	messenger := service.NewMessenger(cfg.Telegram.Token, cfg.Telegram.BaseURL, client, vkSource, userRepo, renderer,
+		translator, ratelimit.New(), l)
+	botUsername, err := messenger.BotUsername()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - messenger.BotUsername: %w", err))
+	}
+
 	userUseCase := usecase.NewUserUseCase(
-		repo.NewUserRepo(pg),
+		userRepo,
 		messenger,
-		source,
+		sources,
+		translator.Languages(),
+		botUsername,
 	)
 
+	if err = userUseCase.RegisterCommands(); err != nil {
+		l.Error(fmt.Errorf("app - Run - userUseCase.RegisterCommands: %w", err))
+	}
+
 	// HTTP Server
 	handler := gin.New()
-	v1.NewRouter(handler, l, userUseCase, cfg.Telegram.Token)
+	v1.NewRouter(handler, l, userUseCase, cfg.Telegram.Token, cfg.Telegram.SecretToken)
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
-	// Grabbers server
-	var apiGrabbers []grabber.Grabber
+	// Telegram updates
+	var (
+		poller       *telegram.Poller
+		pollerNotify <-chan error
+	)
+
+	webhook := telegram.NewWebhook(cfg.Telegram.Token, cfg.Telegram.BaseURL, client)
 
+	if cfg.Telegram.Mode == config.TelegramPolling {
+		if err = webhook.Delete(); err != nil {
+			l.Error(fmt.Errorf("app - Run - webhook.Delete: %w", err))
+		}
+
+		pollTimeout := time.Duration(cfg.Telegram.PollTimeout) * time.Second
+		poller = telegram.New(cfg.Telegram.Token, cfg.Telegram.BaseURL, userUseCase, l,
+			telegram.PollTimeout(pollTimeout), telegram.AllowedUpdates(cfg.Telegram.AllowedUpdates))
+		pollerNotify = poller.Notify()
+	} else {
+		registerWebhook(cfg, webhook, l)
+	}
+
+	// Grabbers server
 	sleepTime := time.Duration(cfg.Grabber.Sleep) * time.Second
-	groupRepo := repo.NewGroupRepo(pg)
+	schedule := entity.Schedule{
+		Min:    time.Duration(cfg.Grabber.MinInterval) * time.Second,
+		Max:    time.Duration(cfg.Grabber.MaxInterval) * time.Second,
+		Jitter: cfg.Grabber.Jitter,
+	}
+	feedRepo := repo.NewFeedRepo(pg)
 	messageRepo := repo.NewMessageRepo(pg)
-	vkGrabber := service.NewVkGrabber(sleepTime, source, messenger, groupRepo, messageRepo, l)
-	apiGrabbers = append(apiGrabbers, &vkGrabber)
+
+	var apiGrabbers []grabber.Grabber
+	for _, source := range sources.All() {
+		apiGrabbers = append(apiGrabbers, service.NewGrabber(sleepTime, cfg.Grabber.Workers, schedule, source,
+			feedRepo, messageRepo, renderer, translator, l))
+	}
+
+	deliverySleep := time.Duration(cfg.Delivery.Sleep) * time.Second
+	dispatcher := service.NewDispatcher(deliverySleep, cfg.Delivery.MaxAttempts, messenger, repo.NewDeliveryRepo(pg), l)
+	apiGrabbers = append(apiGrabbers, dispatcher)
+
+	digestSleep := time.Duration(cfg.Digest.Sleep) * time.Second
+	digester := service.NewDigester(digestSleep, repo.NewDigestRepo(pg), renderer, translator, l)
+	apiGrabbers = append(apiGrabbers, digester)
+
 	grabbersServer := grabber.New(apiGrabbers)
 
 	// Waiting signal
@@ -68,6 +140,8 @@ func Run(cfg *config.Config) {
 		l.Info("app - Run - signal: " + s.String())
 	case err = <-httpServer.Notify():
 		l.Error(fmt.Errorf("app - Run - httpServer.Notify: %w", err))
+	case err = <-pollerNotify:
+		l.Error(fmt.Errorf("app - Run - poller.Notify: %w", err))
 	}
 
 	// Shutdown
@@ -76,5 +150,47 @@ func Run(cfg *config.Config) {
 		l.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
 	}
 
-	grabbersServer.Shutdown()
+	if poller != nil {
+		err = poller.Shutdown()
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - poller.Shutdown: %w", err))
+		}
+	}
+
+	if cfg.Telegram.Mode == config.TelegramWebhook && cfg.Telegram.DeleteWebhook {
+		err = webhook.Delete()
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - webhook.Delete: %w", err))
+		}
+	}
+
+	err = grabbersServer.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - grabbersServer.Shutdown: %w", err))
+	}
+}
+
+func registerWebhook(cfg *config.Config, webhook *telegram.Webhook, l logger.InterfaceLogger) {
+	if cfg.Telegram.WebhookURL == "" {
+		l.Warn("app - Run - webhook url is empty, webhook must be registered manually")
+	} else {
+		url := strings.TrimRight(cfg.Telegram.WebhookURL, "/") + "/v1/telegram/callback/" + cfg.Telegram.Token
+
+		err := webhook.Register(url, cfg.Telegram.SecretToken, cfg.Telegram.AllowedUpdates)
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - webhook.Register: %w", err))
+		}
+	}
+
+	info, err := webhook.Info()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - webhook.Info: %w", err))
+
+		return
+	}
+
+	if info.LastErrorMessage != "" {
+		l.Warn("app - Run - webhook last error at %s: %s, pending updates: %d",
+			time.Unix(info.LastErrorDate, 0).UTC(), info.LastErrorMessage, info.PendingUpdateCount)
+	}
 }
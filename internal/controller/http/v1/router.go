@@ -9,7 +9,7 @@ import (
 	"github.com/jokius/news-telegram-bot/pkg/logger"
 )
 
-func NewRouter(handler *gin.Engine, l logger.InterfaceLogger, user usecase.User, token string) {
+func NewRouter(handler *gin.Engine, l logger.InterfaceLogger, user usecase.User, token, secretToken string) {
 	// Options
 	handler.Use(gin.Logger())
 	handler.Use(gin.Recovery())
@@ -20,6 +20,6 @@ func NewRouter(handler *gin.Engine, l logger.InterfaceLogger, user usecase.User,
 	// Routers
 	h := handler.Group("/v1")
 	{
-		UserTelegramRoutes(h, user, token, l)
+		UserTelegramRoutes(h, user, token, secretToken, l)
 	}
 }
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 
@@ -11,12 +12,15 @@ import (
 )
 
 type userRoutes struct {
-	user usecase.User
-	l    logger.InterfaceLogger
+	user        usecase.User
+	secretToken string
+	l           logger.InterfaceLogger
 }
 
-func UserTelegramRoutes(handler *gin.RouterGroup, u usecase.User, token string, l logger.InterfaceLogger) {
-	r := &userRoutes{u, l}
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+func UserTelegramRoutes(handler *gin.RouterGroup, u usecase.User, token, secretToken string, l logger.InterfaceLogger) {
+	r := &userRoutes{u, secretToken, l}
 
 	h := handler.Group("/telegram")
 	{
@@ -25,6 +29,14 @@ func UserTelegramRoutes(handler *gin.RouterGroup, u usecase.User, token string,
 }
 
 func (r *userRoutes) telegramCallback(c *gin.Context) {
+	header := c.GetHeader(secretTokenHeader)
+	if r.secretToken != "" && subtle.ConstantTimeCompare([]byte(header), []byte(r.secretToken)) != 1 {
+		r.l.Error(fmt.Errorf("`r.telegramCallback` wrong %s header", secretTokenHeader))
+		c.Status(http.StatusUnauthorized)
+
+		return
+	}
+
 	var telegramResult entity.TelegramResult
 	err := c.ShouldBindJSON(&telegramResult)
 
@@ -0,0 +1,76 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/jokius/news-telegram-bot/internal/controller/http/v1"
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	callbackPath = "/v1/telegram/callback/token"
+	callbackBody = `{"update_id":1,"message":{"text":"/list","from":{"id":1}}}`
+)
+
+func router(t *testing.T, secretToken string) (*gin.Engine, *mocks.MockUser, *mocks.MockInterfaceLogger) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	mockCtl := gomock.NewController(t)
+	user := mocks.NewMockUser(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+
+	handler := gin.New()
+	v1.NewRouter(handler, logger, user, "token", secretToken)
+
+	return handler, user, logger
+}
+
+func callback(handler http.Handler, secretToken string) int {
+	req := httptest.NewRequest(http.MethodPost, callbackPath, strings.NewReader(callbackBody))
+	if secretToken != "" {
+		req.Header.Set("X-Telegram-Bot-Api-Secret-Token", secretToken)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return w.Code
+}
+
+func TestTelegramCallback(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with secret token", func(t *testing.T) {
+		t.Parallel()
+
+		handler, user, _ := router(t, "secret")
+		user.EXPECT().TelegramCallback(gomock.AssignableToTypeOf(entity.TelegramResult{})).Return(nil).Times(1)
+		assert.Equal(t, http.StatusNoContent, callback(handler, "secret"))
+	})
+
+	t.Run("with wrong secret token", func(t *testing.T) {
+		t.Parallel()
+
+		handler, _, logger := router(t, "secret")
+		logger.EXPECT().Error(gomock.Any()).Times(2)
+		assert.Equal(t, http.StatusUnauthorized, callback(handler, "wrong"))
+		assert.Equal(t, http.StatusUnauthorized, callback(handler, ""))
+	})
+
+	t.Run("without secret token in config", func(t *testing.T) {
+		t.Parallel()
+
+		handler, user, _ := router(t, "")
+		user.EXPECT().TelegramCallback(gomock.AssignableToTypeOf(entity.TelegramResult{})).Return(nil).Times(1)
+		assert.Equal(t, http.StatusNoContent, callback(handler, ""))
+	})
+}
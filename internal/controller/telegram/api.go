@@ -0,0 +1,57 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+)
+
+// api - bot api methods caller.
+type api struct {
+	baseURL string
+	client  httpclient.InterfaceClient
+}
+
+func newAPI(token, baseURL string, client httpclient.InterfaceClient) api {
+	if lastCh := baseURL[len(baseURL)-1:]; lastCh != "/" {
+		baseURL += "/"
+	}
+
+	return api{baseURL + token + "/", client}
+}
+
+func (a api) call(ctx context.Context, method string, params, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	res, err := a.client.PostContext(ctx, a.baseURL+method, body)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	var response entity.TelegramResponse
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return fmt.Errorf("%s status %d: %w", method, res.StatusCode, err)
+	}
+
+	if !response.Ok {
+		retryAfter := time.Duration(response.Parameters.RetryAfter) * time.Second
+
+		return errors.NewTelegramError(response.ErrorCode, response.Description, retryAfter)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(response.Result, result)
+}
@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"time"
+
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+)
+
+// Option -.
+type Option func(*Poller)
+
+// PollTimeout - long polling timeout of getUpdates. Default: 30s.
+func PollTimeout(timeout time.Duration) Option {
+	return func(p *Poller) {
+		p.pollTimeout = timeout
+	}
+}
+
+// RetryDelay - delay after failed getUpdates. Default: 3s.
+func RetryDelay(delay time.Duration) Option {
+	return func(p *Poller) {
+		p.retryDelay = delay
+	}
+}
+
+// ShutdownTimeout -.
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(p *Poller) {
+		p.shutdownTimeout = timeout
+	}
+}
+
+// Client - http client, by default with timeout longer than poll timeout.
+func Client(client httpclient.InterfaceClient) Option {
+	return func(p *Poller) {
+		p.client = client
+	}
+}
+
+// AllowedUpdates - update types to receive. Default: message, callback_query.
+func AllowedUpdates(updates []string) Option {
+	return func(p *Poller) {
+		p.allowedUpdates = updates
+	}
+}
@@ -0,0 +1,147 @@
+// Package telegram implements receiving telegram updates by long polling.
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+	"github.com/jokius/news-telegram-bot/pkg/logger"
+)
+
+const (
+	_defaultPollTimeout     = 30 * time.Second
+	_defaultRetryDelay      = 3 * time.Second
+	_defaultShutdownTimeout = 3 * time.Second
+)
+
+// Poller - calls getUpdates and passes every update to the user use case.
+type Poller struct {
+	api             api
+	client          httpclient.InterfaceClient
+	allowedUpdates  []string
+	user            usecase.User
+	l               logger.InterfaceLogger
+	offset          uint64
+	pollTimeout     time.Duration
+	retryDelay      time.Duration
+	shutdownTimeout time.Duration
+	ctx             context.Context
+	cancel          context.CancelFunc
+	done            chan struct{}
+	notify          chan error
+}
+
+// New - init and start polling.
+func New(token, baseURL string, user usecase.User, l logger.InterfaceLogger, opts ...Option) *Poller {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Poller{
+		allowedUpdates:  []string{"message", "callback_query"},
+		user:            user,
+		l:               l,
+		pollTimeout:     _defaultPollTimeout,
+		retryDelay:      _defaultRetryDelay,
+		shutdownTimeout: _defaultShutdownTimeout,
+		ctx:             ctx,
+		cancel:          cancel,
+		done:            make(chan struct{}),
+		notify:          make(chan error, 1),
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.client == nil {
+		p.client = httpclient.NewClient(httpclient.Timeout(p.pollTimeout + _defaultRetryDelay))
+	}
+
+	p.api = newAPI(token, baseURL, p.client)
+
+	p.start()
+
+	return p
+}
+
+func (p *Poller) start() {
+	go func() {
+		defer close(p.done)
+
+		for p.ctx.Err() == nil {
+			if err := p.poll(); err != nil {
+				p.retry(err)
+			}
+		}
+	}()
+}
+
+func (p *Poller) poll() error {
+	updates, err := p.getUpdates()
+	if err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		p.offset = update.UpdateID + 1
+
+		if err = p.user.TelegramCallback(update); err != nil {
+			p.l.Error(fmt.Errorf("`p.poll` something wrong: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func (p *Poller) retry(err error) {
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	if errors.IsTelegramFatal(err) {
+		p.notify <- err
+		p.cancel()
+
+		return
+	}
+
+	p.l.Error(fmt.Errorf("`p.poll` something wrong: %w", err))
+
+	select {
+	case <-p.ctx.Done():
+	case <-time.After(p.retryDelay):
+	}
+}
+
+func (p *Poller) getUpdates() (updates []entity.TelegramResult, err error) {
+	params := struct {
+		Offset         uint64   `json:"offset"`
+		Timeout        int64    `json:"timeout"`
+		AllowedUpdates []string `json:"allowed_updates"`
+	}{p.offset, int64(p.pollTimeout / time.Second), p.allowedUpdates}
+
+	err = p.api.call(p.ctx, "getUpdates", params, &updates)
+
+	return
+}
+
+// Notify - errors after which polling is stopped.
+func (p *Poller) Notify() <-chan error {
+	return p.notify
+}
+
+// Shutdown - stop polling and wait for the current update to be handled.
+func (p *Poller) Shutdown() error {
+	p.cancel()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(p.shutdownTimeout):
+		return errors.ErrShutdownTimeout
+	}
+}
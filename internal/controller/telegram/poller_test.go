@@ -0,0 +1,105 @@
+package telegram_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jokius/news-telegram-bot/internal/controller/telegram"
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type getUpdatesParams struct {
+	Offset  uint64 `json:"offset"`
+	Timeout int64  `json:"timeout"`
+}
+
+type getUpdatesResponse struct {
+	Ok          bool                    `json:"ok"`
+	Result      []entity.TelegramResult `json:"result"`
+	ErrorCode   int                     `json:"error_code"`
+	Description string                  `json:"description"`
+}
+
+func telegramServer(t *testing.T, handler func(params getUpdatesParams) getUpdatesResponse) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/token/getUpdates", r.URL.Path)
+
+		var params getUpdatesParams
+		assert.ErrorIs(t, json.NewDecoder(r.Body).Decode(&params), nil)
+		assert.ErrorIs(t, json.NewEncoder(w).Encode(handler(params)), nil)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestPoller(t *testing.T) {
+	t.Parallel()
+
+	mockCtl := gomock.NewController(t)
+	user := mocks.NewMockUser(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+
+	var once sync.Once
+
+	nextOffset := make(chan uint64)
+	server := telegramServer(t, func(params getUpdatesParams) getUpdatesResponse {
+		if params.Offset == 0 {
+			return getUpdatesResponse{Ok: true, Result: []entity.TelegramResult{{UpdateID: 10}, {UpdateID: 11}}}
+		}
+
+		once.Do(func() { nextOffset <- params.Offset })
+
+		return getUpdatesResponse{Ok: true}
+	})
+
+	gomock.InOrder(
+		user.EXPECT().TelegramCallback(entity.TelegramResult{UpdateID: 10}).Return(nil),
+		user.EXPECT().TelegramCallback(entity.TelegramResult{UpdateID: 11}).Return(nil),
+	)
+
+	poller := telegram.New("token", server.URL, user, logger, telegram.PollTimeout(time.Second))
+
+	select {
+	case offset := <-nextOffset:
+		assert.Equal(t, uint64(12), offset)
+	case <-time.After(time.Second):
+		t.Fatal("updates weren't handled")
+	}
+
+	require.ErrorIs(t, poller.Shutdown(), nil)
+}
+
+func TestPoller_fatal_error(t *testing.T) {
+	t.Parallel()
+
+	mockCtl := gomock.NewController(t)
+	user := mocks.NewMockUser(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+
+	server := telegramServer(t, func(getUpdatesParams) getUpdatesResponse {
+		return getUpdatesResponse{ErrorCode: http.StatusConflict, Description: "Conflict"}
+	})
+
+	poller := telegram.New("token", server.URL, user, logger)
+
+	select {
+	case err := <-poller.Notify():
+		assert.True(t, errors.IsTelegramFatal(err))
+	case <-time.After(time.Second):
+		t.Fatal("fatal error wasn't notified")
+	}
+
+	require.ErrorIs(t, poller.Shutdown(), nil)
+}
@@ -0,0 +1,41 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+)
+
+// Webhook - webhook registration in telegram.
+type Webhook struct {
+	api api
+}
+
+// NewWebhook -.
+func NewWebhook(token, baseURL string, client httpclient.InterfaceClient) *Webhook {
+	return &Webhook{newAPI(token, baseURL, client)}
+}
+
+// Register - setWebhook, telegram will send secretToken in X-Telegram-Bot-Api-Secret-Token header.
+func (w *Webhook) Register(url, secretToken string, allowedUpdates []string) error {
+	params := struct {
+		URL            string   `json:"url"`
+		SecretToken    string   `json:"secret_token,omitempty"`
+		AllowedUpdates []string `json:"allowed_updates"`
+	}{url, secretToken, allowedUpdates}
+
+	return w.api.call(context.Background(), "setWebhook", params, nil)
+}
+
+// Delete - deleteWebhook, pending updates are kept.
+func (w *Webhook) Delete() error {
+	return w.api.call(context.Background(), "deleteWebhook", struct{}{}, nil)
+}
+
+// Info - getWebhookInfo.
+func (w *Webhook) Info() (info entity.TelegramWebhookInfo, err error) {
+	err = w.api.call(context.Background(), "getWebhookInfo", struct{}{}, &info)
+
+	return
+}
@@ -0,0 +1,78 @@
+package telegram_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jokius/news-telegram-bot/internal/controller/telegram"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func webhookServer(t *testing.T, requests map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.ErrorIs(t, err, nil)
+
+		requests[r.URL.Path] = string(body)
+
+		switch r.URL.Path {
+		case "/token/getWebhookInfo":
+			_, err = io.WriteString(w, `{"ok":true,"result":{"url":"https://example.com","pending_update_count":2,`+
+				`"last_error_date":1637056800,"last_error_message":"Wrong response from the webhook: 502 Bad Gateway"}}`)
+		case "/token/setWebhook", "/token/deleteWebhook":
+			_, err = io.WriteString(w, `{"ok":true,"result":true}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, err = io.WriteString(w, `{"ok":false,"error_code":404,"description":"Not Found"}`)
+		}
+
+		assert.ErrorIs(t, err, nil)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+
+	requests := map[string]string{}
+	server := webhookServer(t, requests)
+	webhook := telegram.NewWebhook("token", server.URL, httpclient.NewClient())
+
+	err := webhook.Register("https://example.com/v1/telegram/callback/token", "secret", []string{"message"})
+	assert.ErrorIs(t, err, nil)
+
+	var params map[string]interface{}
+	assert.ErrorIs(t, json.Unmarshal([]byte(requests["/token/setWebhook"]), &params), nil)
+	assert.Equal(t, map[string]interface{}{
+		"url":             "https://example.com/v1/telegram/callback/token",
+		"secret_token":    "secret",
+		"allowed_updates": []interface{}{"message"},
+	}, params)
+
+	info, err := webhook.Info()
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, 2, info.PendingUpdateCount)
+	assert.Equal(t, "Wrong response from the webhook: 502 Bad Gateway", info.LastErrorMessage)
+
+	assert.ErrorIs(t, webhook.Delete(), nil)
+	assert.Contains(t, requests, "/token/deleteWebhook")
+}
+
+func TestWebhook_wrong_token(t *testing.T) {
+	t.Parallel()
+
+	server := webhookServer(t, map[string]string{})
+	webhook := telegram.NewWebhook("wrong", server.URL, httpclient.NewClient())
+
+	err := webhook.Delete()
+	assert.True(t, errors.IsTelegramFatal(err))
+}
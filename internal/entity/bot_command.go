@@ -0,0 +1,10 @@
+package entity
+
+// BotCommand - command of telegram menu, arguments and aliases are shown by /help only.
+// Description and Args are catalog keys, they are translated to language of the user.
+type BotCommand struct {
+	Command     string   `json:"command"`
+	Description string   `json:"description"`
+	Args        string   `json:"-"`
+	Aliases     []string `json:"-"`
+}
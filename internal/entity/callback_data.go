@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Actions of inline keyboard buttons.
+const (
+	CallbackList   = "l"
+	CallbackRemove = "r"
+	CallbackPause  = "p"
+	CallbackMute   = "m"
+)
+
+// CallbackDataLimit - telegram limit of callback_data in bytes.
+const CallbackDataLimit = 64
+
+const _callbackDataFields = 3
+
+// CallbackData - payload of inline keyboard button: action, subscription and list page to show after it.
+type CallbackData struct {
+	Action         string
+	SubscriptionID uint64
+	Page           int
+}
+
+// String - compact payload like "p:42:1", it fits the telegram limit for any ids.
+func (d CallbackData) String() string {
+	return d.Action + ":" + strconv.FormatUint(d.SubscriptionID, 10) + ":" + strconv.Itoa(d.Page)
+}
+
+func ParseCallbackData(data string) (d CallbackData, err error) {
+	if len(data) > CallbackDataLimit {
+		return d, fmt.Errorf("callback data is longer than %d bytes", CallbackDataLimit)
+	}
+
+	fields := strings.Split(data, ":")
+	if len(fields) != _callbackDataFields {
+		return d, fmt.Errorf("callback data %q: wrong format", data)
+	}
+
+	switch fields[0] {
+	case CallbackList, CallbackRemove, CallbackPause, CallbackMute:
+		d.Action = fields[0]
+	default:
+		return d, fmt.Errorf("callback data %q: unknown action", data)
+	}
+
+	if d.SubscriptionID, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+		return d, fmt.Errorf("callback data %q: %w", data, err)
+	}
+
+	if d.Page, err = strconv.Atoi(fields[2]); err != nil || d.Page < 0 {
+		return d, fmt.Errorf("callback data %q: wrong page", data)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Delivery statuses.
+const (
+	DeliveryPending = "pending"
+	DeliveryParked  = "parked"
+	DeliveryHeld    = "held"
+)
+
+// Delivery - outbox message to telegram chat, with media it is a photo, video, document or album with caption.
+// Silent delivery is sent with disabled notification. Post of paused subscription is held until it is resumed.
+type Delivery struct {
+	ID                  uint64        `gorm:"primaryKey"`
+	ChatID              uint64        `gorm:"not null"`
+	SubscriptionID      uint64        `gorm:"not null"`
+	Text                string        `gorm:"not null"`
+	ParseMode           string        `gorm:"not null"`
+	Media               DeliveryMedia `gorm:"type:jsonb;not null"`
+	DisableNotification bool          `gorm:"not null"`
+	Status              string        `gorm:"not null"`
+	Attempts            int           `gorm:"not null"`
+	NextAttemptAt       time.Time     `gorm:"not null"`
+	LastError           string        `gorm:"not null"`
+	CreatedAt           time.Time     `gorm:"not null"`
+	UpdatedAt           time.Time     `gorm:"not null"`
+}
+
+// Media - file sent to telegram by url, type is one of attachment types.
+type Media struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// DeliveryMedia - media of delivery stored as json.
+type DeliveryMedia []Media
+
+func (m DeliveryMedia) Value() (driver.Value, error) {
+	if m == nil {
+		return "[]", nil
+	}
+
+	value, err := json.Marshal(m)
+
+	return string(value), err
+}
+
+func (m *DeliveryMedia) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	case nil:
+		*m = nil
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported media type %T", value)
+	}
+}
@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	// timezones of users don't depend on the host.
+	_ "time/tzdata"
+)
+
+const (
+	_clockLayout = "15:04"
+)
+
+// Digest modes.
+const (
+	DigestInstant = "instant"
+	DigestHourly  = "hourly"
+	DigestDaily   = "daily"
+)
+
+// DigestItem - post waiting for digest of the user.
+type DigestItem struct {
+	ID        uint64    `gorm:"primaryKey"`
+	UserID    uint64    `gorm:"not null;index"`
+	FeedID    uint64    `gorm:"not null"`
+	Title     string    `gorm:"not null"`
+	Link      string    `gorm:"not null"`
+	PostAt    time.Time `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	Feed      Feed      `gorm:"foreignKey:FeedID"`
+}
+
+// ParseDigestAt - time of daily digest as HH:MM.
+func ParseDigestAt(text string) (string, error) {
+	return parseClock(text)
+}
+
+// parseClock - time of day as HH:MM.
+func parseClock(text string) (string, error) {
+	t, err := time.Parse(_clockLayout, text)
+	if err != nil {
+		return "", fmt.Errorf("time %q: %w", text, err)
+	}
+
+	return t.Format(_clockLayout), nil
+}
+
+// ParseTimezone - IANA timezone name like Europe/Moscow.
+func ParseTimezone(name string) (string, error) {
+	if name == "" || name == "Local" {
+		return "", fmt.Errorf("timezone %q: unknown time zone", name)
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return "", fmt.Errorf("timezone %q: %w", name, err)
+	}
+
+	return location.String(), nil
+}
+
+// Digest - posts are collected for digest instead of instant delivery.
+func (u *User) Digest() bool {
+	return u.DigestMode == DigestHourly || u.DigestMode == DigestDaily
+}
+
+// Location - timezone of the user, UTC for unknown one.
+func (u *User) Location() *time.Location {
+	location, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// NextDigest - the first digest time after t, nil for instant mode.
+// Hourly digest is sent at the start of every hour, daily one at DigestAt in timezone of the user.
+func (u *User) NextDigest(t time.Time) *time.Time {
+	var next time.Time
+
+	switch u.DigestMode {
+	case DigestHourly:
+		next = t.Truncate(time.Hour).Add(time.Hour)
+	case DigestDaily:
+		at, err := time.Parse(_clockLayout, u.DigestAt)
+		if err != nil {
+			return nil
+		}
+
+		local := t.In(u.Location())
+		next = time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, local.Location())
+
+		if !next.After(t) {
+			next = time.Date(local.Year(), local.Month(), local.Day()+1, at.Hour(), at.Minute(), 0, 0, local.Location())
+		}
+	default:
+		return nil
+	}
+
+	next = next.UTC()
+
+	return &next
+}
@@ -0,0 +1,80 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDigestAt(t *testing.T) {
+	t.Parallel()
+
+	at, err := entity.ParseDigestAt("08:30")
+	require.ErrorIs(t, err, nil)
+	assert.Equal(t, "08:30", at)
+
+	for _, text := range []string{"", "8", "25:00", "08:61", "morning"} {
+		_, err = entity.ParseDigestAt(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestParseTimezone(t *testing.T) {
+	t.Parallel()
+
+	timezone, err := entity.ParseTimezone("Europe/Moscow")
+	require.ErrorIs(t, err, nil)
+	assert.Equal(t, "Europe/Moscow", timezone)
+
+	for _, name := range []string{"", "Local", "Mars/Olympus"} {
+		_, err = entity.ParseTimezone(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestUserNextDigest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, 12, 4, 10, 20, 0, 0, time.UTC)
+
+	t.Run("instant", func(t *testing.T) {
+		t.Parallel()
+
+		user := entity.User{DigestMode: entity.DigestInstant}
+		assert.False(t, user.Digest())
+		assert.Nil(t, user.NextDigest(now))
+	})
+
+	t.Run("hourly", func(t *testing.T) {
+		t.Parallel()
+
+		user := entity.User{DigestMode: entity.DigestHourly}
+		assert.True(t, user.Digest())
+		assert.Equal(t, time.Date(2021, 12, 4, 11, 0, 0, 0, time.UTC), *user.NextDigest(now))
+	})
+
+	t.Run("daily later today", func(t *testing.T) {
+		t.Parallel()
+
+		// 10:20 UTC is 13:20 in Moscow
+		user := entity.User{DigestMode: entity.DigestDaily, DigestAt: "18:00", Timezone: "Europe/Moscow"}
+		assert.Equal(t, time.Date(2021, 12, 4, 15, 0, 0, 0, time.UTC), *user.NextDigest(now))
+	})
+
+	t.Run("daily tomorrow", func(t *testing.T) {
+		t.Parallel()
+
+		user := entity.User{DigestMode: entity.DigestDaily, DigestAt: "08:00", Timezone: "Europe/Moscow"}
+		assert.Equal(t, time.Date(2021, 12, 5, 5, 0, 0, 0, time.UTC), *user.NextDigest(now))
+	})
+
+	t.Run("daily unknown timezone", func(t *testing.T) {
+		t.Parallel()
+
+		user := entity.User{DigestMode: entity.DigestDaily, DigestAt: "08:00", Timezone: "Mars/Olympus"}
+		assert.Equal(t, time.Date(2021, 12, 5, 8, 0, 0, 0, time.UTC), *user.NextDigest(now))
+	})
+}
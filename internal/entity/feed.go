@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+)
+
+// Feed - source group, fetched once for all subscribers. ErrorCount is the number of failed fetches in a row,
+// LastError is the error of the last one. PostInterval is the average gap between posts in seconds,
+// feed is fetched again at NextCheckAt. LastSeq is the highest Seq of saved posts.
+// BacklogCursor is the page of older posts not scanned yet, they are scanned down to BacklogSeq or BacklogSince.
+type Feed struct {
+	ID            uint64    `gorm:"primaryKey"`
+	SourceName    string    `gorm:"not null"`
+	Name          string    `gorm:"not null"`
+	LastUpdateAt  time.Time `gorm:"not null"`
+	ErrorCount    int       `gorm:"not null"`
+	LastError     string    `gorm:"not null"`
+	LastErrorAt   *time.Time
+	PostInterval  int64     `gorm:"not null"`
+	NextCheckAt   time.Time `gorm:"not null"`
+	LastSeq       uint64    `gorm:"not null"`
+	BacklogCursor string    `gorm:"not null"`
+	BacklogSeq    uint64    `gorm:"not null"`
+	BacklogSince  *time.Time
+	CreatedAt     time.Time      `gorm:"not null"`
+	UpdatedAt     time.Time      `gorm:"not null"`
+	Subscriptions []Subscription `gorm:"foreignKey:FeedID"`
+}
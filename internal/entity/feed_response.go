@@ -0,0 +1,57 @@
+package entity
+
+// FeedResponse - rss 2.0, rss 1.0 (rdf) and atom 1.0 documents.
+type FeedResponse struct {
+	Channel FeedChannel `xml:"channel"`
+	Items   []FeedItem  `xml:"item"`
+	Title   string      `xml:"title"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type FeedChannel struct {
+	Title string     `xml:"title"`
+	Items []FeedItem `xml:"item"`
+}
+
+type FeedItem struct {
+	About       string          `xml:"about,attr"`
+	GUID        string          `xml:"guid"`
+	Title       string          `xml:"title"`
+	Link        string          `xml:"link"`
+	Description string          `xml:"description"`
+	PubDate     string          `xml:"pubDate"`
+	Date        string          `xml:"date"`
+	Creator     string          `xml:"creator"`
+	Enclosures  []FeedEnclosure `xml:"enclosure"`
+}
+
+type FeedEnclosure struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type AtomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Author    AtomAuthor `xml:"author"`
+	Links     []AtomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// RssFeed - parsed rss or atom feed.
+type RssFeed struct {
+	Title string
+	Posts []Post
+}
@@ -0,0 +1,157 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var hashtag = regexp.MustCompile(`#[\p{L}\p{N}_]+`)
+
+// Filter - keyword, #hashtag or /regular expression/ of subscription, case is ignored.
+type Filter struct {
+	Exclude bool   `json:"exclude,omitempty"`
+	Regexp  bool   `json:"regexp,omitempty"`
+	Pattern string `json:"pattern"`
+}
+
+// ParseFilter - "+word" or "word" includes posts, "-word" excludes them, "/expr/" is regular expression.
+func ParseFilter(text string) (f Filter, err error) {
+	switch {
+	case strings.HasPrefix(text, "-"):
+		f.Exclude = true
+		text = text[1:]
+	case strings.HasPrefix(text, "+"):
+		text = text[1:]
+	}
+
+	if len(text) > 2 && strings.HasPrefix(text, "/") && strings.HasSuffix(text, "/") {
+		f.Regexp = true
+		text = text[1 : len(text)-1]
+
+		if _, err = regexp.Compile(text); err != nil {
+			return f, fmt.Errorf("filter %q: %w", text, err)
+		}
+	} else {
+		text = strings.ToLower(text)
+	}
+
+	if text == "" {
+		return f, fmt.Errorf("filter is empty")
+	}
+
+	f.Pattern = text
+
+	return f, nil
+}
+
+func (f Filter) String() string {
+	sign := "+"
+	if f.Exclude {
+		sign = "-"
+	}
+
+	if f.Regexp {
+		return sign + "/" + f.Pattern + "/"
+	}
+
+	return sign + f.Pattern
+}
+
+// match - #hashtag matches whole hashtag only, keyword matches any part of text.
+func (f Filter) match(text string, hashtags []string) bool {
+	switch {
+	case f.Regexp:
+		re, err := regexp.Compile("(?i)" + f.Pattern)
+
+		return err == nil && re.MatchString(text)
+	case strings.HasPrefix(f.Pattern, "#"):
+		for _, tag := range hashtags {
+			if tag == f.Pattern {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return strings.Contains(text, f.Pattern)
+	}
+}
+
+// Filters - filters of subscription stored as json.
+type Filters []Filter
+
+// Match - post has no excluded words and has any of included ones, post matches empty filters.
+// Title, text and reposts are checked.
+func (f Filters) Match(post *Post) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	text := strings.ToLower(postText(post))
+	hashtags := hashtag.FindAllString(text, -1)
+	included, hasIncluded := false, false
+
+	for _, filter := range f {
+		matched := filter.match(text, hashtags)
+
+		if filter.Exclude {
+			if matched {
+				return false
+			}
+
+			continue
+		}
+
+		hasIncluded = true
+		included = included || matched
+	}
+
+	return included || !hasIncluded
+}
+
+func (f Filters) String() string {
+	texts := make([]string, len(f))
+	for i, filter := range f {
+		texts[i] = filter.String()
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func (f Filters) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+
+	value, err := json.Marshal(f)
+
+	return string(value), err
+}
+
+func (f *Filters) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	case nil:
+		*f = nil
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported filters type %T", value)
+	}
+}
+
+func postText(post *Post) string {
+	var texts []string
+
+	for ; post != nil; post = post.Repost {
+		texts = append(texts, post.Title, post.Text)
+	}
+
+	return strings.Join(texts, "\n")
+}
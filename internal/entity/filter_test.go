@@ -0,0 +1,79 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func filters(t *testing.T, texts ...string) entity.Filters {
+	t.Helper()
+
+	result := make(entity.Filters, len(texts))
+
+	for i, text := range texts {
+		filter, err := entity.ParseFilter(text)
+		require.ErrorIs(t, err, nil)
+
+		result[i] = filter
+	}
+
+	return result
+}
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	for text, expected := range map[string]entity.Filter{
+		"Word":     {Pattern: "word"},
+		"+word":    {Pattern: "word"},
+		"-word":    {Exclude: true, Pattern: "word"},
+		"/go+/":    {Regexp: true, Pattern: "go+"},
+		"-/^ad$/":  {Exclude: true, Regexp: true, Pattern: "^ad$"},
+		"#Новости": {Pattern: "#новости"},
+	} {
+		filter, err := entity.ParseFilter(text)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expected, filter, text)
+	}
+
+	for _, text := range []string{"", "-", "+/(/"} {
+		_, err := entity.ParseFilter(text)
+		assert.NotNil(t, err, text)
+	}
+
+	assert.Equal(t, "+word -/^ad$/", filters(t, "word", "-/^ad$/").String())
+}
+
+func TestFiltersMatch(t *testing.T) {
+	t.Parallel()
+
+	post := &entity.Post{
+		Title:  "Release",
+		Text:   "Go 1.17 is out #golang #news@club1",
+		Repost: &entity.Post{Text: "Original Announcement"},
+	}
+
+	for _, test := range []struct {
+		filters []string
+		match   bool
+	}{
+		{nil, true},
+		{[]string{"GO"}, true},
+		{[]string{"rust"}, false},
+		{[]string{"rust", "release"}, true},
+		{[]string{"-announcement"}, false},
+		{[]string{"-ads"}, true},
+		{[]string{"go", "-out"}, false},
+		{[]string{"#golang"}, true},
+		{[]string{"#go"}, false},
+		{[]string{"#news"}, true},
+		{[]string{`/go 1\.\d+/`}, true},
+		{[]string{`-/^release/`}, false},
+		{[]string{`/\D\d\.\d{2}\D/`}, true},
+	} {
+		assert.Equal(t, test.match, filters(t, test.filters...).Match(post), test.filters)
+	}
+}
@@ -6,11 +6,11 @@ import (
 
 type Message struct {
 	ID        uint64    `gorm:"primaryKey"`
-	GroupID   uint64    `gorm:"not null;index"`
+	FeedID    uint64    `gorm:"not null;index"`
 	MessageID uint64    `gorm:"not null"`
+	GUID      string    `gorm:"column:guid;not null"`
 	Source    string    `gorm:"not null"`
 	MessageAt time.Time `gorm:"not null"`
 	CreatedAt time.Time `gorm:"not null"`
 	UpdatedAt time.Time `gorm:"not null"`
-	User      User      `gorm:"foreignKey:GroupID"`
 }
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+)
+
+// Post - source neutral post. Seq is the increasing number of the post in its group when the source has it,
+// zero otherwise. Pinned post is shown first regardless of its date.
+type Post struct {
+	ID          string
+	Seq         uint64
+	Pinned      bool
+	Author      string
+	Date        time.Time
+	Title       string
+	Text        string
+	Link        string
+	Attachments []Attachment
+	Repost      *Post
+}
+
+const (
+	AttachmentPhoto = "photo"
+	AttachmentVideo = "video"
+	AttachmentAudio = "audio"
+	AttachmentDoc   = "doc"
+	AttachmentLink  = "link"
+)
+
+type Attachment struct {
+	Type  string
+	URL   string
+	Title string
+}
+
+// PostsPage - posts sorted from new to old and the cursor of the next (older) page, empty when there are no more.
+type PostsPage struct {
+	Posts []Post
+	Next  string
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Quiet modes.
+const (
+	QuietHold   = "hold"
+	QuietSilent = "silent"
+)
+
+// ParseQuietHours - quiet hours as HH:MM-HH:MM, window may cross midnight.
+func ParseQuietHours(text string) (from, to string, err error) {
+	bounds := strings.Split(text, "-")
+	if len(bounds) != 2 {
+		return "", "", fmt.Errorf("quiet hours %q: expected HH:MM-HH:MM", text)
+	}
+
+	if from, err = parseClock(bounds[0]); err != nil {
+		return "", "", err
+	}
+
+	if to, err = parseClock(bounds[1]); err != nil {
+		return "", "", err
+	}
+
+	if from == to {
+		return "", "", fmt.Errorf("quiet hours %q: empty window", text)
+	}
+
+	return from, to, nil
+}
+
+// Quiet - user has quiet hours.
+func (u *User) Quiet() bool {
+	return u.QuietFrom != "" && u.QuietTo != ""
+}
+
+// QuietUntil - the end of quiet hours when t is in them, nil otherwise.
+func (u *User) QuietUntil(t time.Time) *time.Time {
+	if !u.Quiet() {
+		return nil
+	}
+
+	from, errFrom := time.Parse(_clockLayout, u.QuietFrom)
+	to, errTo := time.Parse(_clockLayout, u.QuietTo)
+
+	if errFrom != nil || errTo != nil {
+		return nil
+	}
+
+	local := t.In(u.Location())
+	minute := local.Hour()*60 + local.Minute()
+	fromMinute, toMinute := from.Hour()*60+from.Minute(), to.Hour()*60+to.Minute()
+
+	inside := minute >= fromMinute && minute < toMinute
+	if fromMinute > toMinute {
+		inside = minute >= fromMinute || minute < toMinute
+	}
+
+	if !inside {
+		return nil
+	}
+
+	end := time.Date(local.Year(), local.Month(), local.Day(), to.Hour(), to.Minute(), 0, 0, local.Location())
+	if !end.After(t) {
+		end = time.Date(local.Year(), local.Month(), local.Day()+1, to.Hour(), to.Minute(), 0, 0, local.Location())
+	}
+
+	end = end.UTC()
+
+	return &end
+}
@@ -0,0 +1,61 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	t.Parallel()
+
+	from, to, err := entity.ParseQuietHours("23:00-08:00")
+	require.ErrorIs(t, err, nil)
+	assert.Equal(t, "23:00", from)
+	assert.Equal(t, "08:00", to)
+
+	for _, text := range []string{"", "23:00", "23:00-", "23:00-24:30", "08:00-08:00", "1-2-3"} {
+		_, _, err = entity.ParseQuietHours(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestUserQuietUntil(t *testing.T) {
+	t.Parallel()
+
+	night := entity.User{QuietFrom: "23:00", QuietTo: "08:00", Timezone: "Europe/Moscow"}
+	lunch := entity.User{QuietFrom: "13:00", QuietTo: "14:00"}
+
+	for name, tc := range map[string]struct {
+		user     entity.User
+		t        time.Time
+		expected *time.Time
+	}{
+		"without quiet hours": {entity.User{}, time.Date(2021, 12, 4, 0, 0, 0, 0, time.UTC), nil},
+		// 21:30 UTC is 00:30 in Moscow
+		"after midnight": {night, time.Date(2021, 12, 4, 21, 30, 0, 0, time.UTC),
+			timePtr(time.Date(2021, 12, 5, 5, 0, 0, 0, time.UTC))},
+		"before midnight": {night, time.Date(2021, 12, 4, 20, 30, 0, 0, time.UTC),
+			timePtr(time.Date(2021, 12, 5, 5, 0, 0, 0, time.UTC))},
+		"at the end": {night, time.Date(2021, 12, 4, 5, 0, 0, 0, time.UTC), nil},
+		"day":        {night, time.Date(2021, 12, 4, 10, 0, 0, 0, time.UTC), nil},
+		"inside the same day": {lunch, time.Date(2021, 12, 4, 13, 10, 0, 0, time.UTC),
+			timePtr(time.Date(2021, 12, 4, 14, 0, 0, 0, time.UTC))},
+		"outside the same day": {lunch, time.Date(2021, 12, 4, 14, 10, 0, 0, time.UTC), nil},
+	} {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.user.QuietUntil(tc.t))
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"time"
+)
+
+// Schedule - bounds of the adaptive check interval of feeds. Jitter is the part of the interval
+// randomly added or subtracted, so feeds added together are not checked together.
+type Schedule struct {
+	Min    time.Duration
+	Max    time.Duration
+	Jitter float64
+}
+
+// ObservePosts - update average gap between posts by count of posts published during period since the last check.
+// Period without posts can only make the gap longer.
+func (f *Feed) ObservePosts(count int, period time.Duration) {
+	if period <= 0 {
+		return
+	}
+
+	gap := int64(period / time.Second)
+	if count > 0 {
+		gap /= int64(count)
+	} else if gap <= f.PostInterval {
+		return
+	}
+
+	if f.PostInterval == 0 {
+		f.PostInterval = gap
+	} else {
+		f.PostInterval = (f.PostInterval + gap) / 2
+	}
+}
+
+// Interval - feed is checked twice per its average gap between posts within Min and Max,
+// the shortest interval set by subscribers replaces it and may be longer than Max.
+// Every failed fetch in a row doubles the interval up to the longest one.
+func (s Schedule) Interval(feed *Feed) time.Duration {
+	interval := time.Duration(feed.PostInterval) * time.Second / 2
+	maxInterval := s.Max
+
+	if override := feed.CheckInterval(); override > 0 {
+		interval = override
+
+		if override > maxInterval {
+			maxInterval = override
+		}
+	}
+
+	if interval < s.Min {
+		interval = s.Min
+	}
+
+	for i := 0; i < feed.ErrorCount && interval < maxInterval; i++ {
+		interval *= 2
+	}
+
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	return interval
+}
+
+// NextCheck - time of the next check after t, random from 0 to 1 chooses the jitter.
+func (s Schedule) NextCheck(feed *Feed, t time.Time, random float64) time.Time {
+	interval := s.Interval(feed)
+	jitter := time.Duration((2*random - 1) * s.Jitter * float64(interval))
+
+	return t.Add(interval + jitter)
+}
+
+// CheckInterval - the shortest check interval set by subscribers, zero when nobody set it.
+func (f *Feed) CheckInterval() time.Duration {
+	var interval time.Duration
+
+	for i := range f.Subscriptions {
+		override := time.Duration(f.Subscriptions[i].CheckInterval) * time.Second
+		if override > 0 && (interval == 0 || override < interval) {
+			interval = override
+		}
+	}
+
+	return interval
+}
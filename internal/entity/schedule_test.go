@@ -0,0 +1,73 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedObservePosts(t *testing.T) {
+	t.Parallel()
+
+	feed := entity.Feed{}
+
+	feed.ObservePosts(4, 4*time.Hour)
+	assert.Equal(t, int64(3600), feed.PostInterval)
+
+	feed.ObservePosts(1, 3*time.Hour)
+	assert.Equal(t, int64(7200), feed.PostInterval, "average with the previous gap")
+
+	feed.ObservePosts(0, time.Hour)
+	assert.Equal(t, int64(7200), feed.PostInterval, "short period without posts")
+
+	feed.ObservePosts(0, 6*time.Hour)
+	assert.Equal(t, int64(14400), feed.PostInterval, "dormant group")
+
+	feed.ObservePosts(3, 0)
+	assert.Equal(t, int64(14400), feed.PostInterval, "empty period")
+}
+
+func TestScheduleInterval(t *testing.T) {
+	t.Parallel()
+
+	schedule := entity.Schedule{Min: 5 * time.Minute, Max: 6 * time.Hour}
+	hour := int64(time.Hour / time.Second)
+
+	for name, tc := range map[string]struct {
+		feed     entity.Feed
+		expected time.Duration
+	}{
+		"unknown activity": {entity.Feed{}, 5 * time.Minute},
+		"active":           {entity.Feed{PostInterval: 60}, 5 * time.Minute},
+		"regular":          {entity.Feed{PostInterval: 2 * hour}, time.Hour},
+		"dormant":          {entity.Feed{PostInterval: 100 * hour}, 6 * time.Hour},
+		"errors":           {entity.Feed{PostInterval: 2 * hour, ErrorCount: 2}, 4 * time.Hour},
+		"too many errors":  {entity.Feed{PostInterval: 2 * hour, ErrorCount: 10}, 6 * time.Hour},
+		"override": {entity.Feed{PostInterval: 100 * hour, Subscriptions: []entity.Subscription{
+			{CheckInterval: 0}, {CheckInterval: hour / 2}, {CheckInterval: hour},
+		}}, 30 * time.Minute},
+		"override longer than max": {entity.Feed{Subscriptions: []entity.Subscription{
+			{CheckInterval: 24 * hour},
+		}}, 24 * time.Hour},
+		"override shorter than min": {entity.Feed{Subscriptions: []entity.Subscription{
+			{CheckInterval: 60},
+		}}, 5 * time.Minute},
+	} {
+		feed := tc.feed
+		assert.Equal(t, tc.expected, schedule.Interval(&feed), name)
+	}
+}
+
+func TestScheduleNextCheck(t *testing.T) {
+	t.Parallel()
+
+	schedule := entity.Schedule{Min: time.Hour, Max: time.Hour, Jitter: 0.1}
+	now := time.Date(2021, 12, 8, 10, 0, 0, 0, time.UTC)
+	feed := entity.Feed{}
+
+	assert.Equal(t, now.Add(54*time.Minute), schedule.NextCheck(&feed, now, 0))
+	assert.Equal(t, now.Add(time.Hour), schedule.NextCheck(&feed, now, 0.5))
+	assert.Equal(t, now.Add(66*time.Minute), schedule.NextCheck(&feed, now, 1))
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	_maxMuteDuration = 365 * 24 * time.Hour
+	_maxInterval     = 7 * 24 * time.Hour
+)
+
+// durationUnits - units of mute duration and check interval.
+var durationUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// Subscription - user follows feed, posts older than StartAt are not delivered.
+// Paused or muted subscription doesn't get posts, posts not matching filters are skipped.
+// Posts of paused subscription are held for catching up on resume, posts of muted one are lost.
+// CheckInterval in seconds overrides the adaptive schedule of the feed, zero keeps it.
+// BrokenAt is set while the group is unavailable, user is told about it once.
+type Subscription struct {
+	ID            uint64    `gorm:"primaryKey"`
+	UserID        uint64    `gorm:"not null;index"`
+	FeedID        uint64    `gorm:"not null;index"`
+	StartAt       time.Time `gorm:"not null"`
+	Paused        bool      `gorm:"not null"`
+	MutedUntil    *time.Time
+	CheckInterval int64 `gorm:"not null"`
+	BrokenAt      *time.Time
+	Filters       Filters   `gorm:"type:jsonb;not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+	UpdatedAt     time.Time `gorm:"not null"`
+	User          User      `gorm:"foreignKey:UserID"`
+	Feed          Feed      `gorm:"foreignKey:FeedID"`
+}
+
+// Muted - posts are not delivered until MutedUntil.
+func (s *Subscription) Muted(t time.Time) bool {
+	return s.MutedUntil != nil && s.MutedUntil.After(t)
+}
+
+// Active - posts are delivered at t.
+func (s *Subscription) Active(t time.Time) bool {
+	return !s.Paused && !s.Muted(t)
+}
+
+// ParseMuteDuration - duration like 30m, 12h, 2d or 1w, up to a year.
+func ParseMuteDuration(text string) (time.Duration, error) {
+	return parseDuration("mute duration", text, _maxMuteDuration, "a year")
+}
+
+// ParseInterval - check interval like 15m, 2h or 1d, up to a week.
+func ParseInterval(text string) (time.Duration, error) {
+	return parseDuration("interval", text, _maxInterval, "a week")
+}
+
+func parseDuration(kind, text string, maxDuration time.Duration, maxText string) (time.Duration, error) {
+	if len(text) < 2 {
+		return 0, fmt.Errorf("%s %q: expected number with unit m, h, d or w", kind, text)
+	}
+
+	unit, ok := durationUnits[text[len(text)-1]]
+	if !ok {
+		return 0, fmt.Errorf("%s %q: unknown unit", kind, text)
+	}
+
+	count, err := strconv.Atoi(text[:len(text)-1])
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("%s %q: incorrect number", kind, text)
+	}
+
+	duration := time.Duration(count) * unit
+	if duration > maxDuration || duration/unit != time.Duration(count) {
+		return 0, fmt.Errorf("%s %q: more than %s", kind, text, maxText)
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,61 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMuteDuration(t *testing.T) {
+	t.Parallel()
+
+	for text, expected := range map[string]time.Duration{
+		"30m": 30 * time.Minute,
+		"12h": 12 * time.Hour,
+		"2d":  48 * time.Hour,
+		"1w":  7 * 24 * time.Hour,
+	} {
+		duration, err := entity.ParseMuteDuration(text)
+		require.ErrorIs(t, err, nil, text)
+		assert.Equal(t, expected, duration, text)
+	}
+
+	for _, text := range []string{"", "d", "2", "2y", "-1d", "0h", "400d", "99999999999999w"} {
+		_, err := entity.ParseMuteDuration(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	t.Parallel()
+
+	for text, expected := range map[string]time.Duration{
+		"15m": 15 * time.Minute,
+		"2h":  2 * time.Hour,
+		"1w":  7 * 24 * time.Hour,
+	} {
+		duration, err := entity.ParseInterval(text)
+		require.ErrorIs(t, err, nil, text)
+		assert.Equal(t, expected, duration, text)
+	}
+
+	for _, text := range []string{"", "auto", "15", "0m", "8d", "2w"} {
+		_, err := entity.ParseInterval(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestSubscriptionActive(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	assert.True(t, (&entity.Subscription{}).Active(now))
+	assert.False(t, (&entity.Subscription{Paused: true}).Active(now))
+	assert.False(t, (&entity.Subscription{MutedUntil: &later}).Active(now))
+	assert.True(t, (&entity.Subscription{MutedUntil: &now}).Active(later))
+}
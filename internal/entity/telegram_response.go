@@ -1,15 +1,73 @@
 package entity
 
+import (
+	"encoding/json"
+)
+
 type TelegramResult struct {
+	UpdateID      uint64                 `json:"update_id"`
+	Message       TelegramMessage        `json:"message"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query"`
+}
+
+// TelegramCallbackQuery - press of inline keyboard button.
+type TelegramCallbackQuery struct {
+	ID      string          `json:"id"`
+	User    TelegramUser    `json:"from"`
 	Message TelegramMessage `json:"message"`
+	Data    string          `json:"data"`
+}
+
+// TelegramResponse - bot api response.
+type TelegramResponse struct {
+	Ok          bool                       `json:"ok"`
+	Result      json.RawMessage            `json:"result"`
+	ErrorCode   int                        `json:"error_code"`
+	Description string                     `json:"description"`
+	Parameters  TelegramResponseParameters `json:"parameters"`
+}
+
+type TelegramResponseParameters struct {
+	RetryAfter int `json:"retry_after"`
+}
+
+// TelegramWebhookInfo - getWebhookInfo result.
+type TelegramWebhookInfo struct {
+	URL                  string   `json:"url"`
+	PendingUpdateCount   int      `json:"pending_update_count"`
+	LastErrorDate        int64    `json:"last_error_date"`
+	LastErrorMessage     string   `json:"last_error_message"`
+	AllowedUpdates       []string `json:"allowed_updates"`
+	HasCustomCertificate bool     `json:"has_custom_certificate"`
 }
 
 type TelegramMessage struct {
-	Text string       `json:"text"`
-	User TelegramUser `json:"from"`
+	MessageID uint64                  `json:"message_id"`
+	Text      string                  `json:"text"`
+	User      TelegramUser            `json:"from"`
+	Entities  []TelegramMessageEntity `json:"entities"`
+}
+
+// TelegramMessageEntity - special part of message text, offset and length are in UTF-16 code units.
+type TelegramMessageEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
 }
 
 type TelegramUser struct {
-	ID    uint64 `json:"id"`
-	IsBot bool   `json:"is_bot"`
+	ID           uint64 `json:"id"`
+	IsBot        bool   `json:"is_bot"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// TelegramInlineKeyboard - reply_markup with buttons under the message.
+type TelegramInlineKeyboard struct {
+	InlineKeyboard [][]TelegramInlineButton `json:"inline_keyboard"`
+}
+
+type TelegramInlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
 }
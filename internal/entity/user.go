@@ -4,9 +4,31 @@ import (
 	"time"
 )
 
+// User - LanguageCode comes from telegram, Language is chosen by /lang and overrides it.
+// Posts of digest mode are collected and sent together at NextDigestAt.
+// Posts in quiet hours from QuietFrom to QuietTo wait for their end or come without sound by QuietMode.
 type User struct {
-	ID         uint64    `gorm:"primaryKey"`
-	TelegramID uint64    `gorm:"not null;index"`
-	CreatedAt  time.Time `gorm:"not null"`
-	UpdatedAt  time.Time `gorm:"not null"`
+	ID           uint64 `gorm:"primaryKey"`
+	TelegramID   uint64 `gorm:"not null;index"`
+	Active       bool   `gorm:"not null;default:true"`
+	LanguageCode string `gorm:"not null;default:''"`
+	Language     string `gorm:"not null;default:''"`
+	Timezone     string `gorm:"not null;default:'UTC'"`
+	DigestMode   string `gorm:"not null;default:'instant'"`
+	DigestAt     string `gorm:"not null;default:''"`
+	NextDigestAt *time.Time
+	QuietFrom    string    `gorm:"not null;default:''"`
+	QuietTo      string    `gorm:"not null;default:''"`
+	QuietMode    string    `gorm:"not null;default:'hold'"`
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+// PreferredLanguage - language of bot replies, empty when unknown.
+func (u *User) PreferredLanguage() string {
+	if u.Language != "" {
+		return u.Language
+	}
+
+	return u.LanguageCode
 }
@@ -1,15 +1,82 @@
 package entity
 
+// VkResponse - result or error of vk api method.
 type VkResponse struct {
 	VkResult `json:"response"`
+	Error    *VkResponseError `json:"error"`
 }
 
+type VkResponseError struct {
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+// VkResult - wall.get response with extended=1, profiles and groups are authors of posts and reposts.
 type VkResult struct {
 	Messages []VkMessage `json:"items"`
+	Profiles []VkProfile `json:"profiles"`
+	Groups   []VkGroup   `json:"groups"`
 }
 
 type VkMessage struct {
+	ID          uint64         `json:"id"`
+	OwnerID     int64          `json:"owner_id"`
+	Date        int64          `json:"date"`
+	IsPinned    int            `json:"is_pinned"`
+	Text        string         `json:"text"`
+	Attachments []VkAttachment `json:"attachments"`
+	CopyHistory []VkMessage    `json:"copy_history"`
+}
+
+type VkAttachment struct {
+	Type  string   `json:"type"`
+	Photo *VkPhoto `json:"photo"`
+	Link  *VkLink  `json:"link"`
+	Video *VkVideo `json:"video"`
+	Doc   *VkDoc   `json:"doc"`
+}
+
+type VkPhoto struct {
+	ID      uint64        `json:"id"`
+	OwnerID int64         `json:"owner_id"`
+	Text    string        `json:"text"`
+	Sizes   []VkPhotoSize `json:"sizes"`
+}
+
+type VkPhotoSize struct {
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type VkLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+type VkVideo struct {
+	ID      uint64 `json:"id"`
+	OwnerID int64  `json:"owner_id"`
+	Title   string `json:"title"`
+}
+
+type VkDoc struct {
 	ID      uint64 `json:"id"`
 	OwnerID int64  `json:"owner_id"`
-	Date    int64  `json:"date"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+}
+
+type VkProfile struct {
+	ID         int64  `json:"id"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	ScreenName string `json:"screen_name"`
+}
+
+type VkGroup struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	ScreenName string `json:"screen_name"`
 }
@@ -0,0 +1,43 @@
+// Package locales contains message catalogs of bot replies.
+package locales
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+)
+
+// Default - language of users without language_code and of missing translations.
+const Default = "ru"
+
+//go:embed *.json
+var files embed.FS
+
+// New - bundle of all catalogs, file name is the language.
+func New() (*i18n.Bundle, error) {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("locales - New - files.ReadDir: %w", err)
+	}
+
+	opts := make([]i18n.Option, 0, len(entries))
+
+	for _, entry := range entries {
+		data, err := files.ReadFile(entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("locales - New - files.ReadFile: %w", err)
+		}
+
+		catalog, err := i18n.ParseCatalog(data)
+		if err != nil {
+			return nil, fmt.Errorf("locales - New - %s: %w", entry.Name(), err)
+		}
+
+		opts = append(opts, i18n.WithCatalog(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())), catalog))
+	}
+
+	return i18n.New(Default, opts...), nil
+}
@@ -0,0 +1,47 @@
+package locales_test
+
+import (
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/jokius/news-telegram-bot/internal/locales"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func catalogKeys(t *testing.T, lang string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(lang + ".json")
+	require.ErrorIs(t, err, nil)
+
+	catalog, err := i18n.ParseCatalog(data)
+	require.ErrorIs(t, err, nil)
+
+	keys := make([]string, 0, len(catalog))
+	for key := range catalog {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func TestCatalogs(t *testing.T) {
+	t.Parallel()
+
+	bundle, err := locales.New()
+	require.ErrorIs(t, err, nil)
+	assert.ElementsMatch(t, []string{"ru", "en"}, bundle.Languages())
+
+	defaultKeys := catalogKeys(t, locales.Default)
+	for _, lang := range bundle.Languages() {
+		assert.Equal(t, defaultKeys, catalogKeys(t, lang), "keys of %s catalog", lang)
+	}
+
+	assert.Equal(t, "Вы подписаны на 3 группы:", bundle.Plural("ru", "list.header", 3))
+	assert.Equal(t, "You are subscribed to 1 group:", bundle.Plural("en-US", "list.header", 1))
+}
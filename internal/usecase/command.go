@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+)
+
+const (
+	_botCommandEntity = "bot_command"
+)
+
+// command - bot command, aliases are not shown in telegram menu.
+type command struct {
+	name     string
+	aliases  []string
+	withArgs bool
+	minArgs  int
+	handler  func(user *entity.User, args []string)
+}
+
+// botCommand - command for /help and setMyCommands, description and arguments are catalog keys
+// "command.<name>" and "command.<name>.args".
+func (c *command) botCommand() entity.BotCommand {
+	description := "command." + c.name
+
+	var args string
+	if c.withArgs {
+		args = description + ".args"
+	}
+
+	aliases := make([]string, len(c.aliases))
+	for i, alias := range c.aliases {
+		aliases[i] = "/" + alias
+	}
+
+	return entity.BotCommand{Command: c.name, Description: description, Args: args, Aliases: aliases}
+}
+
+// commandRouter - finds handler of message command by name or alias.
+// Commands with @username suffix of other bot are not for it.
+type commandRouter struct {
+	botUsername string
+	commands    []*command
+	names       map[string]*command
+}
+
+func newCommandRouter(botUsername string, commands ...*command) *commandRouter {
+	router := &commandRouter{botUsername: botUsername, commands: commands, names: make(map[string]*command)}
+
+	for _, c := range commands {
+		router.names[c.name] = c
+		for _, alias := range c.aliases {
+			router.names[alias] = c
+		}
+	}
+
+	return router
+}
+
+// find - command of message and its arguments, nil for text without known command.
+// Message with command of other bot is ignored, ok is false then.
+func (r *commandRouter) find(message entity.TelegramMessage) (c *command, args []string, ok bool) {
+	name, bot, args, isCommand := parseCommand(message)
+	if !isCommand {
+		return nil, nil, true
+	}
+
+	if bot != "" && !strings.EqualFold(bot, r.botUsername) {
+		return nil, nil, false
+	}
+
+	return r.names[name], args, true
+}
+
+func (r *commandRouter) botCommands() []entity.BotCommand {
+	botCommands := make([]entity.BotCommand, len(r.commands))
+	for i, c := range r.commands {
+		botCommands[i] = c.botCommand()
+	}
+
+	return botCommands
+}
+
+// parseCommand - command name without slash, bot from @botname suffix and rest of the text split by spaces.
+// Message without bot_command entity, like in webhook tests and old clients, is parsed by leading slash.
+func parseCommand(message entity.TelegramMessage) (name, bot string, args []string, ok bool) {
+	text := strings.TrimSpace(message.Text)
+	commandText, rest := "", ""
+
+	for _, messageEntity := range message.Entities {
+		if messageEntity.Type == _botCommandEntity && messageEntity.Offset == 0 {
+			commandText, rest = splitUTF16(message.Text, messageEntity.Length)
+
+			break
+		}
+	}
+
+	if commandText == "" {
+		if !strings.HasPrefix(text, "/") {
+			return "", "", nil, false
+		}
+
+		fields := strings.Fields(text)
+		commandText, rest = fields[0], strings.TrimPrefix(text, fields[0])
+	}
+
+	name = strings.TrimPrefix(commandText, "/")
+	if at := strings.Index(name, "@"); at >= 0 {
+		name, bot = name[:at], name[at+1:]
+	}
+
+	return strings.ToLower(name), bot, strings.Fields(rest), name != ""
+}
+
+// splitUTF16 - text split at offset in UTF-16 code units.
+func splitUTF16(text string, offset int) (head, tail string) {
+	encoded := utf16.Encode([]rune(text))
+	if offset > len(encoded) {
+		offset = len(encoded)
+	}
+
+	return string(utf16.Decode(encoded[:offset])), string(utf16.Decode(encoded[offset:]))
+}
@@ -2,6 +2,8 @@
 package usecase
 
 import (
+	"context"
+	"net/url"
 	"time"
 
 	"github.com/jokius/news-telegram-bot/internal/entity"
@@ -15,39 +17,99 @@ type (
 		TelegramCallback(entity.TelegramResult) error
 	}
 
-	// Messenger - send message to telegram.
+	// Messenger - send message to telegram, replies are sent to the user in their language.
 	Messenger interface {
-		URLAdded(id uint64)
-		RemovedGroup(id uint64)
-		StartDateUpdated(id uint64)
-		GroupList(id uint64, groups []string)
-		IncorrectFormat(id uint64, command string)
-		UnknownSource(id uint64, url string)
-		UnknownError(id uint64, text string)
-		Message(id uint64, text string)
+		URLAdded(user *entity.User)
+		RemovedGroup(user *entity.User)
+		StartDateUpdated(user *entity.User)
+		SubscriptionList(user *entity.User, subscriptions []entity.Subscription, page int)
+		EditSubscriptionList(user *entity.User, messageID uint64, subscriptions []entity.Subscription, page int)
+		SubscriptionUpdated(user *entity.User, callbackID, action string, subscription *entity.Subscription)
+		SubscriptionNotFound(user *entity.User, callbackID string)
+		CallbackFailed(user *entity.User, callbackID string)
+		SubscriptionPaused(user *entity.User, subscription *entity.Subscription)
+		SubscriptionResumed(user *entity.User, subscription *entity.Subscription, missed int64, catchUp bool)
+		SubscriptionMuted(user *entity.User, subscription *entity.Subscription)
+		CheckIntervalUpdated(user *entity.User, subscription *entity.Subscription)
+		Welcome(user *entity.User, commands []entity.BotCommand)
+		Help(user *entity.User, commands []entity.BotCommand)
+		UnknownCommand(user *entity.User)
+		IncorrectFormat(user *entity.User, command entity.BotCommand)
+		UnknownSource(user *entity.User, url string)
+		UnknownError(user *entity.User, text string)
+		FiltersUpdated(user *entity.User, filters entity.Filters)
+		IncorrectFilter(user *entity.User, filter string)
+		NotSubscribed(user *entity.User, url string)
+		LanguageUpdated(user *entity.User)
+		LanguageList(user *entity.User, current string, languages []string)
+		DigestUpdated(user *entity.User)
+		DigestSettings(user *entity.User)
+		IncorrectTimezone(user *entity.User, timezone string)
+		QuietUpdated(user *entity.User)
+		QuietSettings(user *entity.User)
+		Send(id uint64, text, parseMode string, silent bool) (err error)
+		SendMedia(id uint64, media []entity.Media, caption, parseMode string, silent bool) (err error)
+		SetCommands(commands []entity.BotCommand, languageCode string) (err error)
 	}
 
 	// Source - to work with groups source.
 	Source interface {
 		Name() string
-		GetGroupMessages(id string, offset int) (result entity.VkResult, err error)
+		GroupName(u *url.URL) (name string, ok bool)
+		GetPosts(ctx context.Context, group, cursor string) (page entity.PostsPage, err error)
+	}
+
+	// Sources - registry of groups sources.
+	Sources interface {
+		Find(rawURL string) (source Source, groupName string, err error)
+		All() []Source
 	}
 
 	// UserRepo - user db interaction.
 	UserRepo interface {
-		AddGroupByURL(id uint64, source, url string) (err error)
+		AddGroup(id uint64, source, name string) (err error)
 		UpdateStartDate(id uint64, date time.Time) (err error)
-		RemoveGroup(id uint64, source, url string) (err error)
-		Groups(id uint64) (groups []entity.Group, err error)
+		RemoveGroup(id uint64, source, name string) (err error)
+		Subscriptions(id uint64) (subscriptions []entity.Subscription, err error)
+		Subscription(id, subscriptionID uint64) (subscription entity.Subscription, err error)
+		FindSubscription(id uint64, source, name string) (subscription entity.Subscription, err error)
+		UpdateSubscription(subscription *entity.Subscription) (err error)
+		ResumeSubscription(subscription *entity.Subscription, catchUp bool) (missed int64, err error)
+		UpdateCheckInterval(subscription *entity.Subscription) (err error)
+		RemoveSubscription(subscription *entity.Subscription) (err error)
+		SetFilters(id uint64, source, name string, filters entity.Filters) (err error)
+		SetActive(id uint64, active bool) (err error)
+		Activate(id uint64, languageCode string) (user entity.User, err error)
+		SetLanguage(id uint64, language string) (err error)
+		UpdateDigest(user *entity.User) (err error)
+		UpdateQuiet(user *entity.User) (err error)
 	}
 
-	GroupRepo interface {
-		AllBySource(source string) (groups []entity.Group, err error)
-		Update(group *entity.Group) (err error)
+	// FeedRepo - source groups shared by subscribers.
+	FeedRepo interface {
+		Due(source string, now time.Time) (feeds []entity.Feed, err error)
+		Update(feed *entity.Feed) (err error)
+		SetBroken(feedID uint64, brokenAt *time.Time, deliveries []entity.Delivery) (err error)
 	}
 
 	MessageRepo interface {
-		Add(groupID, messageID uint64, source string, messageAt time.Time) (err error)
-		Last(groupID uint64) (message entity.Message)
+		Add(feedID uint64, guid, source string, messageAt time.Time, deliveries []entity.Delivery,
+			digestItems []entity.DigestItem) (err error)
+		Exists(feedID uint64, guid string) (exists bool, err error)
+		Last(feedID uint64) (message entity.Message)
+	}
+
+	// DeliveryRepo - outbox of messages to telegram.
+	DeliveryRepo interface {
+		Due(now time.Time, limit int) (deliveries []entity.Delivery, err error)
+		Update(delivery *entity.Delivery) (err error)
+		Delete(delivery *entity.Delivery) (err error)
+	}
+
+	// DigestRepo - posts collected for digests of users.
+	DigestRepo interface {
+		Due(now time.Time, limit int) (users []entity.User, err error)
+		Items(userID uint64) (items []entity.DigestItem, err error)
+		Complete(user *entity.User, items []entity.DigestItem, deliveries []entity.Delivery) (err error)
 	}
 )
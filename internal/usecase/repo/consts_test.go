@@ -2,6 +2,7 @@ package repo_test
 
 const (
 	userID    = 1
-	groupID   = 1
+	feedID    = 1
 	messageID = 1
+	guid      = "1"
 )
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/postgres"
+)
+
+type DeliveryRepo struct {
+	db *postgres.Postgres
+}
+
+func NewDeliveryRepo(pg *postgres.Postgres) *DeliveryRepo {
+	return &DeliveryRepo{pg}
+}
+
+// Due - pending deliveries which next attempt is not after now, in order of creation.
+// Deliveries wait for earlier pending deliveries of the chat, so messages of the chat keep their order.
+func (d DeliveryRepo) Due(now time.Time, limit int) (deliveries []entity.Delivery, err error) {
+	err = d.db.Query.
+		Where(&entity.Delivery{Status: entity.DeliveryPending}).
+		Where("next_attempt_at <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM deliveries earlier WHERE earlier.chat_id = deliveries.chat_id AND "+
+			"earlier.status = ? AND earlier.id < deliveries.id AND earlier.next_attempt_at > ?)",
+			entity.DeliveryPending, now).
+		Order("id").
+		Limit(limit).
+		Find(&deliveries).Error
+
+	return
+}
+
+func (d DeliveryRepo) Update(delivery *entity.Delivery) (err error) {
+	delivery.UpdatedAt = time.Now().UTC()
+
+	return d.db.Query.Save(delivery).Error
+}
+
+func (d DeliveryRepo) Delete(delivery *entity.Delivery) (err error) {
+	return d.db.Query.Delete(delivery).Error
+}
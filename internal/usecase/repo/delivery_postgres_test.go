@@ -0,0 +1,106 @@
+package repo_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/repo"
+	"github.com/jokius/news-telegram-bot/pkg/postgres"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/khaiql/dbcleaner.v2"
+	"gopkg.in/khaiql/dbcleaner.v2/engine"
+)
+
+func buildDeliveryRepo(t *testing.T) (*postgres.Postgres, *repo.DeliveryRepo, dbcleaner.DbCleaner) {
+	t.Helper()
+
+	pgURL := os.Getenv("PG_URL_TEST")
+	pg, err := postgres.New(pgURL)
+	cleaner := dbcleaner.New()
+	pgEngine := engine.NewPostgresEngine(pgURL)
+	cleaner.SetEngine(pgEngine)
+
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - postgres.New: %w", err))
+	}
+
+	deliveryRepo := repo.NewDeliveryRepo(pg)
+
+	return pg, deliveryRepo, cleaner
+}
+
+func TestDueDeliveries(t *testing.T) {
+	pg, deliveryRepo, cleaner := buildDeliveryRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("deliveries")
+		cleaner.Clean("deliveries")
+
+		timeNow := time.Now().UTC()
+		media := entity.DeliveryMedia{{Type: entity.AttachmentPhoto, URL: "https://example.com/1.jpg"}}
+		due := entity.Delivery{ChatID: userID, Text: "due", Media: media, Status: entity.DeliveryPending,
+			NextAttemptAt: timeNow, CreatedAt: timeNow, UpdatedAt: timeNow}
+		later := entity.Delivery{ChatID: userID, Text: "later", Status: entity.DeliveryPending,
+			NextAttemptAt: timeNow.Add(time.Hour), CreatedAt: timeNow, UpdatedAt: timeNow}
+		parked := entity.Delivery{ChatID: userID, Text: "parked", Status: entity.DeliveryParked, NextAttemptAt: timeNow,
+			CreatedAt: timeNow, UpdatedAt: timeNow}
+		afterLater := entity.Delivery{ChatID: userID, Text: "after later", Status: entity.DeliveryPending,
+			NextAttemptAt: timeNow, CreatedAt: timeNow, UpdatedAt: timeNow}
+		otherChat := entity.Delivery{ChatID: userID + 1, Text: "other chat", Status: entity.DeliveryPending,
+			NextAttemptAt: timeNow, CreatedAt: timeNow, UpdatedAt: timeNow}
+
+		for _, delivery := range []*entity.Delivery{&due, &later, &parked, &afterLater, &otherChat} {
+			err := pg.Query.Create(delivery).Error
+			assert.ErrorIs(t, err, nil)
+		}
+
+		deliveries, err := deliveryRepo.Due(timeNow, 10)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, 2, len(deliveries))
+		assert.Equal(t, "due", deliveries[0].Text)
+		assert.Equal(t, media, deliveries[0].Media)
+		assert.Equal(t, "other chat", deliveries[1].Text, "after later waits for it")
+
+		cleaner.Clean("deliveries")
+	})
+}
+
+func TestUpdateDelivery(t *testing.T) {
+	pg, deliveryRepo, cleaner := buildDeliveryRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("deliveries")
+		cleaner.Clean("deliveries")
+
+		timeNow := time.Now().UTC()
+		delivery := entity.Delivery{ChatID: userID, Text: "text", Status: entity.DeliveryPending, NextAttemptAt: timeNow,
+			CreatedAt: timeNow, UpdatedAt: timeNow}
+		err := pg.Query.Create(&delivery).Error
+		assert.ErrorIs(t, err, nil)
+
+		delivery.Status = entity.DeliveryParked
+		delivery.LastError = "error"
+		err = deliveryRepo.Update(&delivery)
+		assert.ErrorIs(t, err, nil)
+
+		var updated entity.Delivery
+		err = pg.Query.Where(&entity.Delivery{ID: delivery.ID}).First(&updated).Error
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, entity.DeliveryParked, updated.Status)
+		assert.Equal(t, "error", updated.LastError)
+
+		err = deliveryRepo.Delete(&delivery)
+		assert.ErrorIs(t, err, nil)
+
+		var deleted entity.Delivery
+		pg.Query.Where(&entity.Delivery{ID: delivery.ID}).First(&deleted)
+		assert.Empty(t, deleted)
+
+		cleaner.Clean("deliveries")
+	})
+}
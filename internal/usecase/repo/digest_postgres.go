@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/postgres"
+	"gorm.io/gorm"
+)
+
+type DigestRepo struct {
+	db *postgres.Postgres
+}
+
+func NewDigestRepo(pg *postgres.Postgres) *DigestRepo {
+	return &DigestRepo{pg}
+}
+
+// Due - users which digest time is not after now.
+func (d DigestRepo) Due(now time.Time, limit int) (users []entity.User, err error) {
+	err = d.db.Query.
+		Where("next_digest_at <= ?", now).
+		Order("next_digest_at").
+		Limit(limit).
+		Find(&users).Error
+
+	return
+}
+
+// Items - posts waiting for digest of the user with their feeds, grouped by feed.
+func (d DigestRepo) Items(userID uint64) (items []entity.DigestItem, err error) {
+	err = d.db.Query.
+		Preload("Feed").
+		Where(&entity.DigestItem{UserID: userID}).
+		Order("feed_id, post_at, id").
+		Find(&items).Error
+
+	return
+}
+
+// Complete - enqueue digest deliveries, remove sent items and save the next digest time in one transaction.
+func (d DigestRepo) Complete(user *entity.User, items []entity.DigestItem, deliveries []entity.Delivery) error {
+	t := time.Now()
+
+	return d.db.Query.Transaction(func(tx *gorm.DB) error {
+		if err := enqueue(tx, deliveries, t); err != nil {
+			return err
+		}
+
+		if len(items) > 0 {
+			if err := tx.Delete(&items).Error; err != nil {
+				return err
+			}
+		}
+
+		user.UpdatedAt = t.UTC()
+
+		return tx.Model(user).Select("next_digest_at", "updated_at").Updates(user).Error
+	})
+}
@@ -0,0 +1,96 @@
+package repo_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/repo"
+	"github.com/jokius/news-telegram-bot/pkg/postgres"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/khaiql/dbcleaner.v2"
+	"gopkg.in/khaiql/dbcleaner.v2/engine"
+)
+
+func buildDigestRepo(t *testing.T) (*postgres.Postgres, *repo.DigestRepo, dbcleaner.DbCleaner) {
+	t.Helper()
+
+	pgURL := os.Getenv("PG_URL_TEST")
+	pg, err := postgres.New(pgURL)
+	cleaner := dbcleaner.New()
+	pgEngine := engine.NewPostgresEngine(pgURL)
+	cleaner.SetEngine(pgEngine)
+
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - postgres.New: %w", err))
+	}
+
+	digestRepo := repo.NewDigestRepo(pg)
+
+	return pg, digestRepo, cleaner
+}
+
+func TestDigest(t *testing.T) {
+	pg, digestRepo, cleaner := buildDigestRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		for _, table := range []string{"users", "feeds", "digest_items", "deliveries"} {
+			cleaner.Acquire(table)
+			cleaner.Clean(table)
+		}
+
+		timeNow := time.Now().UTC()
+		later := timeNow.Add(time.Hour)
+
+		due := entity.User{TelegramID: userID, DigestMode: entity.DigestHourly, NextDigestAt: &timeNow,
+			CreatedAt: timeNow, UpdatedAt: timeNow}
+		notDue := entity.User{TelegramID: userID + 1, DigestMode: entity.DigestHourly, NextDigestAt: &later,
+			CreatedAt: timeNow, UpdatedAt: timeNow}
+		feed := entity.Feed{SourceName: "vk", Name: "club1", LastUpdateAt: timeNow, CreatedAt: timeNow,
+			UpdatedAt: timeNow}
+
+		for _, record := range []interface{}{&due, &notDue, &feed} {
+			err := pg.Query.Create(record).Error
+			assert.ErrorIs(t, err, nil)
+		}
+
+		err := pg.Query.Create(&entity.DigestItem{UserID: due.ID, FeedID: feed.ID, Title: "title",
+			Link: "https://vk.com/wall-1_1", PostAt: timeNow, CreatedAt: timeNow}).Error
+		assert.ErrorIs(t, err, nil)
+
+		users, err := digestRepo.Due(timeNow, 10)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, 1, len(users))
+		assert.Equal(t, due.ID, users[0].ID)
+
+		items, err := digestRepo.Items(due.ID)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, 1, len(items))
+		assert.Equal(t, "club1", items[0].Feed.Name)
+
+		due.NextDigestAt = due.NextDigest(timeNow)
+		err = digestRepo.Complete(&due, items, []entity.Delivery{{ChatID: userID, Text: "digest"}})
+		assert.ErrorIs(t, err, nil)
+
+		items, err = digestRepo.Items(due.ID)
+		assert.ErrorIs(t, err, nil)
+		assert.Empty(t, items)
+
+		var delivery entity.Delivery
+		err = pg.Query.Where(&entity.Delivery{ChatID: userID}).First(&delivery).Error
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, entity.DeliveryPending, delivery.Status)
+
+		users, err = digestRepo.Due(timeNow, 10)
+		assert.ErrorIs(t, err, nil)
+		assert.Empty(t, users)
+
+		for _, table := range []string{"users", "feeds", "digest_items", "deliveries"} {
+			cleaner.Clean(table)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/postgres"
+	"gorm.io/gorm"
+)
+
+type FeedRepo struct {
+	db *postgres.Postgres
+}
+
+func NewFeedRepo(pg *postgres.Postgres) *FeedRepo {
+	return &FeedRepo{pg}
+}
+
+// Due - feeds of the source with active subscribers to check at now, only active subscriptions are preloaded.
+// Feeds which waited longer go first.
+func (f FeedRepo) Due(source string, now time.Time) (feeds []entity.Feed, err error) {
+	activeUsers := "user_id IN (SELECT id FROM users WHERE active)"
+
+	err = f.db.Query.
+		Preload("Subscriptions", activeUsers).
+		Preload("Subscriptions.User").
+		Model(&entity.Feed{}).
+		Where(&entity.Feed{SourceName: source}).
+		Where("next_check_at <= ?", now).
+		Where("EXISTS (SELECT 1 FROM subscriptions WHERE subscriptions.feed_id = feeds.id AND " + activeUsers + ")").
+		Order("next_check_at, id").
+		Find(&feeds).Error
+
+	return
+}
+
+func (f FeedRepo) Update(feed *entity.Feed) (err error) {
+	feed.UpdatedAt = time.Now().UTC()
+
+	return f.db.Query.Omit("Subscriptions").Save(feed).Error
+}
+
+// SetBroken - mark working subscriptions of the feed broken since brokenAt, nil marks broken ones working.
+// Deliveries with notices to subscribers are enqueued in the same transaction.
+func (f FeedRepo) SetBroken(feedID uint64, brokenAt *time.Time, deliveries []entity.Delivery) (err error) {
+	return f.db.Query.Transaction(func(tx *gorm.DB) error {
+		query := tx.
+			Model(&entity.Subscription{}).
+			Where(&entity.Subscription{FeedID: feedID})
+
+		if brokenAt != nil {
+			query = query.Where("broken_at IS NULL")
+		} else {
+			query = query.Where("broken_at IS NOT NULL")
+		}
+
+		if err := query.Update("broken_at", brokenAt).Error; err != nil {
+			return err
+		}
+
+		return enqueue(tx, deliveries, time.Now())
+	})
+}
@@ -0,0 +1,233 @@
+package repo_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/repo"
+	"github.com/jokius/news-telegram-bot/pkg/postgres"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/khaiql/dbcleaner.v2"
+	"gopkg.in/khaiql/dbcleaner.v2/engine"
+)
+
+func buildFeedRepo(t *testing.T) (*postgres.Postgres, *repo.FeedRepo, dbcleaner.DbCleaner) {
+	t.Helper()
+
+	pgURL := os.Getenv("PG_URL_TEST")
+	pg, err := postgres.New(pgURL)
+	cleaner := dbcleaner.New()
+	pgEngine := engine.NewPostgresEngine(pgURL)
+	cleaner.SetEngine(pgEngine)
+
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - postgres.New: %w", err))
+	}
+
+	feedRepo := repo.NewFeedRepo(pg)
+
+	return pg, feedRepo, cleaner
+}
+
+func createSubscription(t *testing.T, pg *postgres.Postgres, userID uint64, source, name string,
+	timeNow time.Time) (entity.Feed, entity.Subscription) {
+	t.Helper()
+
+	var feed entity.Feed
+	pg.Query.Where(&entity.Feed{SourceName: source, Name: name}).First(&feed)
+
+	if feed.ID == 0 {
+		feed = entity.Feed{SourceName: source, Name: name, LastUpdateAt: timeNow, CreatedAt: timeNow, UpdatedAt: timeNow}
+		err := pg.Query.Create(&feed).Error
+		assert.ErrorIs(t, err, nil)
+	}
+
+	subscription := entity.Subscription{UserID: userID, FeedID: feed.ID, StartAt: timeNow, CreatedAt: timeNow,
+		UpdatedAt: timeNow}
+	err := pg.Query.Create(&subscription).Error
+	assert.ErrorIs(t, err, nil)
+
+	return feed, subscription
+}
+
+func TestDueFeeds(t *testing.T) {
+	pg, feedRepo, cleaner := buildFeedRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+
+		timeNow := time.Now().UTC()
+		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
+		err := pg.Query.Create(&user).Error
+		assert.ErrorIs(t, err, nil)
+
+		otherUser := entity.User{TelegramID: userID + 1, CreatedAt: timeNow, UpdatedAt: timeNow}
+		err = pg.Query.Create(&otherUser).Error
+		assert.ErrorIs(t, err, nil)
+
+		createSubscription(t, pg, user.ID, "vk", "test_group", timeNow)
+		createSubscription(t, pg, otherUser.ID, "vk", "test_group", timeNow)
+		createSubscription(t, pg, user.ID, "other", "test_group", timeNow)
+
+		feeds, err := feedRepo.Due("vk", time.Now().UTC())
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, len(feeds), 1)
+		assert.Equal(t, len(feeds[0].Subscriptions), 2)
+		assert.NotEmpty(t, feeds[0].Subscriptions[0].User)
+
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+	})
+
+	t.Run("skip inactive users", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+
+		timeNow := time.Now().UTC()
+		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
+		err := pg.Query.Create(&user).Error
+		assert.ErrorIs(t, err, nil)
+
+		err = pg.Query.Model(&user).Update("active", false).Error
+		assert.ErrorIs(t, err, nil)
+
+		createSubscription(t, pg, user.ID, "vk", "test_group", timeNow)
+
+		feeds, err := feedRepo.Due("vk", time.Now().UTC())
+		assert.ErrorIs(t, err, nil)
+		assert.Empty(t, feeds)
+
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+	})
+
+	t.Run("skip feeds checked later", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+
+		timeNow := time.Now().UTC()
+		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
+		err := pg.Query.Create(&user).Error
+		assert.ErrorIs(t, err, nil)
+
+		feed, _ := createSubscription(t, pg, user.ID, "vk", "test_group", timeNow)
+		dueFeed, _ := createSubscription(t, pg, user.ID, "vk", "due_group", timeNow)
+
+		err = pg.Query.Model(&feed).Update("next_check_at", timeNow.Add(time.Hour)).Error
+		assert.ErrorIs(t, err, nil)
+
+		err = pg.Query.Model(&dueFeed).Update("next_check_at", timeNow.Add(-time.Minute)).Error
+		assert.ErrorIs(t, err, nil)
+
+		feeds, err := feedRepo.Due("vk", timeNow)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, len(feeds), 1)
+		assert.Equal(t, feeds[0].ID, dueFeed.ID)
+
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+	})
+}
+
+func TestUpdateFeed(t *testing.T) {
+	pg, feedRepo, cleaner := buildFeedRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("feeds")
+		cleaner.Clean("feeds")
+
+		timeNow := time.Now().UTC()
+		feed := entity.Feed{SourceName: "vk", Name: "test_group", LastUpdateAt: timeNow, CreatedAt: timeNow, UpdatedAt: timeNow}
+		err := pg.Query.Create(&feed).Error
+		assert.ErrorIs(t, err, nil)
+
+		dayBefore := timeNow.AddDate(0, 0, -1).UTC()
+		feed.LastUpdateAt = dayBefore
+		err = feedRepo.Update(&feed)
+		assert.ErrorIs(t, err, nil)
+
+		var updatedFeed entity.Feed
+		err = pg.Query.Where(&entity.Feed{ID: feed.ID}).First(&updatedFeed).Error
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, updatedFeed.LastUpdateAt, feed.LastUpdateAt)
+
+		cleaner.Clean("feeds")
+	})
+}
+
+func TestSetBroken(t *testing.T) {
+	pg, feedRepo, cleaner := buildFeedRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
+		cleaner.Acquire("deliveries")
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+		cleaner.Clean("deliveries")
+
+		timeNow := time.Now().UTC().Truncate(time.Second)
+		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
+		err := pg.Query.Create(&user).Error
+		assert.ErrorIs(t, err, nil)
+
+		feed, subscription := createSubscription(t, pg, user.ID, "vk", "test_group", timeNow)
+		_, other := createSubscription(t, pg, user.ID, "vk", "other_group", timeNow)
+
+		err = feedRepo.SetBroken(feed.ID, &timeNow, []entity.Delivery{{ChatID: userID, Text: "unavailable"}})
+		assert.ErrorIs(t, err, nil)
+
+		var notice entity.Delivery
+		pg.Query.Where(&entity.Delivery{ChatID: userID}).First(&notice)
+		assert.Equal(t, "unavailable", notice.Text)
+		assert.Equal(t, entity.DeliveryPending, notice.Status)
+
+		later := timeNow.Add(time.Hour)
+		err = feedRepo.SetBroken(feed.ID, &later, nil)
+		assert.ErrorIs(t, err, nil)
+
+		var found entity.Subscription
+		pg.Query.First(&found, subscription.ID)
+		assert.NotNil(t, found.BrokenAt)
+		assert.True(t, timeNow.Equal(*found.BrokenAt), "broken since the first error")
+
+		var foundOther entity.Subscription
+		pg.Query.First(&foundOther, other.ID)
+		assert.Nil(t, foundOther.BrokenAt)
+
+		err = feedRepo.SetBroken(feed.ID, nil, nil)
+		assert.ErrorIs(t, err, nil)
+
+		var repaired entity.Subscription
+		pg.Query.First(&repaired, subscription.ID)
+		assert.Nil(t, repaired.BrokenAt)
+
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+		cleaner.Clean("deliveries")
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/jokius/news-telegram-bot/internal/entity"
 	"github.com/jokius/news-telegram-bot/pkg/postgres"
+	"gorm.io/gorm"
 )
 
 type MessageRepo struct {
@@ -15,22 +16,72 @@ func NewMessageRepo(pg *postgres.Postgres) *MessageRepo {
 	return &MessageRepo{pg}
 }
 
-func (m MessageRepo) Add(groupID, messageID uint64, source string, messageAt time.Time) error {
+// Add - save message, enqueue its deliveries and store items of digests in one transaction.
+func (m MessageRepo) Add(feedID uint64, guid, source string, messageAt time.Time, deliveries []entity.Delivery,
+	digestItems []entity.DigestItem) error {
 	t := time.Now()
 	message := entity.Message{
-		GroupID:   groupID,
-		MessageID: messageID,
+		FeedID:    feedID,
+		GUID:      guid,
 		Source:    source,
 		MessageAt: messageAt,
 		CreatedAt: t,
 		UpdatedAt: t,
 	}
 
-	return m.db.Query.Create(&message).Error
+	return m.db.Query.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+
+		if err := enqueue(tx, deliveries, t); err != nil {
+			return err
+		}
+
+		if len(digestItems) == 0 {
+			return nil
+		}
+
+		for i := range digestItems {
+			digestItems[i].CreatedAt = t
+		}
+
+		return tx.Create(&digestItems).Error
+	})
+}
+
+// enqueue - save deliveries, pending ones are sent by dispatcher. Delivery in quiet hours keeps its first
+// attempt time, posts of paused subscriptions keep held status.
+func enqueue(tx *gorm.DB, deliveries []entity.Delivery, t time.Time) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	for i := range deliveries {
+		if deliveries[i].Status == "" {
+			deliveries[i].Status = entity.DeliveryPending
+		}
+
+		if deliveries[i].NextAttemptAt.IsZero() {
+			deliveries[i].NextAttemptAt = t
+		}
+
+		deliveries[i].CreatedAt = t
+		deliveries[i].UpdatedAt = t
+	}
+
+	return tx.Create(&deliveries).Error
+}
+
+func (m MessageRepo) Exists(feedID uint64, guid string) (bool, error) {
+	var count int64
+	err := m.db.Query.Model(&entity.Message{}).Where(&entity.Message{FeedID: feedID, GUID: guid}).Count(&count).Error
+
+	return count > 0, err
 }
 
-func (m MessageRepo) Last(groupID uint64) (message entity.Message) {
-	m.db.Query.Where(&entity.Message{GroupID: groupID}).Order("message_at desc").First(&message)
+func (m MessageRepo) Last(feedID uint64) (message entity.Message) {
+	m.db.Query.Where(&entity.Message{FeedID: feedID}).Order("message_at desc").First(&message)
 
 	return
 }
@@ -29,9 +29,9 @@ func buildMessageRepo(t *testing.T) (*postgres.Postgres, *repo.MessageRepo, dbcl
 		log.Fatal(fmt.Errorf("app - Run - postgres.New: %w", err))
 	}
 
-	groupRepo := repo.NewMessageRepo(pg)
+	messageRepo := repo.NewMessageRepo(pg)
 
-	return pg, groupRepo, cleaner
+	return pg, messageRepo, cleaner
 }
 
 func TestAddMessage(t *testing.T) {
@@ -42,12 +42,12 @@ func TestAddMessage(t *testing.T) {
 		cleaner.Clean("messages")
 
 		messageAt := time.Now().UTC()
-		err := messageRepo.Add(groupID, messageID, "vk", messageAt)
+		err := messageRepo.Add(feedID, guid, "vk", messageAt, nil, nil)
 		assert.ErrorIs(t, err, nil)
 
 		var message entity.Message
 		err = pg.Query.
-			Where(&entity.Message{GroupID: groupID, MessageID: messageID, Source: "vk", MessageAt: messageAt}).
+			Where(&entity.Message{FeedID: feedID, GUID: guid, Source: "vk", MessageAt: messageAt}).
 			First(&message).
 			Error
 		assert.ErrorIs(t, err, nil)
@@ -55,6 +55,48 @@ func TestAddMessage(t *testing.T) {
 
 		cleaner.Clean("messages")
 	})
+
+	t.Run("with deliveries", func(t *testing.T) {
+		cleaner.Acquire("messages")
+		cleaner.Acquire("deliveries")
+		cleaner.Clean("messages")
+		cleaner.Clean("deliveries")
+
+		err := messageRepo.Add(feedID, guid, "vk", time.Now().UTC(), []entity.Delivery{{ChatID: userID, Text: "text"}},
+			nil)
+		assert.ErrorIs(t, err, nil)
+
+		var delivery entity.Delivery
+		err = pg.Query.Where(&entity.Delivery{ChatID: userID}).First(&delivery).Error
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "text", delivery.Text)
+		assert.Equal(t, entity.DeliveryPending, delivery.Status)
+
+		cleaner.Clean("messages")
+		cleaner.Clean("deliveries")
+	})
+
+	t.Run("with digest items", func(t *testing.T) {
+		cleaner.Acquire("messages")
+		cleaner.Acquire("digest_items")
+		cleaner.Clean("messages")
+		cleaner.Clean("digest_items")
+
+		postAt := time.Now().UTC()
+		err := messageRepo.Add(feedID, guid, "vk", postAt, nil,
+			[]entity.DigestItem{{UserID: userID, FeedID: feedID, Title: "title", Link: "https://vk.com/wall-1_1",
+				PostAt: postAt}})
+		assert.ErrorIs(t, err, nil)
+
+		var item entity.DigestItem
+		err = pg.Query.Where(&entity.DigestItem{UserID: userID}).First(&item).Error
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "title", item.Title)
+		assert.False(t, item.CreatedAt.IsZero())
+
+		cleaner.Clean("messages")
+		cleaner.Clean("digest_items")
+	})
 }
 
 func TestLastMessage(t *testing.T) {
@@ -68,7 +110,7 @@ func TestLastMessage(t *testing.T) {
 
 		dayBefore := time.Now().AddDate(0, 0, -1).UTC()
 		err := pg.Query.Create(&entity.Message{
-			GroupID:   groupID,
+			FeedID:    feedID,
 			MessageID: 2,
 			Source:    "vk",
 			MessageAt: dayBefore,
@@ -78,7 +120,7 @@ func TestLastMessage(t *testing.T) {
 		assert.ErrorIs(t, err, nil)
 
 		message := entity.Message{
-			GroupID:   groupID,
+			FeedID:    feedID,
 			MessageID: messageID,
 			Source:    "vk",
 			MessageAt: messageAt,
@@ -89,9 +131,31 @@ func TestLastMessage(t *testing.T) {
 		err = pg.Query.Create(&message).Error
 		assert.ErrorIs(t, err, nil)
 
-		lastMessage := messageRepo.Last(groupID)
+		lastMessage := messageRepo.Last(feedID)
 		assert.Equal(t, message, lastMessage)
 
 		cleaner.Clean("messages")
 	})
 }
+
+func TestExistsMessage(t *testing.T) {
+	_, messageRepo, cleaner := buildMessageRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("messages")
+		cleaner.Clean("messages")
+
+		exists, err := messageRepo.Exists(feedID, guid)
+		assert.ErrorIs(t, err, nil)
+		assert.False(t, exists)
+
+		err = messageRepo.Add(feedID, guid, "rss", time.Now().UTC(), nil, nil)
+		assert.ErrorIs(t, err, nil)
+
+		exists, err = messageRepo.Exists(feedID, guid)
+		assert.ErrorIs(t, err, nil)
+		assert.True(t, exists)
+
+		cleaner.Clean("messages")
+	})
+}
@@ -1,11 +1,12 @@
 package repo
 
 import (
-	"strings"
 	"time"
 
 	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
 	"github.com/jokius/news-telegram-bot/pkg/postgres"
+	"gorm.io/gorm"
 )
 
 type UserRepo struct {
@@ -16,26 +17,28 @@ func NewUserRepo(pg *postgres.Postgres) *UserRepo {
 	return &UserRepo{pg}
 }
 
-func (u UserRepo) AddGroupByURL(id uint64, sourceName, url string) (err error) {
+// AddGroup - subscribe user to the feed, the feed is shared by all its subscribers.
+func (u UserRepo) AddGroup(id uint64, sourceName, groupName string) (err error) {
 	user, err := u.findOrCreateUser(id)
 	if err != nil {
 		return
 	}
 
-	s := strings.Split(url, "/")
-	groupName := s[len(s)-1]
+	feed, err := u.findOrCreateFeed(sourceName, groupName)
+	if err != nil {
+		return
+	}
 
-	var group entity.Group
+	var subscription entity.Subscription
 
 	u.db.Query.
-		Where(&entity.Group{UserID: user.ID, SourceName: sourceName, Name: groupName}).
-		First(&group)
+		Where(&entity.Subscription{UserID: user.ID, FeedID: feed.ID}).
+		First(&subscription)
 
-	if group.ID == 0 {
+	if subscription.ID == 0 {
 		t := time.Now()
-		group = entity.Group{UserID: user.ID, SourceName: sourceName, Name: groupName, LastUpdateAt: t,
-			CreatedAt: t, UpdatedAt: t}
-		err = u.db.Query.Create(&group).Error
+		subscription = entity.Subscription{UserID: user.ID, FeedID: feed.ID, StartAt: t, CreatedAt: t, UpdatedAt: t}
+		err = u.db.Query.Create(&subscription).Error
 	}
 
 	return
@@ -48,37 +51,269 @@ func (u UserRepo) UpdateStartDate(id uint64, date time.Time) (err error) {
 	}
 
 	return u.db.Query.
-		Model(&entity.Group{}).
-		Where(&entity.Group{UserID: user.ID}).
-		Updates(entity.Group{LastUpdateAt: date}).
+		Model(&entity.Subscription{}).
+		Where(&entity.Subscription{UserID: user.ID}).
+		Updates(entity.Subscription{StartAt: date}).
 		Error
 }
 
-func (u UserRepo) RemoveGroup(id uint64, sourceName, url string) (err error) {
+// RemoveGroup - unsubscribe user from the feed, held posts of the subscription are removed too.
+func (u UserRepo) RemoveGroup(id uint64, sourceName, groupName string) (err error) {
 	user, err := u.findOrCreateUser(id)
 	if err != nil {
 		return
 	}
 
-	s := strings.Split(url, "/")
-	groupName := s[len(s)-1]
+	return u.db.Query.Transaction(func(tx *gorm.DB) error {
+		subscriptions := tx.
+			Model(&entity.Subscription{}).
+			Select("id").
+			Where(&entity.Subscription{UserID: user.ID}).
+			Where("feed_id IN (SELECT id FROM feeds WHERE source_name = ? AND name = ?)", sourceName, groupName)
+
+		err := tx.
+			Where(&entity.Delivery{Status: entity.DeliveryHeld}).
+			Where("subscription_id IN (?)", subscriptions).
+			Delete(&entity.Delivery{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.
+			Where(&entity.Subscription{UserID: user.ID}).
+			Where("feed_id IN (SELECT id FROM feeds WHERE source_name = ? AND name = ?)", sourceName, groupName).
+			Delete(&entity.Subscription{}).Error
+	})
+}
+
+// Subscriptions - user subscriptions with feeds in order of adding.
+func (u UserRepo) Subscriptions(id uint64) (subscriptions []entity.Subscription, err error) {
+	user, err := u.findOrCreateUser(id)
+	if err != nil {
+		return
+	}
+
+	err = u.db.Query.
+		Preload("Feed").
+		Where(&entity.Subscription{UserID: user.ID}).
+		Order("id").
+		Find(&subscriptions).Error
+
+	return
+}
+
+// Subscription - subscription of the user, gorm.ErrRecordNotFound for subscriptions of other users.
+func (u UserRepo) Subscription(id, subscriptionID uint64) (subscription entity.Subscription, err error) {
+	err = u.db.Query.
+		Preload("Feed").
+		Where("user_id IN (SELECT id FROM users WHERE telegram_id = ?)", id).
+		First(&subscription, subscriptionID).Error
+
+	return
+}
 
-	var group entity.Group
+// FindSubscription - subscription of the user to the feed, errors.ErrNotSubscribed without it.
+func (u UserRepo) FindSubscription(id uint64, sourceName, groupName string) (subscription entity.Subscription,
+	err error) {
 	err = u.db.Query.
-		Where(&entity.Group{UserID: user.ID, SourceName: sourceName, Name: groupName}).
-		Delete(&group).
+		Preload("Feed").
+		Where("user_id IN (SELECT id FROM users WHERE telegram_id = ?)", id).
+		Where("feed_id IN (SELECT id FROM feeds WHERE source_name = ? AND name = ?)", sourceName, groupName).
+		Limit(1).
+		Find(&subscription).Error
+
+	if err == nil && subscription.ID == 0 {
+		err = errors.ErrNotSubscribed
+	}
+
+	return
+}
+
+func (u UserRepo) UpdateSubscription(subscription *entity.Subscription) (err error) {
+	subscription.UpdatedAt = time.Now().UTC()
+
+	return u.db.Query.
+		Model(subscription).
+		Select("paused", "muted_until", "updated_at").
+		Updates(subscription).Error
+}
+
+// SetFilters - filters of user subscription to the feed.
+func (u UserRepo) SetFilters(id uint64, sourceName, groupName string, filters entity.Filters) (err error) {
+	result := u.db.Query.
+		Model(&entity.Subscription{}).
+		Where("user_id IN (SELECT id FROM users WHERE telegram_id = ?)", id).
+		Where("feed_id IN (SELECT id FROM feeds WHERE source_name = ? AND name = ?)", sourceName, groupName).
+		Updates(map[string]interface{}{"filters": filters, "updated_at": time.Now().UTC()})
+
+	if result.Error == nil && result.RowsAffected == 0 {
+		return errors.ErrNotSubscribed
+	}
+
+	return result.Error
+}
+
+// ResumeSubscription - save paused and mute state of resumed subscription. Posts held during the pause are
+// enqueued on catching up and removed otherwise, missed is their number.
+func (u UserRepo) ResumeSubscription(subscription *entity.Subscription, catchUp bool) (missed int64, err error) {
+	t := time.Now().UTC()
+	subscription.UpdatedAt = t
+
+	err = u.db.Query.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Model(subscription).
+			Select("paused", "muted_until", "updated_at").
+			Updates(subscription).Error
+		if err != nil {
+			return err
+		}
+
+		held := tx.Where(&entity.Delivery{SubscriptionID: subscription.ID, Status: entity.DeliveryHeld})
+
+		var result *gorm.DB
+		if catchUp {
+			result = held.
+				Model(&entity.Delivery{}).
+				Updates(map[string]interface{}{"status": entity.DeliveryPending, "next_attempt_at": t, "updated_at": t})
+		} else {
+			result = held.Delete(&entity.Delivery{})
+		}
+
+		missed = result.RowsAffected
+
+		return result.Error
+	})
+
+	return
+}
+
+// UpdateCheckInterval - save check interval of the subscription, the feed is checked not later than
+// the new interval from now.
+func (u UserRepo) UpdateCheckInterval(subscription *entity.Subscription) (err error) {
+	t := time.Now().UTC()
+	subscription.UpdatedAt = t
+	nextCheckAt := t.Add(time.Duration(subscription.CheckInterval) * time.Second)
+
+	return u.db.Query.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Model(subscription).
+			Select("check_interval", "updated_at").
+			Updates(subscription).Error
+		if err != nil || subscription.CheckInterval == 0 {
+			return err
+		}
+
+		return tx.
+			Model(&entity.Feed{}).
+			Where("id = ? AND next_check_at > ?", subscription.FeedID, nextCheckAt).
+			Update("next_check_at", nextCheckAt).Error
+	})
+}
+
+// RemoveSubscription - remove subscription with its held posts.
+func (u UserRepo) RemoveSubscription(subscription *entity.Subscription) (err error) {
+	return u.db.Query.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Where(&entity.Delivery{SubscriptionID: subscription.ID, Status: entity.DeliveryHeld}).
+			Delete(&entity.Delivery{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(subscription).Error
+	})
+}
+
+// SetActive - inactive users don't get new posts, they are activated again by any message to bot.
+func (u UserRepo) SetActive(id uint64, active bool) (err error) {
+	return u.db.Query.
+		Model(&entity.User{}).
+		Where("telegram_id = ? AND active <> ?", id, active).
+		Updates(map[string]interface{}{"active": active, "updated_at": time.Now().UTC()}).
 		Error
+}
+
+// Activate - user who writes to bot is active, language code of telegram user is sent with every update.
+// Unknown user is created, known one is updated only when they changed.
+func (u UserRepo) Activate(id uint64, languageCode string) (user entity.User, err error) {
+	user, err = u.findOrCreateUser(id)
+	if err != nil {
+		return
+	}
+
+	if languageCode == "" {
+		languageCode = user.LanguageCode
+	}
+
+	if user.Active && user.LanguageCode == languageCode {
+		return
+	}
+
+	user.Active = true
+	user.LanguageCode = languageCode
+	user.UpdatedAt = time.Now().UTC()
+
+	err = u.db.Query.
+		Model(&user).
+		Select("active", "language_code", "updated_at").
+		Updates(&user).Error
 
 	return
 }
 
-func (u UserRepo) Groups(id uint64) (groups []entity.Group, err error) {
+// SetLanguage - language chosen by user, empty language resets it to telegram one.
+func (u UserRepo) SetLanguage(id uint64, language string) (err error) {
 	user, err := u.findOrCreateUser(id)
 	if err != nil {
 		return
 	}
 
-	err = u.db.Query.Where(&entity.Group{UserID: user.ID}).Find(&groups).Error
+	return u.db.Query.
+		Model(&user).
+		Updates(map[string]interface{}{"language": language, "updated_at": time.Now().UTC()}).
+		Error
+}
+
+// UpdateDigest - digest mode, time and timezone of user with the next digest time.
+func (u UserRepo) UpdateDigest(user *entity.User) (err error) {
+	user.UpdatedAt = time.Now().UTC()
+
+	return u.db.Query.
+		Model(user).
+		Select("timezone", "digest_mode", "digest_at", "next_digest_at", "updated_at").
+		Updates(user).Error
+}
+
+// UpdateQuiet - quiet hours and timezone of user. Held deliveries are released when it is not quiet time anymore.
+func (u UserRepo) UpdateQuiet(user *entity.User) (err error) {
+	t := time.Now().UTC()
+	user.UpdatedAt = t
+
+	return u.db.Query.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Model(user).
+			Select("timezone", "quiet_from", "quiet_to", "quiet_mode", "updated_at").
+			Updates(user).Error
+		if err != nil || user.QuietUntil(t) != nil {
+			return err
+		}
+
+		return tx.
+			Model(&entity.Delivery{}).
+			Where(&entity.Delivery{ChatID: user.TelegramID, Status: entity.DeliveryPending}).
+			Where("attempts = 0 AND next_attempt_at > ?", t).
+			Updates(map[string]interface{}{"next_attempt_at": t, "updated_at": t}).Error
+	})
+}
+
+func (u UserRepo) findOrCreateFeed(sourceName, name string) (feed entity.Feed, err error) {
+	u.db.Query.Where(&entity.Feed{SourceName: sourceName, Name: name}).First(&feed)
+
+	if feed.ID == 0 {
+		t := time.Now()
+		feed = entity.Feed{SourceName: sourceName, Name: name, LastUpdateAt: t, CreatedAt: t, UpdatedAt: t}
+		err = u.db.Query.Create(&feed).Error
+	}
 
 	return
 }
@@ -93,6 +328,7 @@ func (u UserRepo) findOrCreateUser(id uint64) (user entity.User, err error) {
 	if user.ID == 0 {
 		t := time.Now()
 		user.TelegramID = id
+		user.Active = true
 		user.CreatedAt = t
 		user.UpdatedAt = t
 		err = u.db.Query.Create(&user).Error
@@ -9,11 +9,14 @@ import (
 
 	"github.com/jokius/news-telegram-bot/internal/entity"
 	"github.com/jokius/news-telegram-bot/internal/usecase/repo"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
 	"github.com/jokius/news-telegram-bot/pkg/postgres"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/khaiql/dbcleaner.v2"
 	"gopkg.in/khaiql/dbcleaner.v2/engine"
+	"gorm.io/gorm"
 )
 
 func buildUserRepo(t *testing.T) (*postgres.Postgres, *repo.UserRepo, dbcleaner.DbCleaner) {
@@ -34,53 +37,98 @@ func buildUserRepo(t *testing.T) (*postgres.Postgres, *repo.UserRepo, dbcleaner.
 	return pg, userRepo, cleaner
 }
 
-func TestAddGroupByURL(t *testing.T) {
+func TestAddGroup(t *testing.T) {
 	pg, userRepo, cleaner := buildUserRepo(t)
 
 	t.Run("without user", func(t *testing.T) {
 		cleaner.Acquire("users")
-		cleaner.Acquire("groups")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 
 		var user entity.User
 		pg.Query.Where(&entity.User{TelegramID: userID}).First(&user)
 		assert.Empty(t, user)
 
-		err := userRepo.AddGroupByURL(userID, "vk", "https://example.com/group1")
+		err := userRepo.AddGroup(userID, "vk", "group1")
 		assert.ErrorIs(t, err, nil)
 
 		pg.Query.Where(&entity.User{TelegramID: userID}).First(&user)
 		assert.NotEmpty(t, user)
 
-		var group entity.Group
-		pg.Query.Where(&entity.Group{UserID: user.ID, SourceName: "vk", Name: "group1"}).First(&group)
-		assert.NotEmpty(t, group)
+		var feed entity.Feed
+		pg.Query.Where(&entity.Feed{SourceName: "vk", Name: "group1"}).First(&feed)
+		assert.NotEmpty(t, feed)
+
+		var subscription entity.Subscription
+		pg.Query.Where(&entity.Subscription{UserID: user.ID, FeedID: feed.ID}).First(&subscription)
+		assert.NotEmpty(t, subscription)
 
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 	})
 
 	t.Run("with user", func(t *testing.T) {
 		cleaner.Acquire("users")
-		cleaner.Acquire("groups")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 
 		timeNow := time.Now()
 		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
 		err := pg.Query.Create(&user).Error
 		assert.ErrorIs(t, err, nil)
 
-		err = userRepo.AddGroupByURL(userID, "vk", "https://example.com/group1")
+		err = userRepo.AddGroup(userID, "vk", "group1")
+		assert.ErrorIs(t, err, nil)
+
+		var feed entity.Feed
+		pg.Query.Where(&entity.Feed{SourceName: "vk", Name: "group1"}).First(&feed)
+		assert.NotEmpty(t, feed)
+
+		var subscription entity.Subscription
+		pg.Query.Where(&entity.Subscription{UserID: user.ID, FeedID: feed.ID}).First(&subscription)
+		assert.NotEmpty(t, subscription)
+
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+	})
+}
+
+func TestAddGroup_shared_feed(t *testing.T) {
+	pg, userRepo, cleaner := buildUserRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+
+		err := userRepo.AddGroup(userID, "vk", "group1")
+		assert.ErrorIs(t, err, nil)
+
+		err = userRepo.AddGroup(userID+1, "vk", "group1")
 		assert.ErrorIs(t, err, nil)
 
-		var group entity.Group
-		pg.Query.Where(&entity.Group{UserID: user.ID, SourceName: "vk", Name: "group1"}).First(&group)
-		assert.NotEmpty(t, group)
+		var feeds []entity.Feed
+		pg.Query.Find(&feeds)
+		assert.Equal(t, 1, len(feeds))
+
+		var subscriptions []entity.Subscription
+		pg.Query.Where(&entity.Subscription{FeedID: feeds[0].ID}).Find(&subscriptions)
+		assert.Equal(t, 2, len(subscriptions))
 
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 	})
 }
 
@@ -89,9 +137,11 @@ func TestUpdateStartDate(t *testing.T) {
 
 	t.Run("without user", func(t *testing.T) {
 		cleaner.Acquire("users")
-		cleaner.Acquire("groups")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 
 		var user entity.User
 		pg.Query.Where(&entity.User{TelegramID: userID}).First(&user)
@@ -105,41 +155,35 @@ func TestUpdateStartDate(t *testing.T) {
 		assert.NotEmpty(t, user)
 
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 	})
 
 	t.Run("with user", func(t *testing.T) {
 		cleaner.Acquire("users")
-		cleaner.Acquire("groups")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 
 		timeNow := time.Now()
 		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
 		err := pg.Query.Create(&user).Error
 		assert.ErrorIs(t, err, nil)
 
-		group := entity.Group{
-			UserID:       user.ID,
-			SourceName:   "vk",
-			Name:         "group1",
-			CreatedAt:    timeNow,
-			UpdatedAt:    timeNow,
-			LastUpdateAt: timeNow,
-		}
-		err = pg.Query.Create(&group).Error
-		assert.ErrorIs(t, err, nil)
+		_, subscription := createSubscription(t, pg, user.ID, "vk", "group1", timeNow)
 
 		dayBefore := timeNow.AddDate(0, 0, -1).UTC()
 		err = userRepo.UpdateStartDate(userID, dayBefore)
 		assert.ErrorIs(t, err, nil)
 
-		pg.Query.Where(&entity.Group{UserID: user.ID, SourceName: "vk", Name: "group1"}).First(&group)
-		assert.NotEmpty(t, group)
-		assert.Equal(t, group.LastUpdateAt, dayBefore)
+		pg.Query.First(&subscription, subscription.ID)
+		assert.Equal(t, subscription.StartAt, dayBefore)
 
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 	})
 }
 
@@ -148,110 +192,426 @@ func TestRemoveGroup(t *testing.T) {
 
 	t.Run("without user", func(t *testing.T) {
 		cleaner.Acquire("users")
-		cleaner.Acquire("groups")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 
 		var user entity.User
 		pg.Query.Where(&entity.User{TelegramID: userID}).First(&user)
 		assert.Empty(t, user)
 
-		err := userRepo.RemoveGroup(userID, "vk", "https://example.com/group1")
+		err := userRepo.RemoveGroup(userID, "vk", "group1")
 		assert.ErrorIs(t, err, nil)
 
 		pg.Query.Where(&entity.User{TelegramID: userID}).First(&user)
 		assert.NotEmpty(t, user)
 
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 	})
 
 	t.Run("with user", func(t *testing.T) {
 		cleaner.Acquire("users")
-		cleaner.Acquire("groups")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 
 		timeNow := time.Now()
 		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
 		err := pg.Query.Create(&user).Error
 		assert.ErrorIs(t, err, nil)
 
-		group := entity.Group{
-			UserID:       user.ID,
-			SourceName:   "vk",
-			Name:         "group1",
-			CreatedAt:    timeNow,
-			UpdatedAt:    timeNow,
-			LastUpdateAt: timeNow,
-		}
-		err = pg.Query.Create(&group).Error
-		assert.ErrorIs(t, err, nil)
+		feed, subscription := createSubscription(t, pg, user.ID, "vk", "group1", timeNow)
 
-		err = userRepo.RemoveGroup(userID, "vk", "https://example.com/group1")
+		err = userRepo.RemoveGroup(userID, "vk", "group1")
 		assert.ErrorIs(t, err, nil)
 
-		var emptyGroup entity.Group
-		pg.Query.Where(&entity.Group{UserID: user.ID, SourceName: "vk", Name: "group1"}).First(&emptyGroup)
-		assert.Empty(t, emptyGroup)
+		var emptySubscription entity.Subscription
+		pg.Query.Where(&entity.Subscription{UserID: user.ID, FeedID: feed.ID}).First(&emptySubscription)
+		assert.Empty(t, emptySubscription)
+		assert.NotEmpty(t, subscription)
 
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 	})
 }
 
-func TestGroups(t *testing.T) {
+func TestSubscriptions(t *testing.T) {
 	pg, userRepo, cleaner := buildUserRepo(t)
 
 	t.Run("without user", func(t *testing.T) {
 		cleaner.Acquire("users")
-		cleaner.Acquire("groups")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 
 		var user entity.User
 		pg.Query.Where(&entity.User{TelegramID: userID}).First(&user)
 		assert.Empty(t, user)
 
-		groups, err := userRepo.Groups(userID)
+		subscriptions, err := userRepo.Subscriptions(userID)
 		assert.ErrorIs(t, err, nil)
-		assert.Empty(t, groups)
+		assert.Empty(t, subscriptions)
 
 		pg.Query.Where(&entity.User{TelegramID: userID}).First(&user)
 		assert.NotEmpty(t, user)
 
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 	})
 
 	t.Run("with user", func(t *testing.T) {
 		cleaner.Acquire("users")
-		cleaner.Acquire("groups")
+		cleaner.Acquire("feeds")
+		cleaner.Acquire("subscriptions")
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
 
 		timeNow := time.Now().UTC()
 		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
 		err := pg.Query.Create(&user).Error
 		assert.ErrorIs(t, err, nil)
 
-		group := entity.Group{
-			UserID:       user.ID,
-			SourceName:   "vk",
-			Name:         "group1",
-			CreatedAt:    timeNow,
-			UpdatedAt:    timeNow,
-			LastUpdateAt: timeNow,
+		feed, subscription := createSubscription(t, pg, user.ID, "vk", "group1", timeNow)
+
+		subscriptions, err := userRepo.Subscriptions(userID)
+		assert.ErrorIs(t, err, nil)
+		assert.Len(t, subscriptions, 1)
+		assert.Equal(t, subscription.ID, subscriptions[0].ID)
+		assert.Equal(t, feed.ID, subscriptions[0].Feed.ID)
+		assert.Equal(t, feed.Name, subscriptions[0].Feed.Name)
+
+		cleaner.Clean("users")
+		cleaner.Clean("feeds")
+		cleaner.Clean("subscriptions")
+	})
+}
+
+func TestSubscription(t *testing.T) {
+	pg, userRepo, cleaner := buildUserRepo(t)
+
+	cleaner.Acquire("users")
+	cleaner.Acquire("feeds")
+	cleaner.Acquire("subscriptions")
+	cleaner.Clean("users")
+	cleaner.Clean("feeds")
+	cleaner.Clean("subscriptions")
+
+	timeNow := time.Now().UTC()
+	user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
+	err := pg.Query.Create(&user).Error
+	require.ErrorIs(t, err, nil)
+
+	feed, subscription := createSubscription(t, pg, user.ID, "vk", "group1", timeNow)
+
+	t.Run("own subscription", func(t *testing.T) {
+		found, err := userRepo.Subscription(userID, subscription.ID)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, subscription.ID, found.ID)
+		assert.Equal(t, "group1", found.Feed.Name)
+	})
+
+	t.Run("subscription of other user", func(t *testing.T) {
+		_, err := userRepo.Subscription(userID+1, subscription.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		mutedUntil := timeNow.Add(time.Hour).Truncate(time.Second)
+		subscription.Paused = true
+		subscription.MutedUntil = &mutedUntil
+
+		err := userRepo.UpdateSubscription(&subscription)
+		assert.ErrorIs(t, err, nil)
+
+		found, err := userRepo.Subscription(userID, subscription.ID)
+		require.ErrorIs(t, err, nil)
+		assert.True(t, found.Paused)
+		require.NotNil(t, found.MutedUntil)
+		assert.True(t, mutedUntil.Equal(*found.MutedUntil))
+	})
+
+	t.Run("set filters", func(t *testing.T) {
+		filters := entity.Filters{{Pattern: "go"}, {Exclude: true, Regexp: true, Pattern: "ad|promo"}}
+
+		err := userRepo.SetFilters(userID, "vk", "group1", filters)
+		assert.ErrorIs(t, err, nil)
+
+		found, err := userRepo.Subscription(userID, subscription.ID)
+		require.ErrorIs(t, err, nil)
+		assert.Equal(t, filters, found.Filters)
+
+		err = userRepo.SetFilters(userID, "vk", "group2", filters)
+		assert.ErrorIs(t, err, errors.ErrNotSubscribed)
+	})
+
+	t.Run("find", func(t *testing.T) {
+		found, err := userRepo.FindSubscription(userID, "vk", "group1")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, subscription.ID, found.ID)
+		assert.Equal(t, "group1", found.Feed.Name)
+
+		_, err = userRepo.FindSubscription(userID, "vk", "group2")
+		assert.ErrorIs(t, err, errors.ErrNotSubscribed)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		cleaner.Acquire("deliveries")
+		cleaner.Clean("deliveries")
+
+		for _, text := range []string{"first", "second"} {
+			err := pg.Query.Create(&entity.Delivery{ChatID: userID, SubscriptionID: subscription.ID, Text: text,
+				Status: entity.DeliveryHeld, NextAttemptAt: timeNow, CreatedAt: timeNow, UpdatedAt: timeNow}).Error
+			require.ErrorIs(t, err, nil)
 		}
-		err = pg.Query.Create(&group).Error
+
+		subscription.Paused = false
+		subscription.MutedUntil = nil
+
+		missed, err := userRepo.ResumeSubscription(&subscription, true)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, int64(2), missed)
+
+		var pending int64
+		pg.Query.Model(&entity.Delivery{}).Where(&entity.Delivery{Status: entity.DeliveryPending}).Count(&pending)
+		assert.Equal(t, int64(2), pending)
+
+		found, err := userRepo.Subscription(userID, subscription.ID)
+		require.ErrorIs(t, err, nil)
+		assert.False(t, found.Paused)
+		assert.Nil(t, found.MutedUntil)
+
+		err = pg.Query.Create(&entity.Delivery{ChatID: userID, SubscriptionID: subscription.ID, Text: "third",
+			Status: entity.DeliveryHeld, NextAttemptAt: timeNow, CreatedAt: timeNow, UpdatedAt: timeNow}).Error
+		require.ErrorIs(t, err, nil)
+
+		missed, err = userRepo.ResumeSubscription(&subscription, false)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, int64(1), missed)
+
+		var held int64
+		pg.Query.Model(&entity.Delivery{}).Where(&entity.Delivery{Status: entity.DeliveryHeld}).Count(&held)
+		assert.Zero(t, held)
+
+		cleaner.Clean("deliveries")
+	})
+
+	t.Run("check interval", func(t *testing.T) {
+		err := pg.Query.Model(&feed).Update("next_check_at", timeNow.Add(24*time.Hour)).Error
+		require.ErrorIs(t, err, nil)
+
+		subscription.CheckInterval = int64(time.Hour / time.Second)
+
+		err = userRepo.UpdateCheckInterval(&subscription)
 		assert.ErrorIs(t, err, nil)
 
-		groups, err := userRepo.Groups(userID)
+		found, err := userRepo.Subscription(userID, subscription.ID)
+		require.ErrorIs(t, err, nil)
+		assert.Equal(t, subscription.CheckInterval, found.CheckInterval)
+		assert.True(t, found.Feed.NextCheckAt.Before(timeNow.Add(2*time.Hour)))
+
+		subscription.CheckInterval = 0
+
+		err = userRepo.UpdateCheckInterval(&subscription)
 		assert.ErrorIs(t, err, nil)
-		assert.NotEmpty(t, groups)
-		assert.Equal(t, groups[0], group)
+
+		found, err = userRepo.Subscription(userID, subscription.ID)
+		require.ErrorIs(t, err, nil)
+		assert.Zero(t, found.CheckInterval)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		err := userRepo.RemoveSubscription(&subscription)
+		assert.ErrorIs(t, err, nil)
+
+		_, err = userRepo.Subscription(userID, subscription.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	cleaner.Clean("users")
+	cleaner.Clean("feeds")
+	cleaner.Clean("subscriptions")
+}
+
+func TestSetActive(t *testing.T) {
+	pg, userRepo, cleaner := buildUserRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Clean("users")
+
+		timeNow := time.Now().UTC()
+		user := entity.User{TelegramID: userID, CreatedAt: timeNow, UpdatedAt: timeNow}
+		err := pg.Query.Create(&user).Error
+		assert.ErrorIs(t, err, nil)
+		assert.True(t, user.Active)
+
+		err = userRepo.SetActive(userID, false)
+		assert.ErrorIs(t, err, nil)
+
+		pg.Query.First(&user, user.ID)
+		assert.False(t, user.Active)
+
+		err = userRepo.SetActive(userID, true)
+		assert.ErrorIs(t, err, nil)
+
+		pg.Query.First(&user, user.ID)
+		assert.True(t, user.Active)
+
+		cleaner.Clean("users")
+	})
+}
+
+func TestActivate(t *testing.T) {
+	pg, userRepo, cleaner := buildUserRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Clean("users")
+
+		user, err := userRepo.Activate(userID, "")
+		assert.ErrorIs(t, err, nil)
+		assert.True(t, user.Active)
+		assert.Empty(t, user.LanguageCode)
+
+		err = userRepo.SetActive(userID, false)
+		assert.ErrorIs(t, err, nil)
+
+		user, err = userRepo.Activate(userID, "en-US")
+		assert.ErrorIs(t, err, nil)
+		assert.True(t, user.Active)
+		assert.Equal(t, "en-US", user.LanguageCode)
+
+		pg.Query.First(&user, user.ID)
+		assert.True(t, user.Active)
+		assert.Equal(t, "en-US", user.LanguageCode)
+
+		// update without language code keeps the known one
+		user, err = userRepo.Activate(userID, "")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "en-US", user.LanguageCode)
+
+		cleaner.Clean("users")
+	})
+}
+
+func TestLanguage(t *testing.T) {
+	_, userRepo, cleaner := buildUserRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Clean("users")
+
+		user, err := userRepo.Activate(userID, "")
+		assert.ErrorIs(t, err, nil)
+		assert.Empty(t, user.PreferredLanguage())
+
+		user, err = userRepo.Activate(userID, "en-US")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "en-US", user.PreferredLanguage())
+
+		err = userRepo.SetLanguage(userID, "ru")
+		assert.ErrorIs(t, err, nil)
+
+		user, err = userRepo.Activate(userID, "")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "ru", user.PreferredLanguage())
+
+		err = userRepo.SetLanguage(userID, "")
+		assert.ErrorIs(t, err, nil)
+
+		user, err = userRepo.Activate(userID, "")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "en-US", user.PreferredLanguage())
+
+		cleaner.Clean("users")
+	})
+}
+
+func TestUpdateDigest(t *testing.T) {
+	pg, userRepo, cleaner := buildUserRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Clean("users")
+
+		user, err := userRepo.Activate(userID, "")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, entity.DigestInstant, user.DigestMode)
+		assert.Equal(t, "UTC", user.Timezone)
+
+		user.DigestMode = entity.DigestDaily
+		user.DigestAt = "08:00"
+		user.Timezone = "Europe/Moscow"
+		user.NextDigestAt = user.NextDigest(time.Now())
+
+		err = userRepo.UpdateDigest(&user)
+		assert.ErrorIs(t, err, nil)
+
+		var saved entity.User
+		pg.Query.First(&saved, user.ID)
+		assert.Equal(t, entity.DigestDaily, saved.DigestMode)
+		assert.Equal(t, "08:00", saved.DigestAt)
+		assert.Equal(t, "Europe/Moscow", saved.Timezone)
+		assert.NotNil(t, saved.NextDigestAt)
+
+		cleaner.Clean("users")
+	})
+}
+
+func TestUpdateQuiet(t *testing.T) {
+	pg, userRepo, cleaner := buildUserRepo(t)
+
+	t.Run("run", func(t *testing.T) {
+		cleaner.Acquire("users")
+		cleaner.Acquire("deliveries")
+		cleaner.Clean("users")
+		cleaner.Clean("deliveries")
+
+		user, err := userRepo.Activate(userID, "")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, entity.QuietHold, user.QuietMode)
+
+		timeNow := time.Now().UTC()
+		held := entity.Delivery{ChatID: userID, Text: "held", Status: entity.DeliveryPending,
+			NextAttemptAt: timeNow.Add(time.Hour), CreatedAt: timeNow, UpdatedAt: timeNow}
+		err = pg.Query.Create(&held).Error
+		assert.ErrorIs(t, err, nil)
+
+		user.QuietFrom = "23:00"
+		user.QuietTo = "08:00"
+		user.QuietMode = entity.QuietSilent
+		user.Timezone = "Europe/Moscow"
+
+		err = userRepo.UpdateQuiet(&user)
+		assert.ErrorIs(t, err, nil)
+
+		var saved entity.User
+		pg.Query.First(&saved, user.ID)
+		assert.Equal(t, "23:00", saved.QuietFrom)
+		assert.Equal(t, "08:00", saved.QuietTo)
+		assert.Equal(t, entity.QuietSilent, saved.QuietMode)
+
+		user.QuietFrom = ""
+		user.QuietTo = ""
+
+		err = userRepo.UpdateQuiet(&user)
+		assert.ErrorIs(t, err, nil)
+
+		pg.Query.First(&held, held.ID)
+		assert.False(t, held.NextAttemptAt.After(time.Now()), "held delivery is released")
 
 		cleaner.Clean("users")
-		cleaner.Clean("groups")
+		cleaner.Clean("deliveries")
 	})
 }
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+)
+
+const (
+	_digestTitleLimit = 100
+)
+
+// DigestMessages - digest rendered to telegram messages: header with number of posts and a block for every feed
+// with links to its posts. Items are expected to be grouped by feed.
+func DigestMessages(items []entity.DigestItem, renderer markup.Renderer, translator i18n.Translator,
+	lang string) []string {
+	l := localizer{translator, lang}
+	parts := []markup.Part{markup.BoldText(l.plural("digest.header", len(items)))}
+
+	var feedID uint64
+
+	for i := range items {
+		item := &items[i]
+
+		if i == 0 || item.FeedID != feedID {
+			feedID = item.FeedID
+			parts = append(parts,
+				markup.Text("\n\n"),
+				markup.BoldText(item.Feed.Name),
+				markup.ItalicText(" ("+item.Feed.SourceName+")"),
+			)
+		}
+
+		title := item.Title
+		if title == "" {
+			title = l.t("post.link")
+		}
+
+		parts = append(parts, markup.Text("\n• "))
+
+		if item.Link == "" {
+			parts = append(parts, markup.Text(title))
+		} else {
+			parts = append(parts, markup.LinkText(title, item.Link))
+		}
+	}
+
+	return markup.Split(renderer, parts, markup.MessageLimit, markup.MessageLimit)
+}
+
+// digestTitle - title of the post or the first line of its text without vk mentions, it is cut to the limit.
+func digestTitle(post *entity.Post) string {
+	title := strings.TrimSpace(post.Title)
+	if title == "" {
+		title = strings.TrimSpace(vkMention.ReplaceAllString(post.Text, "$2"))
+		if end := strings.Index(title, "\n"); end >= 0 {
+			title = strings.TrimSpace(title[:end])
+		}
+	}
+
+	if title == "" && post.Repost != nil {
+		return digestTitle(post.Repost)
+	}
+
+	if runes := []rune(title); len(runes) > _digestTitleLimit {
+		title = strings.TrimSpace(string(runes[:_digestTitleLimit-1])) + "…"
+	}
+
+	return title
+}
@@ -0,0 +1,34 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestMessages(t *testing.T) {
+	t.Parallel()
+
+	postAt := time.Now()
+	vk := entity.Feed{ID: 1, SourceName: "vk", Name: "club1"}
+	rss := entity.Feed{ID: 2, SourceName: "rss", Name: "https://example.com/feed"}
+	items := []entity.DigestItem{
+		{FeedID: vk.ID, Feed: vk, Title: "First <post>", Link: "https://vk.com/wall-1_1", PostAt: postAt},
+		{FeedID: vk.ID, Feed: vk, Link: "https://vk.com/wall-1_2", PostAt: postAt},
+		{FeedID: rss.ID, Feed: rss, Title: "Article", PostAt: postAt},
+	}
+
+	assert.Equal(t, []string{
+		"<b>digest.header(3)</b>\n\n" +
+			"<b>club1</b><i> (vk)</i>\n" +
+			`• <a href="https://vk.com/wall-1_1">First &lt;post&gt;</a>` + "\n" +
+			`• <a href="https://vk.com/wall-1_2">post.link</a>` + "\n\n" +
+			"<b>https://example.com/feed</b><i> (rss)</i>\n" +
+			"• Article",
+	}, service.DigestMessages(items, markup.HTML, i18n.Keys{}, ""))
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase"
+	"github.com/jokius/news-telegram-bot/pkg/grabber"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/logger"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+)
+
+// Digester - composes digests of collected posts when their time comes and enqueues them for dispatcher.
+type Digester struct {
+	sleep      time.Duration
+	digestRepo usecase.DigestRepo
+	renderer   markup.Renderer
+	translator i18n.Translator
+	l          logger.InterfaceLogger
+}
+
+const (
+	_digesterBatch = 100
+)
+
+func NewDigester(sleep time.Duration, digestRepo usecase.DigestRepo, renderer markup.Renderer,
+	translator i18n.Translator, l logger.InterfaceLogger) *Digester {
+	return &Digester{
+		sleep:      sleep,
+		digestRepo: digestRepo,
+		renderer:   renderer,
+		translator: translator,
+		l:          l,
+	}
+}
+
+// Start - compose due digests every sleep until ctx is canceled.
+func (d *Digester) Start(ctx context.Context) {
+	grabber.Run(ctx, d.sleep, d.digest)
+}
+
+// digest - digests of due users, canceled ctx stops it between users.
+func (d *Digester) digest(ctx context.Context) {
+	t := time.Now().UTC()
+
+	users, err := d.digestRepo.Due(t, _digesterBatch)
+	if err != nil {
+		d.l.Error(fmt.Errorf("`d.digest` something wrong: %w", err))
+
+		return
+	}
+
+	for i := range users {
+		if ctx.Err() != nil {
+			return
+		}
+
+		user := &users[i]
+
+		if err = d.compose(user, t); err != nil {
+			d.l.Error(fmt.Errorf("`d.digest` user %d: %w", user.TelegramID, err))
+		}
+	}
+}
+
+// compose - one digest of all collected posts of the user, nothing is sent without posts.
+// User switched to instant mode gets the rest of posts and has no next digest. Digest in quiet hours of the user
+// waits for their end or comes without sound.
+func (d *Digester) compose(user *entity.User, t time.Time) error {
+	items, err := d.digestRepo.Items(user.ID)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []entity.Delivery
+
+	if len(items) > 0 {
+		texts := DigestMessages(items, d.renderer, d.translator, user.PreferredLanguage())
+		deliveries = textDeliveries(texts, d.renderer)
+		for i := range deliveries {
+			deliveries[i].ChatID = user.TelegramID
+		}
+
+		quietDeliveries(user, deliveries, t)
+	}
+
+	user.NextDigestAt = user.NextDigest(t)
+
+	return d.digestRepo.Complete(user, items, deliveries)
+}
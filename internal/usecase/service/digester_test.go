@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+	"github.com/jokius/news-telegram-bot/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func digestOnce(t *testing.T, users []entity.User, expect func(*mocks.MockDigestRepo)) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	digestRepo := mocks.NewMockDigestRepo(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	digestRepo.EXPECT().Due(gomock.Any(), gomock.Any()).Return(users, nil).Times(1)
+	digestRepo.EXPECT().Due(gomock.Any(), gomock.Any()).DoAndReturn(func(time.Time, int) ([]entity.User, error) {
+		cancel()
+
+		return nil, nil
+	}).Times(1)
+	expect(digestRepo)
+
+	digester := service.NewDigester(time.Millisecond, digestRepo, markup.HTML, i18n.Keys{}, logger)
+	stopped := make(chan struct{})
+
+	go func() {
+		digester.Start(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("digester didn't stop after cycle")
+	}
+}
+
+func TestDigester(t *testing.T) {
+	t.Parallel()
+
+	t.Run("digest is enqueued", func(t *testing.T) {
+		t.Parallel()
+
+		due := time.Now().Add(-time.Minute)
+		user := entity.User{ID: 10, TelegramID: userID, DigestMode: entity.DigestHourly, NextDigestAt: &due}
+		items := []entity.DigestItem{{ID: 1, UserID: user.ID, FeedID: 1, Feed: entity.Feed{SourceName: "vk",
+			Name: "club1"}, Title: "Title", Link: "https://vk.com/wall-1_1"}}
+
+		digestOnce(t, []entity.User{user}, func(repo *mocks.MockDigestRepo) {
+			repo.EXPECT().Items(user.ID).Return(items, nil).Times(1)
+			repo.EXPECT().Complete(gomock.Any(), items, []entity.Delivery{{
+				ChatID: userID,
+				Text: "<b>digest.header(1)</b>\n\n<b>club1</b><i> (vk)</i>\n" +
+					`• <a href="https://vk.com/wall-1_1">Title</a>`,
+				ParseMode: "HTML",
+			}}).DoAndReturn(func(completed *entity.User, _ []entity.DigestItem, _ []entity.Delivery) error {
+				assert.True(t, completed.NextDigestAt.After(time.Now()))
+
+				return nil
+			}).Times(1)
+		})
+	})
+
+	t.Run("digest waits for the end of quiet hours", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now().UTC()
+		user := entity.User{ID: 10, TelegramID: userID, DigestMode: entity.DigestHourly, NextDigestAt: &now,
+			QuietFrom: now.Add(-time.Hour).Format("15:04"), QuietTo: now.Add(time.Hour).Format("15:04"),
+			QuietMode: entity.QuietHold}
+		items := []entity.DigestItem{{ID: 1, UserID: user.ID, FeedID: 1, Title: "Title"}}
+
+		digestOnce(t, []entity.User{user}, func(repo *mocks.MockDigestRepo) {
+			repo.EXPECT().Items(user.ID).Return(items, nil).Times(1)
+			repo.EXPECT().Complete(gomock.Any(), items, gomock.Any()).DoAndReturn(
+				func(_ *entity.User, _ []entity.DigestItem, deliveries []entity.Delivery) error {
+					assert.Equal(t, 1, len(deliveries))
+					assert.True(t, deliveries[0].NextAttemptAt.After(now.Add(50*time.Minute)))
+
+					return nil
+				}).Times(1)
+		})
+	})
+
+	t.Run("nothing is sent without posts", func(t *testing.T) {
+		t.Parallel()
+
+		due := time.Now().Add(-time.Minute)
+		user := entity.User{ID: 10, TelegramID: userID, DigestMode: entity.DigestDaily, DigestAt: "08:00",
+			NextDigestAt: &due}
+
+		digestOnce(t, []entity.User{user}, func(repo *mocks.MockDigestRepo) {
+			repo.EXPECT().Items(user.ID).Return(nil, nil).Times(1)
+			repo.EXPECT().Complete(gomock.Any(), nil, nil).DoAndReturn(
+				func(completed *entity.User, _ []entity.DigestItem, _ []entity.Delivery) error {
+					assert.True(t, completed.NextDigestAt.After(time.Now()))
+
+					return nil
+				}).Times(1)
+		})
+	})
+
+	t.Run("instant user has no next digest", func(t *testing.T) {
+		t.Parallel()
+
+		due := time.Now().Add(-time.Minute)
+		user := entity.User{ID: 10, TelegramID: userID, DigestMode: entity.DigestInstant, NextDigestAt: &due}
+
+		digestOnce(t, []entity.User{user}, func(repo *mocks.MockDigestRepo) {
+			repo.EXPECT().Items(user.ID).Return(nil, nil).Times(1)
+			repo.EXPECT().Complete(gomock.Any(), nil, nil).DoAndReturn(
+				func(completed *entity.User, _ []entity.DigestItem, _ []entity.Delivery) error {
+					assert.Nil(t, completed.NextDigestAt)
+
+					return nil
+				}).Times(1)
+		})
+	})
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/grabber"
+	"github.com/jokius/news-telegram-bot/pkg/logger"
+)
+
+// Dispatcher - sends outbox deliveries with retries.
+type Dispatcher struct {
+	sleep        time.Duration
+	maxAttempts  int
+	messenger    usecase.Messenger
+	deliveryRepo usecase.DeliveryRepo
+	l            logger.InterfaceLogger
+}
+
+const (
+	_dispatcherBatch      = 100
+	_dispatcherBackoff    = 5 * time.Second
+	_dispatcherMaxBackoff = time.Hour
+)
+
+func NewDispatcher(sleep time.Duration, maxAttempts int, messenger usecase.Messenger,
+	deliveryRepo usecase.DeliveryRepo, l logger.InterfaceLogger) *Dispatcher {
+	return &Dispatcher{
+		sleep:        sleep,
+		maxAttempts:  maxAttempts,
+		messenger:    messenger,
+		deliveryRepo: deliveryRepo,
+		l:            l,
+	}
+}
+
+// Start - send due deliveries every sleep until ctx is canceled.
+func (d *Dispatcher) Start(ctx context.Context) {
+	grabber.Run(ctx, d.sleep, d.dispatch)
+}
+
+// dispatch - send a batch of due deliveries, canceled ctx stops it between deliveries.
+// After failed delivery the rest of the chat waits for it to keep the order of messages.
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	deliveries, err := d.deliveryRepo.Due(time.Now().UTC(), _dispatcherBatch)
+	if err != nil {
+		d.l.Error(fmt.Errorf("`d.dispatch` something wrong: %w", err))
+
+		return
+	}
+
+	failedChats := make(map[uint64]bool)
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		delivery := &deliveries[i]
+		if failedChats[delivery.ChatID] {
+			continue
+		}
+
+		sent, err := d.deliver(delivery)
+		if !sent {
+			failedChats[delivery.ChatID] = true
+		}
+
+		if err != nil {
+			d.l.Error(fmt.Errorf("`d.dispatch` delivery %d: %w", delivery.ID, err))
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(delivery *entity.Delivery) (sent bool, err error) {
+	if len(delivery.Media) > 0 {
+		err = d.sendMedia(delivery)
+	} else {
+		err = d.messenger.Send(delivery.ChatID, delivery.Text, delivery.ParseMode, delivery.DisableNotification)
+	}
+
+	if err == nil {
+		return true, d.deliveryRepo.Delete(delivery)
+	}
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+
+	if retryAfter, ok := errors.TelegramRetryAfter(err); ok {
+		delivery.NextAttemptAt = now.Add(retryAfter)
+	} else {
+		delivery.NextAttemptAt = now.Add(backoff(delivery.Attempts))
+	}
+
+	if errors.IsTelegramPermanent(err) || delivery.Attempts >= d.maxAttempts {
+		delivery.Status = entity.DeliveryParked
+	}
+
+	return false, d.deliveryRepo.Update(delivery)
+}
+
+// sendMedia - media which telegram can't fetch by url is sent as text message with links to files.
+func (d *Dispatcher) sendMedia(delivery *entity.Delivery) error {
+	err := d.messenger.SendMedia(delivery.ChatID, delivery.Media, delivery.Text, delivery.ParseMode,
+		delivery.DisableNotification)
+	if !errors.IsTelegramBadRequest(err) {
+		return err
+	}
+
+	text, ok := mediaFallback(delivery)
+	if !ok {
+		return err
+	}
+
+	return d.messenger.Send(delivery.ChatID, text, delivery.ParseMode, delivery.DisableNotification)
+}
+
+// backoff - exponential delay before the next attempt.
+func backoff(attempts int) time.Duration {
+	delay := _dispatcherBackoff
+	for i := 1; i < attempts && delay < _dispatcherMaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > _dispatcherMaxBackoff {
+		return _dispatcherMaxBackoff
+	}
+
+	return delay
+}
@@ -0,0 +1,233 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func dispatchOnce(t *testing.T, deliveries []entity.Delivery, expect func(*mocks.MockMessenger, *mocks.MockDeliveryRepo)) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	messenger := mocks.NewMockMessenger(mockCtl)
+	deliveryRepo := mocks.NewMockDeliveryRepo(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliveryRepo.EXPECT().Due(gomock.Any(), gomock.Any()).Return(deliveries, nil).Times(1)
+	deliveryRepo.EXPECT().Due(gomock.Any(), gomock.Any()).DoAndReturn(func(time.Time, int) ([]entity.Delivery, error) {
+		cancel()
+
+		return nil, nil
+	}).Times(1)
+	expect(messenger, deliveryRepo)
+
+	dispatcher := service.NewDispatcher(time.Millisecond, 3, messenger, deliveryRepo, logger)
+	stopped := make(chan struct{})
+
+	go func() {
+		dispatcher.Start(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher didn't stop after cycle")
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sent delivery is deleted", func(t *testing.T) {
+		t.Parallel()
+
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "text", Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().Send(uint64(userID), "text", "", false).Return(nil).Times(1)
+			repo.EXPECT().Delete(&delivery).Return(nil).Times(1)
+		})
+	})
+
+	t.Run("media delivery is sent as media", func(t *testing.T) {
+		t.Parallel()
+
+		media := entity.DeliveryMedia{{Type: entity.AttachmentPhoto, URL: "https://example.com/1.jpg"}}
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "caption", ParseMode: "HTML", Media: media,
+			Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().SendMedia(uint64(userID), []entity.Media(media), "caption", "HTML", false).Return(nil).Times(1)
+			repo.EXPECT().Delete(&delivery).Return(nil).Times(1)
+		})
+	})
+
+	t.Run("rejected media is sent as text with links", func(t *testing.T) {
+		t.Parallel()
+
+		media := entity.DeliveryMedia{
+			{Type: entity.AttachmentPhoto, URL: "https://example.com/1.jpg"},
+			{Type: entity.AttachmentVideo, URL: "https://example.com/2.mp4?a=1&b=2"},
+		}
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "<b>caption</b>", ParseMode: "HTML", Media: media,
+			Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().SendMedia(uint64(userID), []entity.Media(media), "<b>caption</b>", "HTML", false).
+				Return(errors.NewTelegramError(400, "Bad Request: wrong file identifier/HTTP URL specified", 0)).Times(1)
+			m.EXPECT().Send(uint64(userID),
+				"<b>caption</b>\nhttps://example.com/1.jpg\nhttps://example.com/2.mp4?a=1&amp;b=2", "HTML", false).
+				Return(nil).Times(1)
+			repo.EXPECT().Delete(&delivery).Return(nil).Times(1)
+		})
+	})
+
+	t.Run("media without caption falls back to links", func(t *testing.T) {
+		t.Parallel()
+
+		media := entity.DeliveryMedia{{Type: entity.AttachmentPhoto, URL: "https://example.com/1.jpg"}}
+		delivery := entity.Delivery{ID: 1, ChatID: userID, ParseMode: "MarkdownV2", Media: media,
+			Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().SendMedia(uint64(userID), []entity.Media(media), "", "MarkdownV2", false).
+				Return(errors.NewTelegramError(400, "Bad Request: failed to get HTTP URL content", 0)).Times(1)
+			m.EXPECT().Send(uint64(userID), "https://example\\.com/1\\.jpg", "MarkdownV2", false).
+				Return(nil).Times(1)
+			repo.EXPECT().Delete(&delivery).Return(nil).Times(1)
+		})
+	})
+
+	t.Run("failed fallback is parked", func(t *testing.T) {
+		t.Parallel()
+
+		media := entity.DeliveryMedia{{Type: entity.AttachmentPhoto, URL: "https://example.com/1.jpg"}}
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "caption", ParseMode: "HTML", Media: media,
+			Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().SendMedia(uint64(userID), []entity.Media(media), "caption", "HTML", false).
+				Return(errors.NewTelegramError(400, "Bad Request: chat not found", 0)).Times(1)
+			m.EXPECT().Send(uint64(userID), "caption\nhttps://example.com/1.jpg", "HTML", false).
+				Return(errors.NewTelegramError(400, "Bad Request: chat not found", 0)).Times(1)
+			repo.EXPECT().Update(gomock.Any()).DoAndReturn(func(d *entity.Delivery) error {
+				assert.Equal(t, entity.DeliveryParked, d.Status)
+
+				return nil
+			}).Times(1)
+		})
+	})
+
+	t.Run("media is not replaced on blocked bot", func(t *testing.T) {
+		t.Parallel()
+
+		media := entity.DeliveryMedia{{Type: entity.AttachmentPhoto, URL: "https://example.com/1.jpg"}}
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "caption", ParseMode: "HTML", Media: media,
+			Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().SendMedia(uint64(userID), []entity.Media(media), "caption", "HTML", false).
+				Return(errors.NewTelegramError(403, "Forbidden: bot was blocked by the user", 0)).Times(1)
+			repo.EXPECT().Update(gomock.Any()).Return(nil).Times(1)
+		})
+	})
+
+	t.Run("silent delivery is sent without notification", func(t *testing.T) {
+		t.Parallel()
+
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "text", DisableNotification: true,
+			Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().Send(uint64(userID), "text", "", true).Return(nil).Times(1)
+			repo.EXPECT().Delete(&delivery).Return(nil).Times(1)
+		})
+	})
+
+	t.Run("network error is retried with backoff", func(t *testing.T) {
+		t.Parallel()
+
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "text", Status: entity.DeliveryPending, Attempts: 1}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().Send(uint64(userID), "text", "", false).Return(errors.ErrShutdownTimeout).Times(1)
+			repo.EXPECT().Update(gomock.Any()).DoAndReturn(func(d *entity.Delivery) error {
+				assert.Equal(t, entity.DeliveryPending, d.Status)
+				assert.Equal(t, 2, d.Attempts)
+				assert.Equal(t, errors.ErrShutdownTimeout.Error(), d.LastError)
+				assert.WithinDuration(t, time.Now().Add(10*time.Second), d.NextAttemptAt, time.Second)
+
+				return nil
+			}).Times(1)
+		})
+	})
+
+	t.Run("retry after is honored", func(t *testing.T) {
+		t.Parallel()
+
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "text", Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().Send(uint64(userID), "text", "", false).
+				Return(errors.NewTelegramError(429, "Too Many Requests", time.Minute)).Times(1)
+			repo.EXPECT().Update(gomock.Any()).DoAndReturn(func(d *entity.Delivery) error {
+				assert.Equal(t, entity.DeliveryPending, d.Status)
+				assert.WithinDuration(t, time.Now().Add(time.Minute), d.NextAttemptAt, time.Second)
+
+				return nil
+			}).Times(1)
+		})
+	})
+
+	t.Run("undeliverable is parked", func(t *testing.T) {
+		t.Parallel()
+
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "text", Status: entity.DeliveryPending}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().Send(uint64(userID), "text", "", false).
+				Return(errors.NewTelegramError(400, "Bad Request: chat not found", 0)).Times(1)
+			repo.EXPECT().Update(gomock.Any()).DoAndReturn(func(d *entity.Delivery) error {
+				assert.Equal(t, entity.DeliveryParked, d.Status)
+				assert.Equal(t, "telegram error 400: Bad Request: chat not found", d.LastError)
+
+				return nil
+			}).Times(1)
+		})
+	})
+
+	t.Run("parked after max attempts", func(t *testing.T) {
+		t.Parallel()
+
+		delivery := entity.Delivery{ID: 1, ChatID: userID, Text: "text", Status: entity.DeliveryPending, Attempts: 2}
+		dispatchOnce(t, []entity.Delivery{delivery}, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			m.EXPECT().Send(uint64(userID), "text", "", false).Return(errors.ErrShutdownTimeout).Times(1)
+			repo.EXPECT().Update(gomock.Any()).DoAndReturn(func(d *entity.Delivery) error {
+				assert.Equal(t, entity.DeliveryParked, d.Status)
+
+				return nil
+			}).Times(1)
+		})
+	})
+
+	t.Run("chat waits for its failed delivery", func(t *testing.T) {
+		t.Parallel()
+
+		deliveries := []entity.Delivery{
+			{ID: 1, ChatID: userID, Text: "album caption", Status: entity.DeliveryPending},
+			{ID: 2, ChatID: userID + 1, Text: "other chat", Status: entity.DeliveryPending},
+			{ID: 3, ChatID: userID, Text: "overflow", Status: entity.DeliveryPending},
+		}
+		dispatchOnce(t, deliveries, func(m *mocks.MockMessenger, repo *mocks.MockDeliveryRepo) {
+			gomock.InOrder(
+				m.EXPECT().Send(uint64(userID), "album caption", "", false).
+					Return(errors.NewTelegramError(429, "Too Many Requests", time.Minute)),
+				repo.EXPECT().Update(gomock.Any()).Return(nil),
+				m.EXPECT().Send(uint64(userID+1), "other chat", "", false).Return(nil),
+				repo.EXPECT().Delete(&deliveries[1]).Return(nil),
+			)
+		})
+	})
+}
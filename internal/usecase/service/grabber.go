@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/grabber"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/logger"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+)
+
+// SourceGrabber - fetches due feeds of the source once and enqueues new posts for all subscribers.
+// Feeds are fetched concurrently by at most workers goroutines, schedule sets the next check of each feed.
+// Subscribers of unavailable group are told about it once through the outbox.
+type SourceGrabber struct {
+	sleep       time.Duration
+	workers     int
+	schedule    entity.Schedule
+	source      usecase.Source
+	feedRepo    usecase.FeedRepo
+	messageRepo usecase.MessageRepo
+	renderer    markup.Renderer
+	translator  i18n.Translator
+	l           logger.InterfaceLogger
+}
+
+const (
+	_grabberMaxPages = 5
+)
+
+func NewGrabber(sleep time.Duration, workers int, schedule entity.Schedule, source usecase.Source,
+	feedRepo usecase.FeedRepo, messageRepo usecase.MessageRepo, renderer markup.Renderer, translator i18n.Translator,
+	l logger.InterfaceLogger) *SourceGrabber {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &SourceGrabber{
+		sleep:       sleep,
+		workers:     workers,
+		schedule:    schedule,
+		source:      source,
+		feedRepo:    feedRepo,
+		messageRepo: messageRepo,
+		renderer:    renderer,
+		translator:  translator,
+		l:           l,
+	}
+}
+
+// Start - grab due feeds every sleep until ctx is canceled, cycles of the source never overlap.
+func (g *SourceGrabber) Start(ctx context.Context) {
+	grabber.Run(ctx, g.sleep, g.grab)
+}
+
+// grab - one cycle over due feeds of the source, canceled ctx stops queueing feeds.
+// Cycle ends when every started feed is done. Failed authorization in the source stops the cycle, it is logged
+// once and the rest of feeds wait for the next cycle.
+func (g *SourceGrabber) grab(ctx context.Context) {
+	t := time.Now().UTC()
+
+	feeds, err := g.feedRepo.Due(g.source.Name(), t)
+	if err != nil {
+		g.l.Error(fmt.Errorf("`g.grab` something wrong: %w", err))
+
+		return
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	var authFailed sync.Once
+
+	queue := make(chan *entity.Feed)
+
+	workers := g.workers
+	if len(feeds) < workers {
+		workers = len(feeds)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for feed := range queue {
+				// feed taken after the stop is left for the next cycle
+				if ctx.Err() != nil {
+					continue
+				}
+
+				if err := g.checkFeed(ctx, feed, t); err != nil {
+					authFailed.Do(func() {
+						g.l.Error(fmt.Errorf("`g.grab` %s: %w", g.source.Name(), err))
+						stop()
+					})
+				}
+			}
+		}()
+	}
+
+	for i := range feeds {
+		if ctx.Err() != nil {
+			break
+		}
+
+		queue <- &feeds[i]
+	}
+
+	close(queue)
+	wg.Wait()
+}
+
+// checkFeed - failure of the feed doesn't affect others, it is saved to the feed until the next success.
+// The feed is scheduled for the next check in any case, except stop of the grabber in the middle of the check.
+// Failed authorization isn't a failure of the feed, it is returned and the feed is left as it is.
+func (g *SourceGrabber) checkFeed(ctx context.Context, feed *entity.Feed, t time.Time) error {
+	err := g.grabFeed(ctx, feed, t)
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+
+	if errors.IsVkAuth(err) {
+		return err
+	}
+
+	if err != nil {
+		g.l.Error(fmt.Errorf("`g.grab` %s %s: %w", g.source.Name(), feed.Name, err))
+
+		feed.ErrorCount++
+		feed.LastError = err.Error()
+		feed.LastErrorAt = &t
+
+		if errors.Is(err, errors.ErrGroupUnavailable) {
+			g.markBroken(feed, t)
+		}
+	} else {
+		feed.ErrorCount = 0
+		feed.LastError = ""
+		feed.LastErrorAt = nil
+
+		g.markWorking(feed)
+	}
+
+	feed.NextCheckAt = g.schedule.NextCheck(feed, t, rand.Float64()) //nolint:gosec // jitter doesn't need crypto
+	if feed.BacklogCursor != "" {
+		feed.NextCheckAt = t
+	}
+
+	if err := g.feedRepo.Update(feed); err != nil {
+		g.l.Error(fmt.Errorf("`g.grab` %s %s: %w", g.source.Name(), feed.Name, err))
+	}
+
+	return nil
+}
+
+// markBroken - subscribers who don't know yet that the group is unavailable are told about it, notices are
+// enqueued together with the mark.
+func (g *SourceGrabber) markBroken(feed *entity.Feed, t time.Time) {
+	var broken []*entity.Subscription
+
+	for i := range feed.Subscriptions {
+		if feed.Subscriptions[i].BrokenAt == nil {
+			broken = append(broken, &feed.Subscriptions[i])
+		}
+	}
+
+	if len(broken) == 0 {
+		return
+	}
+
+	deliveries := make([]entity.Delivery, len(broken))
+	for i, subscription := range broken {
+		deliveries[i] = g.unavailableDelivery(&subscription.User, feed)
+		quietDeliveries(&subscription.User, deliveries[i:i+1], t)
+	}
+
+	if err := g.feedRepo.SetBroken(feed.ID, &t, deliveries); err != nil {
+		g.l.Error(fmt.Errorf("`g.markBroken` %s %s: %w", g.source.Name(), feed.Name, err))
+
+		return
+	}
+
+	for _, subscription := range broken {
+		subscription.BrokenAt = &t
+	}
+}
+
+// unavailableDelivery - notice to the subscriber in their language that the group is unavailable.
+func (g *SourceGrabber) unavailableDelivery(user *entity.User, feed *entity.Feed) entity.Delivery {
+	l := localizer{g.translator, user.PreferredLanguage()}
+
+	return entity.Delivery{
+		ChatID:    user.TelegramID,
+		Text:      g.renderer.Render([]markup.Part{markup.Text(l.t("group_unavailable")), markup.CodeText(feed.Name)}),
+		ParseMode: g.renderer.ParseMode(),
+	}
+}
+
+// markWorking - group is available again, subscriptions are not broken anymore.
+func (g *SourceGrabber) markWorking(feed *entity.Feed) {
+	for i := range feed.Subscriptions {
+		if feed.Subscriptions[i].BrokenAt == nil {
+			continue
+		}
+
+		if err := g.feedRepo.SetBroken(feed.ID, nil, nil); err != nil {
+			g.l.Error(fmt.Errorf("`g.markWorking` %s %s: %w", g.source.Name(), feed.Name, err))
+		}
+
+		return
+	}
+}
+
+// scanBound - posts at or below it are saved already, by Seq when the source numbers posts or by date otherwise.
+type scanBound struct {
+	seq   uint64
+	since time.Time
+}
+
+// seen - the post is older than saved ones. Posts at the same time as since are checked one by one.
+func (b scanBound) seen(post *entity.Post) bool {
+	if b.seq != 0 && post.Seq != 0 {
+		return post.Seq <= b.seq
+	}
+
+	return !post.Date.IsZero() && post.Date.Before(b.since)
+}
+
+// grabFeed - save new posts of the feed. Posts which didn't fit into _grabberMaxPages pages are the backlog,
+// next checks scan it from its cursor down to the posts saved before it.
+func (g *SourceGrabber) grabFeed(ctx context.Context, feed *entity.Feed, t time.Time) error {
+	lastMessage := g.messageRepo.Last(feed.ID)
+	since := startAt(feed)
+
+	if lastMessage.ID != 0 {
+		since = lastMessage.MessageAt
+	}
+
+	if since.After(t) {
+		return nil
+	}
+
+	bound, cursor := scanBound{seq: feed.LastSeq, since: since}, ""
+	if feed.BacklogCursor != "" {
+		bound, cursor = scanBound{seq: feed.BacklogSeq}, feed.BacklogCursor
+		if feed.BacklogSince != nil {
+			bound.since = *feed.BacklogSince
+		}
+	}
+
+	posts, next, err := g.newPosts(ctx, feed, cursor, bound)
+	if err != nil {
+		return err
+	}
+
+	for i := len(posts) - 1; i >= 0; i-- {
+		post := &posts[i]
+
+		messageAt := post.Date
+		if messageAt.IsZero() {
+			messageAt = t
+		}
+
+		var (
+			deliveries  []entity.Delivery
+			digestItems []entity.DigestItem
+		)
+
+		// Posts without date can't be compared with the start date, so the first sync only remembers them.
+		if lastMessage.ID != 0 || !post.Date.IsZero() {
+			deliveries, digestItems = g.fanOut(feed, post, messageAt, t)
+		}
+
+		err = g.messageRepo.Add(feed.ID, post.ID, g.source.Name(), messageAt, deliveries, digestItems)
+		if err != nil {
+			return err
+		}
+
+		if post.Seq > feed.LastSeq {
+			feed.LastSeq = post.Seq
+		}
+	}
+
+	feed.BacklogCursor, feed.BacklogSeq, feed.BacklogSince = next, 0, nil
+	if next != "" {
+		feed.BacklogSeq, feed.BacklogSince = bound.seq, &bound.since
+	}
+
+	// new posts were published since the previous check
+	if !feed.LastUpdateAt.IsZero() {
+		feed.ObservePosts(len(posts), t.Sub(feed.LastUpdateAt))
+	}
+
+	feed.LastUpdateAt = t
+
+	return nil
+}
+
+// newPosts - not saved posts from the cursor page down to the bound, sorted from new to old.
+// At most _grabberMaxPages pages are scanned, next is the cursor of the rest then.
+func (g *SourceGrabber) newPosts(ctx context.Context, feed *entity.Feed, cursor string, bound scanBound) (
+	posts []entity.Post, next string, err error) {
+	for page := 0; page < _grabberMaxPages; page++ {
+		result, err := g.source.GetPosts(ctx, feed.Name, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for i := range result.Posts {
+			post := result.Posts[i]
+			if bound.seen(&post) {
+				// pinned post is followed by newer ones
+				if post.Pinned {
+					continue
+				}
+
+				return posts, "", nil
+			}
+
+			exists, err := g.messageRepo.Exists(feed.ID, post.ID)
+			if err != nil {
+				return nil, "", err
+			}
+
+			if !exists {
+				posts = append(posts, post)
+			}
+		}
+
+		if result.Next == "" || len(result.Posts) == 0 {
+			return posts, "", nil
+		}
+
+		cursor = result.Next
+	}
+
+	return posts, cursor, nil
+}
+
+// startAt - the earliest start date of subscribers, it is used before the first saved message.
+func startAt(feed *entity.Feed) time.Time {
+	since := feed.LastUpdateAt
+
+	for i := range feed.Subscriptions {
+		if startAt := feed.Subscriptions[i].StartAt; startAt.Before(since) {
+			since = startAt
+		}
+	}
+
+	return since
+}
+
+// fanOut - deliveries of the post to every active subscriber who started before it and whose filters
+// match it, post may take a few messages. Post is rendered once for every language of subscribers.
+// Subscribers in digest mode get digest item instead of deliveries, quiet hours of subscribers are applied.
+// Posts of paused subscriptions are held until resume, muted subscriptions miss posts.
+func (g *SourceGrabber) fanOut(feed *entity.Feed, post *entity.Post, postAt,
+	t time.Time) ([]entity.Delivery, []entity.DigestItem) {
+	var (
+		deliveries  []entity.Delivery
+		digestItems []entity.DigestItem
+		rendered    = make(map[string][]entity.Delivery)
+	)
+
+	for i := range feed.Subscriptions {
+		subscription := &feed.Subscriptions[i]
+		if subscription.Muted(t) || !post.Date.IsZero() && post.Date.Before(subscription.StartAt) {
+			continue
+		}
+
+		if !subscription.Filters.Match(post) {
+			continue
+		}
+
+		if !subscription.Paused && subscription.User.Digest() {
+			digestItems = append(digestItems, entity.DigestItem{
+				UserID: subscription.UserID,
+				FeedID: feed.ID,
+				Title:  digestTitle(post),
+				Link:   post.Link,
+				PostAt: postAt,
+			})
+
+			continue
+		}
+
+		lang := subscription.User.PreferredLanguage()
+
+		messages, ok := rendered[lang]
+		if !ok {
+			messages = PostDeliveries(post, g.renderer, g.translator, lang)
+			rendered[lang] = messages
+		}
+
+		userDeliveries := make([]entity.Delivery, len(messages))
+		for j, message := range messages {
+			message.ChatID = subscription.User.TelegramID
+			userDeliveries[j] = message
+		}
+
+		if subscription.Paused {
+			holdDeliveries(subscription, userDeliveries)
+		} else {
+			quietDeliveries(&subscription.User, userDeliveries, t)
+		}
+
+		deliveries = append(deliveries, userDeliveries...)
+	}
+
+	return deliveries, digestItems
+}
+
+// holdDeliveries - posts of paused subscription wait for its resume with catching up.
+func holdDeliveries(subscription *entity.Subscription, deliveries []entity.Delivery) {
+	for i := range deliveries {
+		deliveries[i].Status = entity.DeliveryHeld
+		deliveries[i].SubscriptionID = subscription.ID
+	}
+}
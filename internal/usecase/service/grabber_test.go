@@ -0,0 +1,606 @@
+package service_test
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase"
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+	"github.com/jokius/news-telegram-bot/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBrokenGroup = stderrors.New("broken group")
+
+// slowSource - source with latency, it counts fetches of groups and their concurrency.
+type slowSource struct {
+	usecase.Source
+
+	latency time.Duration
+	broken  string
+
+	mu      sync.Mutex
+	running int
+	maxRun  int
+	fetched map[string]int
+}
+
+func (s *slowSource) Name() string { return "slow" }
+
+func (s *slowSource) GetPosts(_ context.Context, group, _ string) (entity.PostsPage, error) {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.maxRun {
+		s.maxRun = s.running
+	}
+	s.fetched[group]++
+	s.mu.Unlock()
+
+	time.Sleep(s.latency)
+
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+
+	if group == s.broken {
+		return entity.PostsPage{}, errBrokenGroup
+	}
+
+	return entity.PostsPage{}, nil
+}
+
+func TestGrabberStart(t *testing.T) {
+	t.Parallel()
+
+	mockCtl := gomock.NewController(t)
+	source := mocks.NewMockSource(mockCtl)
+	feedRepo := mocks.NewMockFeedRepo(mockCtl)
+	messageRepo := mocks.NewMockMessageRepo(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+
+	schedule := entity.Schedule{Min: 5 * time.Minute, Max: 6 * time.Hour}
+	startAt := time.Date(2021, 11, 15, 0, 0, 0, 0, time.UTC)
+	otherUserID := uint64(userID + 1)
+	mutedUntil := time.Now().Add(time.Hour)
+	filteredID := otherUserID + 3
+	digestUserID := uint64(10)
+	pausedID := uint64(7)
+	silentID := otherUserID + 5
+	quietNow := time.Now().UTC()
+	silentUser := entity.User{TelegramID: silentID, QuietFrom: quietNow.Add(-time.Hour).Format("15:04"),
+		QuietTo: quietNow.Add(time.Hour).Format("15:04"), QuietMode: entity.QuietSilent}
+	feed := entity.Feed{ID: 1, Name: "test_group", LastUpdateAt: startAt.Add(3 * time.Hour), Subscriptions: []entity.Subscription{
+		{StartAt: startAt, User: entity.User{TelegramID: userID}},
+		{StartAt: startAt.Add(90 * time.Minute), User: entity.User{TelegramID: otherUserID}},
+		{ID: pausedID, StartAt: startAt, Paused: true, User: entity.User{TelegramID: otherUserID + 1}},
+		{StartAt: startAt, MutedUntil: &mutedUntil, User: entity.User{TelegramID: otherUserID + 2}},
+		{StartAt: startAt, Filters: entity.Filters{{Pattern: "golang"}}, User: entity.User{TelegramID: filteredID}},
+		{UserID: digestUserID, StartAt: startAt, User: entity.User{ID: digestUserID, DigestMode: entity.DigestHourly}},
+		{StartAt: startAt.Add(90 * time.Minute), User: silentUser},
+	}}
+	posts := []entity.Post{
+		{ID: "3", Date: startAt.Add(2 * time.Hour), Link: "https://example.com/3"},
+		{ID: "2", Date: startAt.Add(time.Hour), Link: "https://example.com/2"},
+		{ID: "1", Date: startAt.Add(-time.Hour), Link: "https://example.com/1"},
+	}
+
+	source.EXPECT().Name().Return("test").AnyTimes()
+	feedRepo.EXPECT().Due("test", gomock.Any()).Return([]entity.Feed{feed}, nil).Times(1)
+	messageRepo.EXPECT().Last(feed.ID).Return(entity.Message{}).Times(1)
+	source.EXPECT().GetPosts(gomock.Any(), "test_group", "").Return(entity.PostsPage{Posts: posts, Next: "3"}, nil).Times(1)
+	messageRepo.EXPECT().Exists(feed.ID, gomock.Any()).Return(false, nil).Times(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delivery := func(chatID uint64, link string) entity.Delivery {
+		return entity.Delivery{ChatID: chatID, Text: `<a href="` + link + `">` + link + `</a>`, ParseMode: "HTML"}
+	}
+	silent := func(delivery entity.Delivery) entity.Delivery {
+		delivery.DisableNotification = true
+
+		return delivery
+	}
+	held := func(delivery entity.Delivery) entity.Delivery {
+		delivery.Status = entity.DeliveryHeld
+		delivery.SubscriptionID = pausedID
+
+		return delivery
+	}
+	digestItem := func(post entity.Post) []entity.DigestItem {
+		return []entity.DigestItem{{UserID: digestUserID, FeedID: feed.ID, Link: post.Link, PostAt: post.Date}}
+	}
+
+	gomock.InOrder(
+		messageRepo.EXPECT().
+			Add(feed.ID, "2", "test", posts[1].Date, []entity.Delivery{
+				delivery(userID, "https://example.com/2"),
+				held(delivery(otherUserID+1, "https://example.com/2")),
+			},
+				digestItem(posts[1])).
+			Return(nil),
+		messageRepo.EXPECT().
+			Add(feed.ID, "3", "test", posts[0].Date, []entity.Delivery{
+				delivery(userID, "https://example.com/3"),
+				delivery(otherUserID, "https://example.com/3"),
+				held(delivery(otherUserID+1, "https://example.com/3")),
+				silent(delivery(silentID, "https://example.com/3")),
+			}, digestItem(posts[0])).
+			Return(nil),
+		feedRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(updated *entity.Feed) error {
+			assert.True(t, updated.LastUpdateAt.After(feed.LastUpdateAt))
+			assert.Positive(t, updated.PostInterval)
+			assert.True(t, updated.NextCheckAt.After(updated.LastUpdateAt.Add(schedule.Max-time.Minute)))
+			cancel()
+
+			return nil
+		}),
+	)
+
+	grabber := service.NewGrabber(time.Hour, 1, schedule, source, feedRepo, messageRepo, markup.HTML,
+		i18n.Keys{}, logger)
+	stopped := make(chan struct{})
+
+	go func() {
+		grabber.Start(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("grabber didn't stop after cycle")
+	}
+}
+
+func TestGrabberWorkers(t *testing.T) {
+	t.Parallel()
+
+	mockCtl := gomock.NewController(t)
+	feedRepo := mocks.NewMockFeedRepo(mockCtl)
+	messageRepo := mocks.NewMockMessageRepo(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+
+	const (
+		workers = 3
+		latency = 50 * time.Millisecond
+	)
+
+	lastUpdateAt := time.Now().UTC().Add(-time.Hour)
+	names := []string{"group_1", "group_2", "broken", "group_4", "group_5", "group_6", "group_7"}
+	feeds := make([]entity.Feed, len(names))
+
+	for i, name := range names {
+		feeds[i] = entity.Feed{ID: uint64(i + 1), Name: name, LastUpdateAt: lastUpdateAt, ErrorCount: 2}
+	}
+
+	schedule := entity.Schedule{Min: 5 * time.Minute, Max: 6 * time.Hour, Jitter: 0.1}
+	source := &slowSource{latency: latency, broken: "broken", fetched: make(map[string]int)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		updated = make(map[string]entity.Feed)
+	)
+
+	feedRepo.EXPECT().Due("slow", gomock.Any()).Return(feeds, nil).Times(1)
+	messageRepo.EXPECT().Last(gomock.Any()).Return(entity.Message{}).Times(len(feeds))
+	logger.EXPECT().Error(gomock.Any()).Do(func(err interface{}, _ ...interface{}) {
+		assert.ErrorIs(t, err.(error), errBrokenGroup)
+	}).Times(1)
+	feedRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(feed *entity.Feed) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		updated[feed.Name] = *feed
+		if len(updated) == len(feeds) {
+			cancel()
+		}
+
+		return nil
+	}).Times(len(feeds))
+
+	grabber := service.NewGrabber(time.Hour, workers, schedule, source, feedRepo, messageRepo,
+		markup.HTML, i18n.Keys{}, logger)
+	startedAt := time.Now()
+	stopped := make(chan struct{})
+
+	go func() {
+		grabber.Start(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("grabber didn't stop after cycle")
+	}
+
+	elapsed := time.Since(startedAt)
+
+	assert.Equal(t, workers, source.maxRun)
+	assert.Less(t, int64(elapsed), int64(time.Duration(len(feeds))*latency))
+
+	require.Len(t, updated, len(feeds))
+
+	for _, name := range names {
+		assert.Equal(t, 1, source.fetched[name], name)
+
+		feed := updated[name]
+		if name == "broken" {
+			assert.Equal(t, 3, feed.ErrorCount)
+			assert.Equal(t, errBrokenGroup.Error(), feed.LastError)
+			require.NotNil(t, feed.LastErrorAt)
+			assert.Equal(t, lastUpdateAt, feed.LastUpdateAt)
+			assert.GreaterOrEqual(t, int64(feed.NextCheckAt.Sub(*feed.LastErrorAt)), int64(7*schedule.Min), "backoff")
+
+			continue
+		}
+
+		assert.Equal(t, 0, feed.ErrorCount, name)
+		assert.Empty(t, feed.LastError, name)
+		assert.Nil(t, feed.LastErrorAt, name)
+		assert.True(t, feed.LastUpdateAt.After(lastUpdateAt), name)
+		assert.True(t, feed.NextCheckAt.After(feed.LastUpdateAt), name)
+	}
+}
+
+func TestGrabberAuthFailed(t *testing.T) {
+	t.Parallel()
+
+	mockCtl := gomock.NewController(t)
+	source := mocks.NewMockSource(mockCtl)
+	feedRepo := mocks.NewMockFeedRepo(mockCtl)
+	messageRepo := mocks.NewMockMessageRepo(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+	schedule := entity.Schedule{Min: 5 * time.Minute, Max: 6 * time.Hour}
+	feeds := []entity.Feed{{ID: 1, Name: "group_1"}, {ID: 2, Name: "group_2"}, {ID: 3, Name: "group_3"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	source.EXPECT().Name().Return("vk").AnyTimes()
+	feedRepo.EXPECT().Due("vk", gomock.Any()).Return(feeds, nil).Times(1)
+	messageRepo.EXPECT().Last(gomock.Any()).Return(entity.Message{}).Times(1)
+	source.EXPECT().GetPosts(gomock.Any(), "group_1", "").
+		Return(entity.PostsPage{}, errors.NewVkError(errors.VkAuthFailed, "User authorization failed")).Times(1)
+	logger.EXPECT().Error(gomock.Any()).Do(func(err interface{}, _ ...interface{}) {
+		assert.True(t, errors.IsVkAuth(err.(error)))
+	}).Times(1)
+	// the feed keeps its error count and next check
+	feedRepo.EXPECT().Update(gomock.Any()).Times(0)
+
+	grabber := service.NewGrabber(time.Hour, 1, schedule, source, feedRepo, messageRepo, markup.HTML,
+		i18n.Keys{}, logger)
+	grabber.Start(ctx)
+}
+
+// grabCycle - one cycle of the grabber over the feed, the feed saved at its end is returned.
+func grabCycle(t *testing.T, source *mocks.MockSource, feed entity.Feed,
+	messageRepo *mocks.MockMessageRepo) entity.Feed {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	feedRepo := mocks.NewMockFeedRepo(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+	schedule := entity.Schedule{Min: 5 * time.Minute, Max: 6 * time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var updated entity.Feed
+
+	source.EXPECT().Name().Return("vk").AnyTimes()
+	feedRepo.EXPECT().Due("vk", gomock.Any()).Return([]entity.Feed{feed}, nil).Times(1)
+	feedRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(saved *entity.Feed) error {
+		updated = *saved
+
+		cancel()
+
+		return nil
+	}).Times(1)
+
+	grabber := service.NewGrabber(time.Hour, 1, schedule, source, feedRepo, messageRepo, markup.HTML,
+		i18n.Keys{}, logger)
+	stopped := make(chan struct{})
+
+	go func() {
+		grabber.Start(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("grabber didn't stop after cycle")
+	}
+
+	return updated
+}
+
+func TestGrabberNewPosts(t *testing.T) {
+	t.Parallel()
+
+	lastAt := time.Date(2021, 12, 9, 10, 0, 0, 0, time.UTC)
+	lastMessage := entity.Message{ID: 1, MessageAt: lastAt}
+	feed := entity.Feed{ID: 1, Name: "test_group", LastUpdateAt: lastAt}
+
+	t.Run("stop at saved post number", func(t *testing.T) {
+		t.Parallel()
+
+		mockCtl := gomock.NewController(t)
+		source := mocks.NewMockSource(mockCtl)
+		messageRepo := mocks.NewMockMessageRepo(mockCtl)
+		current := feed
+		current.LastSeq = 10
+
+		// equal dates don't matter when posts are numbered
+		posts := []entity.Post{
+			{ID: "3", Seq: 3, Pinned: true, Date: lastAt.Add(-48 * time.Hour)},
+			{ID: "12", Seq: 12, Date: lastAt},
+			{ID: "11", Seq: 11, Date: lastAt},
+			{ID: "10", Seq: 10, Date: lastAt},
+			{ID: "9", Seq: 9, Date: lastAt},
+		}
+
+		messageRepo.EXPECT().Last(feed.ID).Return(lastMessage).Times(1)
+		source.EXPECT().GetPosts(gomock.Any(), "test_group", "").Return(entity.PostsPage{Posts: posts, Next: "5"}, nil).Times(1)
+		messageRepo.EXPECT().Exists(feed.ID, gomock.Any()).Return(false, nil).Times(2)
+		gomock.InOrder(
+			messageRepo.EXPECT().Add(feed.ID, "11", "vk", lastAt, nil, nil).Return(nil),
+			messageRepo.EXPECT().Add(feed.ID, "12", "vk", lastAt, nil, nil).Return(nil),
+		)
+
+		updated := grabCycle(t, source, current, messageRepo)
+		assert.Equal(t, uint64(12), updated.LastSeq)
+	})
+
+	t.Run("skip old pinned post without saved numbers", func(t *testing.T) {
+		t.Parallel()
+
+		mockCtl := gomock.NewController(t)
+		source := mocks.NewMockSource(mockCtl)
+		messageRepo := mocks.NewMockMessageRepo(mockCtl)
+		posts := []entity.Post{
+			{ID: "3", Seq: 3, Pinned: true, Date: lastAt.Add(-48 * time.Hour)},
+			{ID: "12", Seq: 12, Date: lastAt.Add(time.Hour)},
+			{ID: "11", Seq: 11, Date: lastAt},
+			{ID: "10", Seq: 10, Date: lastAt},
+			{ID: "9", Seq: 9, Date: lastAt.Add(-time.Hour)},
+		}
+
+		messageRepo.EXPECT().Last(feed.ID).Return(lastMessage).Times(1)
+		source.EXPECT().GetPosts(gomock.Any(), "test_group", "").Return(entity.PostsPage{Posts: posts, Next: "5"}, nil).Times(1)
+		messageRepo.EXPECT().Exists(feed.ID, "12").Return(false, nil).Times(1)
+		messageRepo.EXPECT().Exists(feed.ID, "11").Return(false, nil).Times(1)
+		messageRepo.EXPECT().Exists(feed.ID, "10").Return(true, nil).Times(1)
+		gomock.InOrder(
+			messageRepo.EXPECT().Add(feed.ID, "11", "vk", lastAt, nil, nil).Return(nil),
+			messageRepo.EXPECT().Add(feed.ID, "12", "vk", lastAt.Add(time.Hour), nil, nil).Return(nil),
+		)
+
+		updated := grabCycle(t, source, feed, messageRepo)
+		assert.Equal(t, uint64(12), updated.LastSeq)
+	})
+
+	t.Run("measure post interval since the previous check", func(t *testing.T) {
+		t.Parallel()
+
+		mockCtl := gomock.NewController(t)
+		source := mocks.NewMockSource(mockCtl)
+		messageRepo := mocks.NewMockMessageRepo(mockCtl)
+		now := time.Now().UTC().Truncate(time.Second)
+		current := feed
+		current.LastUpdateAt = now.Add(-2 * time.Hour)
+		posts := []entity.Post{
+			{ID: "2", Date: now.Add(-30 * time.Minute)},
+			{ID: "1", Date: now.Add(-time.Hour)},
+		}
+
+		messageRepo.EXPECT().Last(feed.ID).Return(lastMessage).Times(1)
+		source.EXPECT().GetPosts(gomock.Any(), "test_group", "").Return(entity.PostsPage{Posts: posts}, nil).Times(1)
+		messageRepo.EXPECT().Exists(feed.ID, gomock.Any()).Return(false, nil).Times(2)
+		messageRepo.EXPECT().Add(feed.ID, gomock.Any(), "vk", gomock.Any(), nil, nil).Return(nil).Times(2)
+
+		// 2 posts in 2 hours, the last saved post is much older
+		updated := grabCycle(t, source, current, messageRepo)
+		assert.InDelta(t, 3600, updated.PostInterval, 1)
+	})
+
+	t.Run("stop at empty page", func(t *testing.T) {
+		t.Parallel()
+
+		mockCtl := gomock.NewController(t)
+		source := mocks.NewMockSource(mockCtl)
+		messageRepo := mocks.NewMockMessageRepo(mockCtl)
+
+		messageRepo.EXPECT().Last(feed.ID).Return(lastMessage).Times(1)
+		source.EXPECT().GetPosts(gomock.Any(), "test_group", "").Return(entity.PostsPage{Next: "100"}, nil).Times(1)
+
+		updated := grabCycle(t, source, feed, messageRepo)
+		assert.Zero(t, updated.LastSeq)
+	})
+
+	t.Run("continue backlog longer than max pages", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			pages   = 7
+			perPage = 10
+		)
+
+		mockCtl := gomock.NewController(t)
+		source := mocks.NewMockSource(mockCtl)
+		messageRepo := mocks.NewMockMessageRepo(mockCtl)
+		current := feed
+		current.LastSeq = 5
+		saved := make(map[string]int)
+
+		// posts from 70 down to 1, 10 per page
+		source.EXPECT().GetPosts(gomock.Any(), "test_group", gomock.Any()).DoAndReturn(func(_ context.Context, _,
+			cursor string) (entity.PostsPage, error) {
+			page, _ := strconv.Atoi(cursor)
+			result := entity.PostsPage{}
+
+			for i := 0; i < perPage; i++ {
+				seq := uint64((pages-page)*perPage - i)
+				result.Posts = append(result.Posts, entity.Post{ID: strconv.FormatUint(seq, 10), Seq: seq})
+			}
+
+			if page+1 < pages {
+				result.Next = strconv.Itoa(page + 1)
+			}
+
+			return result, nil
+		}).AnyTimes()
+		messageRepo.EXPECT().Last(feed.ID).Return(lastMessage).Times(2)
+		messageRepo.EXPECT().Exists(feed.ID, gomock.Any()).DoAndReturn(func(_ uint64, id string) (bool, error) {
+			return saved[id] > 0, nil
+		}).AnyTimes()
+		messageRepo.EXPECT().Add(feed.ID, gomock.Any(), "vk", gomock.Any(), nil, nil).
+			DoAndReturn(func(_ uint64, id, _ string, _ time.Time, _ []entity.Delivery, _ []entity.DigestItem) error {
+				saved[id]++
+
+				return nil
+			}).AnyTimes()
+
+		updated := grabCycle(t, source, current, messageRepo)
+		assert.Equal(t, uint64(70), updated.LastSeq)
+		assert.Equal(t, "5", updated.BacklogCursor)
+		assert.Equal(t, uint64(5), updated.BacklogSeq)
+		assert.Equal(t, updated.LastUpdateAt, updated.NextCheckAt, "backlog is scanned on the next tick")
+		assert.Len(t, saved, 50)
+
+		updated = grabCycle(t, source, updated, messageRepo)
+		assert.Equal(t, uint64(70), updated.LastSeq)
+		assert.Empty(t, updated.BacklogCursor)
+		assert.Len(t, saved, 65)
+
+		for seq := 6; seq <= 70; seq++ {
+			assert.Equal(t, 1, saved[strconv.Itoa(seq)], seq)
+		}
+	})
+}
+
+func TestGrabberUnavailableGroup(t *testing.T) {
+	t.Parallel()
+
+	brokenAt := time.Date(2021, 12, 10, 10, 0, 0, 0, time.UTC)
+	feed := entity.Feed{ID: 1, Name: "closed_group", LastUpdateAt: brokenAt, Subscriptions: []entity.Subscription{
+		{ID: 1, User: entity.User{TelegramID: userID}},
+		{ID: 2, BrokenAt: &brokenAt, User: entity.User{TelegramID: userID + 1}},
+	}}
+	unavailable := errors.NewVkError(errors.VkAccessDenied, "Access denied: this wall available only for community members")
+
+	for name, tc := range map[string]struct {
+		err      error
+		broken   bool
+		notified []uint64
+	}{
+		"unavailable":     {unavailable, true, []uint64{userID}},
+		"other error":     {errors.NewVkError(10, "Internal server error"), false, nil},
+		"available again": {nil, false, nil},
+	} {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			mockCtl := gomock.NewController(t)
+			source := mocks.NewMockSource(mockCtl)
+			feedRepo := mocks.NewMockFeedRepo(mockCtl)
+			messageRepo := mocks.NewMockMessageRepo(mockCtl)
+			logger := mocks.NewMockInterfaceLogger(mockCtl)
+			schedule := entity.Schedule{Min: 5 * time.Minute, Max: 6 * time.Hour}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			current := feed
+			current.Subscriptions = append([]entity.Subscription(nil), feed.Subscriptions...)
+
+			source.EXPECT().Name().Return("vk").AnyTimes()
+			feedRepo.EXPECT().Due("vk", gomock.Any()).Return([]entity.Feed{current}, nil).Times(1)
+			messageRepo.EXPECT().Last(feed.ID).Return(entity.Message{ID: 1, MessageAt: brokenAt}).Times(1)
+			source.EXPECT().GetPosts(gomock.Any(), "closed_group", "").Return(entity.PostsPage{}, tc.err).Times(1)
+
+			switch {
+			case tc.err != nil:
+				logger.EXPECT().Error(gomock.Any()).Times(1)
+			default:
+				feedRepo.EXPECT().SetBroken(feed.ID, nil, nil).Return(nil).Times(1)
+			}
+
+			if tc.broken {
+				feedRepo.EXPECT().SetBroken(feed.ID, gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+					func(_ uint64, _ *time.Time, deliveries []entity.Delivery) error {
+						require.Len(t, deliveries, len(tc.notified))
+
+						for i, id := range tc.notified {
+							assert.Equal(t, entity.Delivery{
+								ChatID:    id,
+								Text:      "group_unavailable<code>closed_group</code>",
+								ParseMode: markup.HTML.ParseMode(),
+							}, deliveries[i])
+						}
+
+						return nil
+					}).Times(1)
+			}
+
+			feedRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(*entity.Feed) error {
+				cancel()
+
+				return nil
+			}).Times(1)
+
+			grabber := service.NewGrabber(time.Hour, 1, schedule, source, feedRepo, messageRepo, markup.HTML,
+				i18n.Keys{}, logger)
+			grabber.Start(ctx)
+		})
+	}
+}
+
+func TestGrabberStop(t *testing.T) {
+	t.Parallel()
+
+	mockCtl := gomock.NewController(t)
+	source := mocks.NewMockSource(mockCtl)
+	feedRepo := mocks.NewMockFeedRepo(mockCtl)
+	messageRepo := mocks.NewMockMessageRepo(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+	schedule := entity.Schedule{Min: 5 * time.Minute, Max: 6 * time.Hour}
+	feed := entity.Feed{ID: 1, Name: "test_group", LastUpdateAt: time.Now().UTC()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source.EXPECT().Name().Return("vk").AnyTimes()
+	feedRepo.EXPECT().Due("vk", gomock.Any()).Return([]entity.Feed{feed}, nil).Times(1)
+	messageRepo.EXPECT().Last(feed.ID).Return(entity.Message{}).Times(1)
+	source.EXPECT().GetPosts(gomock.Any(), "test_group", "").DoAndReturn(
+		func(ctx context.Context, _, _ string) (entity.PostsPage, error) {
+			cancel()
+
+			return entity.PostsPage{}, ctx.Err()
+		}).Times(1)
+
+	// the feed isn't saved with error, it is still due on the next start
+	feedRepo.EXPECT().Update(gomock.Any()).Times(0)
+
+	grabber := service.NewGrabber(time.Hour, 1, schedule, source, feedRepo, messageRepo,
+		markup.HTML, i18n.Keys{}, logger)
+	grabber.Start(ctx)
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+)
+
+// localizer - translator bound to language of the user.
+type localizer struct {
+	translator i18n.Translator
+	lang       string
+}
+
+func (l localizer) t(key string, args ...interface{}) string {
+	return l.translator.Translate(l.lang, key, args...)
+}
+
+func (l localizer) plural(key string, count int, args ...interface{}) string {
+	return l.translator.Plural(l.lang, key, count, args...)
+}
+
+// localizer - preferred language of the user, unknown language is translated to default one.
+func (m *Messenger) localizer(user *entity.User) localizer {
+	return localizer{m.translator, user.PreferredLanguage()}
+}
@@ -2,12 +2,22 @@ package service_test
 
 import (
 	"encoding/json"
+	"io"
+	"math"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/locales"
 	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
 	"github.com/jokius/news-telegram-bot/pkg/mocks"
+	"github.com/jokius/news-telegram-bot/pkg/ratelimit"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,26 +28,51 @@ const (
 	url         = "https://telegram.test.url/token/sendMessage"
 )
 
+// telegramUser - recipient of replies, its language is unknown.
+var telegramUser = &entity.User{TelegramID: userID}
+
 func marshalJSON(text string) ([]byte, error) {
 	params := struct {
-		ChatID uint64 `json:"chat_id"`
-		Text   string `json:"text"`
-	}{userID, text}
+		ChatID    uint64 `json:"chat_id"`
+		Text      string `json:"text"`
+		ParseMode string `json:"parse_mode"`
+	}{userID, text, "HTML"}
 
 	return json.Marshal(params)
 }
 
+func okResponse() *http.Response {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"ok":true}`))}
+}
+
+// unlimited - limits are tested with fake clock, other tests send messages without waiting.
+func unlimited() *ratelimit.Limiter {
+	return ratelimit.New(ratelimit.Global(math.MaxInt16, time.Second), ratelimit.PerKey(math.MaxInt16, time.Second))
+}
+
 func messenger(t *testing.T) (*service.Messenger, *mocks.MockInterfaceClient) {
 	t.Helper()
 
+	newMessenger, client, _ := messengerWithRepo(t)
+
+	return newMessenger, client
+}
+
+func messengerWithRepo(t *testing.T) (*service.Messenger, *mocks.MockInterfaceClient, *mocks.MockUserRepo) {
+	t.Helper()
+
 	mockCtl := gomock.NewController(t)
 	client := mocks.NewMockInterfaceClient(mockCtl)
 	logger := mocks.NewMockInterfaceLogger(mockCtl)
 	source := mocks.NewMockSource(mockCtl)
+	userRepo := mocks.NewMockUserRepo(mockCtl)
 
-	newMessenger := service.NewMessenger(token, testBaseURL, client, source, logger)
+	logger.EXPECT().Debug(gomock.Any()).AnyTimes()
 
-	return newMessenger, client
+	newMessenger := service.NewMessenger(token, testBaseURL, client, source, userRepo, markup.HTML, i18n.Keys{},
+		unlimited(), logger)
+
+	return newMessenger, client, userRepo
 }
 
 func TestURLAdded(t *testing.T) {
@@ -48,10 +83,10 @@ func TestURLAdded(t *testing.T) {
 	t.Run("send message to user", func(t *testing.T) {
 		t.Parallel()
 
-		body, err := marshalJSON("Ссылка на группу добавлена")
+		body, err := marshalJSON("url_added")
 		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.URLAdded(userID)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.URLAdded(telegramUser)
 	})
 }
 
@@ -63,10 +98,10 @@ func TestRemovedGroup(t *testing.T) {
 	t.Run("send message to user", func(t *testing.T) {
 		t.Parallel()
 
-		body, err := marshalJSON("Ссылка на группу удалена")
+		body, err := marshalJSON("group_removed")
 		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.RemovedGroup(userID)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.RemovedGroup(telegramUser)
 	})
 }
 
@@ -78,72 +113,349 @@ func TestStartDateUpdated(t *testing.T) {
 	t.Run("send message to user", func(t *testing.T) {
 		t.Parallel()
 
-		body, err := marshalJSON("Дата начала проверки обновлена")
+		body, err := marshalJSON("start_date_updated")
 		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.StartDateUpdated(userID)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.StartDateUpdated(telegramUser)
 	})
 }
 
-func TestGroupList(t *testing.T) {
+// keyboardRequest - sendMessage or editMessageText params with inline keyboard.
+type keyboardRequest struct {
+	ChatID      uint64                         `json:"chat_id"`
+	MessageID   uint64                         `json:"message_id"`
+	Text        string                         `json:"text"`
+	ReplyMarkup *entity.TelegramInlineKeyboard `json:"reply_markup"`
+}
+
+func expectKeyboard(t *testing.T, client *mocks.MockInterfaceClient, method string) *keyboardRequest {
+	t.Helper()
+
+	var request keyboardRequest
+
+	client.EXPECT().Post(testBaseURL+token+"/"+method, gomock.Any()).
+		DoAndReturn(func(_ string, body []byte) (*http.Response, error) {
+			require.ErrorIs(t, json.Unmarshal(body, &request), nil)
+
+			return okResponse(), nil
+		}).Times(1)
+
+	return &request
+}
+
+func inlineButton(text, data string) entity.TelegramInlineButton {
+	return entity.TelegramInlineButton{Text: text, CallbackData: data}
+}
+
+func buttonsData(keyboard *entity.TelegramInlineKeyboard) (data []string) {
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			data = append(data, button.CallbackData)
+		}
+	}
+
+	return
+}
+
+func TestSubscriptionList(t *testing.T) {
 	t.Parallel()
 
-	serviceMessenger, client := messenger(t)
+	mutedUntil := time.Date(2031, 11, 15, 10, 30, 0, 0, time.UTC)
+	subscriptions := []entity.Subscription{
+		{ID: 1, Feed: entity.Feed{Name: "1"}},
+		{ID: 2, Paused: true, Feed: entity.Feed{Name: "2"}},
+		{ID: 3, MutedUntil: &mutedUntil, Feed: entity.Feed{Name: "3"}, Filters: entity.Filters{
+			{Pattern: "go"},
+			{Exclude: true, Pattern: "ad"},
+		}},
+	}
+
+	t.Run("send list with keyboard", func(t *testing.T) {
+		t.Parallel()
 
-	t.Run("send message to user", func(t *testing.T) {
+		serviceMessenger, client := messenger(t)
+		request := expectKeyboard(t, client, "sendMessage")
+		serviceMessenger.SubscriptionList(telegramUser, subscriptions, 0)
+
+		require.Equal(t, uint64(userID), request.ChatID)
+		require.Equal(t, "<b>list.header(3)</b>\n1. <code>1</code>\n2. <code>2</code><i> list.paused</i>"+
+			"\n3. <code>3</code><i> list.muted(15.11 10:30)</i>\n    <i>list.filters(+go -ad)</i>", request.Text)
+		require.Equal(t, [][]entity.TelegramInlineButton{
+			{
+				inlineButton("list.remove(1)", "r:1:0"),
+				inlineButton("list.pause(1)", "p:1:0"),
+				inlineButton("list.mute(1)", "m:1:0"),
+			},
+			{
+				inlineButton("list.remove(2)", "r:2:0"),
+				inlineButton("list.resume(2)", "p:2:0"),
+				inlineButton("list.mute(2)", "m:2:0"),
+			},
+			{
+				inlineButton("list.remove(3)", "r:3:0"),
+				inlineButton("list.pause(3)", "p:3:0"),
+				inlineButton("list.unmute(3)", "m:3:0"),
+			},
+		}, request.ReplyMarkup.InlineKeyboard)
+	})
+
+	t.Run("muted until in timezone of user", func(t *testing.T) {
 		t.Parallel()
 
-		groups := []string{"1", "2"}
-		list := strings.Join(groups, "\n")
-		body, err := marshalJSON("Список групп:\n" + list)
-		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.GroupList(userID, groups)
+		serviceMessenger, client := messenger(t)
+		request := expectKeyboard(t, client, "sendMessage")
+		serviceMessenger.SubscriptionList(&entity.User{TelegramID: userID, Timezone: "Europe/Moscow"},
+			subscriptions[2:], 0)
+
+		require.Equal(t, "<b>list.header(1)</b>\n1. <code>3</code><i> list.muted(15.11 13:30)</i>"+
+			"\n    <i>list.filters(+go -ad)</i>", request.Text)
+	})
+
+	t.Run("unavailable group", func(t *testing.T) {
+		t.Parallel()
+
+		brokenAt := time.Date(2021, 12, 10, 10, 0, 0, 0, time.UTC)
+		serviceMessenger, client := messenger(t)
+		request := expectKeyboard(t, client, "sendMessage")
+		serviceMessenger.SubscriptionList(telegramUser, []entity.Subscription{
+			{ID: 1, Paused: true, BrokenAt: &brokenAt, Feed: entity.Feed{Name: "1"}},
+		}, 0)
+
+		require.Equal(t, "<b>list.header(1)</b>\n1. <code>1</code><i> list.unavailable</i>", request.Text)
+	})
+
+	t.Run("send empty list", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		request := expectKeyboard(t, client, "sendMessage")
+		serviceMessenger.SubscriptionList(telegramUser, nil, 0)
+
+		require.Equal(t, "list.empty", request.Text)
+		require.Nil(t, request.ReplyMarkup)
 	})
 }
 
-func TestIncorrectFormat(t *testing.T) {
+func TestEditSubscriptionList(t *testing.T) {
 	t.Parallel()
 
-	serviceMessenger, client := messenger(t)
+	subscriptions := make([]entity.Subscription, 12)
+	for i := range subscriptions {
+		subscriptions[i] = entity.Subscription{ID: math.MaxUint64 - uint64(i), Feed: entity.Feed{Name: "group"}}
+	}
 
-	t.Run("send message to user add_url", func(t *testing.T) {
+	t.Run("middle page", func(t *testing.T) {
 		t.Parallel()
 
-		body, err := marshalJSON("Правильный формат: /add_url ссылка на группу")
-		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.IncorrectFormat(userID, "/add_url")
+		serviceMessenger, client := messenger(t)
+		request := expectKeyboard(t, client, "editMessageText")
+		serviceMessenger.EditSubscriptionList(telegramUser, 10, subscriptions, 1)
+
+		require.Equal(t, uint64(10), request.MessageID)
+		require.Contains(t, request.Text, "\n6. <code>group</code>")
+		require.Contains(t, request.Text, "list.page(2, 3)")
+		require.NotContains(t, request.Text, "\n11. ")
+
+		keyboard := request.ReplyMarkup.InlineKeyboard
+		require.Len(t, keyboard, 6)
+		require.Equal(t, []entity.TelegramInlineButton{
+			inlineButton("list.prev", "l:0:0"),
+			inlineButton("list.next", "l:0:2"),
+		}, keyboard[5])
+
+		for _, data := range buttonsData(request.ReplyMarkup) {
+			require.LessOrEqual(t, len(data), entity.CallbackDataLimit)
+		}
 	})
 
-	t.Run("send message to user del_group", func(t *testing.T) {
+	t.Run("page after removing the last subscription of it", func(t *testing.T) {
 		t.Parallel()
 
-		body, err := marshalJSON("Правильный формат: /del_group ссылка на группу")
-		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.IncorrectFormat(userID, "/del_group")
+		serviceMessenger, client := messenger(t)
+		request := expectKeyboard(t, client, "editMessageText")
+		serviceMessenger.EditSubscriptionList(telegramUser, 10, subscriptions[:10], 2)
+
+		require.Contains(t, request.Text, "list.page(2, 2)")
+		require.Equal(t, []entity.TelegramInlineButton{inlineButton("list.prev", "l:0:0")},
+			request.ReplyMarkup.InlineKeyboard[5])
 	})
+}
+
+func TestSubscriptionUpdated(t *testing.T) {
+	t.Parallel()
+
+	mutedUntil := time.Now().Add(24 * time.Hour)
+	answers := map[string]struct {
+		action       string
+		subscription entity.Subscription
+		text         string
+	}{
+		"remove": {entity.CallbackRemove, entity.Subscription{}, "callback.removed"},
+		"pause":  {entity.CallbackPause, entity.Subscription{Paused: true}, "callback.paused"},
+		"resume": {entity.CallbackPause, entity.Subscription{}, "callback.resumed"},
+		"mute":   {entity.CallbackMute, entity.Subscription{MutedUntil: &mutedUntil}, "callback.muted(24)"},
+		"unmute": {entity.CallbackMute, entity.Subscription{}, "callback.unmuted"},
+		"page":   {entity.CallbackList, entity.Subscription{}, ""},
+	}
+
+	for name, answer := range answers {
+		answer := answer
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			serviceMessenger, client := messenger(t)
+			body, err := json.Marshal(map[string]string{"callback_query_id": "query", "text": answer.text})
+			require.ErrorIs(t, err, nil)
+
+			if answer.text == "" {
+				body = []byte(`{"callback_query_id":"query"}`)
+			}
+
+			client.EXPECT().Post(testBaseURL+token+"/answerCallbackQuery", body).Return(okResponse(), nil).Times(1)
+			serviceMessenger.SubscriptionUpdated(telegramUser, "query", answer.action, &answer.subscription)
+		})
+	}
+}
+
+func TestCallbackFailed(t *testing.T) {
+	t.Parallel()
+
+	serviceMessenger, client := messenger(t)
+	body, err := json.Marshal(map[string]string{"callback_query_id": "query", "text": "callback.failed"})
+	require.ErrorIs(t, err, nil)
+
+	client.EXPECT().Post(testBaseURL+token+"/answerCallbackQuery", body).Return(okResponse(), nil).Times(1)
+	serviceMessenger.CallbackFailed(telegramUser, "query")
+}
+
+func TestSubscriptionState(t *testing.T) {
+	t.Parallel()
+
+	mutedUntil := time.Date(2021, 12, 6, 10, 30, 0, 0, time.UTC)
+	subscription := entity.Subscription{Feed: entity.Feed{Name: "group"}, MutedUntil: &mutedUntil}
+	withInterval := subscription
+	withInterval.CheckInterval = 90 * 60
+	moscowUser := &entity.User{TelegramID: userID, Timezone: "Europe/Moscow"}
+	messages := map[string]struct {
+		send func(*service.Messenger)
+		text string
+	}{
+		"paused": {func(m *service.Messenger) { m.SubscriptionPaused(telegramUser, &subscription) }, "subscription.paused"},
+		"resumed": {func(m *service.Messenger) { m.SubscriptionResumed(telegramUser, &subscription, 0, true) },
+			"subscription.resumed"},
+		"caught up": {func(m *service.Messenger) { m.SubscriptionResumed(telegramUser, &subscription, 2, true) },
+			"subscription.caught_up(2)"},
+		"skipped": {func(m *service.Messenger) { m.SubscriptionResumed(telegramUser, &subscription, 2, false) },
+			"subscription.skipped(2)"},
+		"muted": {func(m *service.Messenger) { m.SubscriptionMuted(telegramUser, &subscription) },
+			"subscription.muted(06.12 10:30)"},
+		"muted in timezone of user": {func(m *service.Messenger) { m.SubscriptionMuted(moscowUser, &subscription) },
+			"subscription.muted(06.12 13:30)"},
+		"auto interval": {func(m *service.Messenger) { m.CheckIntervalUpdated(telegramUser, &subscription) },
+			"interval.auto"},
+		"interval": {func(m *service.Messenger) { m.CheckIntervalUpdated(telegramUser, &withInterval) },
+			"interval.updated(90m)"},
+	}
+
+	for name, message := range messages {
+		message := message
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			serviceMessenger, client := messenger(t)
+			body, err := marshalJSON(message.text + "<code>group</code>")
+			require.ErrorIs(t, err, nil)
+			client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+			message.send(serviceMessenger)
+		})
+	}
+}
 
-	t.Run("send message to user start_date", func(t *testing.T) {
+func TestIncorrectFormat(t *testing.T) {
+	t.Parallel()
+
+	serviceMessenger, client := messenger(t)
+
+	body, err := marshalJSON("incorrect_format<code>/add_url command.add_url.args</code>")
+	require.ErrorIs(t, err, nil)
+	client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+	serviceMessenger.IncorrectFormat(telegramUser, entity.BotCommand{
+		Command:     "add_url",
+		Description: "command.add_url",
+		Args:        "command.add_url.args",
+	})
+}
+
+func TestUnknownCommand(t *testing.T) {
+	t.Parallel()
+
+	serviceMessenger, client := messenger(t)
+
+	body, err := marshalJSON("unknown_command")
+	require.ErrorIs(t, err, nil)
+	client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+	serviceMessenger.UnknownCommand(telegramUser)
+}
+
+func TestHelp(t *testing.T) {
+	t.Parallel()
+
+	commands := []entity.BotCommand{
+		{Command: "help", Description: "command.help", Args: "command.help.args"},
+		{Command: "list", Description: "command.list", Aliases: []string{"/groups"}},
+	}
+	help := "<b>help.header</b>\n<code>/help command.help.args</code> — command.help" +
+		"\n<code>/list</code> — command.list<i>help.aliases(/groups)</i>"
+
+	t.Run("help", func(t *testing.T) {
 		t.Parallel()
 
-		body, err := marshalJSON("Правильный формат: /start_date dd.mm.yyyy")
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON(help)
 		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.IncorrectFormat(userID, "/start_date")
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.Help(telegramUser, commands)
 	})
 
-	t.Run("send message to user unknown", func(t *testing.T) {
+	t.Run("welcome", func(t *testing.T) {
 		t.Parallel()
 
-		body, err := marshalJSON("Неизвестная команда")
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("welcome\n\n" + help)
 		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.IncorrectFormat(userID, "unknown")
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.Welcome(telegramUser, commands)
 	})
 }
 
+func TestSetCommands(t *testing.T) {
+	t.Parallel()
+
+	serviceMessenger, client := messenger(t)
+	commands := []entity.BotCommand{{Command: "list", Description: "command.list", Aliases: []string{"/groups"}}}
+
+	body := `{"commands":[{"command":"list","description":"command.list"}],"language_code":"en"}`
+	client.EXPECT().Post(testBaseURL+token+"/setMyCommands", []byte(body)).Return(okResponse(), nil).Times(1)
+
+	err := serviceMessenger.SetCommands(commands, "en")
+	require.ErrorIs(t, err, nil)
+}
+
+func TestBotUsername(t *testing.T) {
+	t.Parallel()
+
+	serviceMessenger, client := messenger(t)
+	res := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+		`{"ok":true,"result":{"id":1,"is_bot":true,"username":"NewsBot"}}`))}
+	client.EXPECT().Post(testBaseURL+token+"/getMe", []byte(`{}`)).Return(res, nil).Times(1)
+
+	username, err := serviceMessenger.BotUsername()
+	require.ErrorIs(t, err, nil)
+	require.Equal(t, "NewsBot", username)
+}
+
 func TestUnknownSource(t *testing.T) {
 	t.Parallel()
 
@@ -153,10 +465,10 @@ func TestUnknownSource(t *testing.T) {
 		t.Parallel()
 
 		urlStr := "http://unknown.url"
-		body, err := marshalJSON("Неизвестный источник: " + urlStr)
+		body, err := marshalJSON("unknown_source<code>" + urlStr + "</code>")
 		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.UnknownSource(userID, urlStr)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.UnknownSource(telegramUser, urlStr)
 	})
 }
 
@@ -169,9 +481,337 @@ func TestUnknownError(t *testing.T) {
 		t.Parallel()
 
 		errMessage := "some error"
-		body, err := marshalJSON("Неизвестная ошибка: " + errMessage)
+		body, err := marshalJSON("unknown_error<code>" + errMessage + "</code>")
 		require.ErrorIs(t, err, nil)
-		client.EXPECT().Post(url, body).Times(1)
-		serviceMessenger.UnknownError(userID, errMessage)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.UnknownError(telegramUser, errMessage)
+	})
+}
+
+func TestFilters(t *testing.T) {
+	t.Parallel()
+
+	t.Run("updated", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("filters.updated<code>+go -/a&lt;b/</code>")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.FiltersUpdated(telegramUser,
+			entity.Filters{{Pattern: "go"}, {Exclude: true, Regexp: true, Pattern: "a<b"}})
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("filters.cleared")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.FiltersUpdated(telegramUser, entity.Filters{})
+	})
+
+	t.Run("incorrect", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("filters.incorrect<code>/(/</code>")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.IncorrectFilter(telegramUser, "/(/")
+	})
+
+	t.Run("not subscribed", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("not_subscribed<code>https://vk.com/group</code>")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.NotSubscribed(telegramUser, "https://vk.com/group")
+	})
+}
+
+func TestLanguage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("updated", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("lang.updated")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.LanguageUpdated(telegramUser)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("lang.current(lang.auto)" +
+			"<code>/lang en</code>, <code>/lang ru</code>, <code>/lang auto</code>")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.LanguageList(telegramUser, "", []string{"en", "ru"})
+	})
+
+	t.Run("reply in language of user", func(t *testing.T) {
+		t.Parallel()
+
+		mockCtl := gomock.NewController(t)
+		client := mocks.NewMockInterfaceClient(mockCtl)
+		userRepo := mocks.NewMockUserRepo(mockCtl)
+		translator, err := locales.New()
+		require.ErrorIs(t, err, nil)
+
+		serviceMessenger := service.NewMessenger(token, testBaseURL, client, mocks.NewMockSource(mockCtl), userRepo,
+			markup.HTML, translator, unlimited(), mocks.NewMockInterfaceLogger(mockCtl))
+
+		body, err := marshalJSON("Group link added")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.URLAdded(&entity.User{TelegramID: userID, LanguageCode: "en-GB"})
+	})
+}
+
+func TestDigest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("updated", func(t *testing.T) {
+		t.Parallel()
+
+		next := time.Date(2021, 12, 5, 5, 0, 0, 0, time.UTC)
+		user := entity.User{TelegramID: userID, DigestMode: entity.DigestDaily, DigestAt: "08:00", Timezone: "Europe/Moscow",
+			NextDigestAt: &next}
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("digest.updated(digest.daily(08:00, Europe/Moscow))\ndigest.next(05.12 08:00)")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.DigestUpdated(&user)
+	})
+
+	t.Run("settings", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("digest.current(digest.instant)<code>/digest instant</code>, " +
+			"<code>/digest hourly</code>, <code>/digest daily 08:00 Europe/Moscow</code>")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.DigestSettings(&entity.User{TelegramID: userID, DigestMode: entity.DigestInstant})
+	})
+
+	t.Run("incorrect timezone", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("incorrect_timezone<code>Mars/Olympus</code>")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.IncorrectTimezone(telegramUser, "Mars/Olympus")
+	})
+}
+
+func TestQuiet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("updated", func(t *testing.T) {
+		t.Parallel()
+
+		user := entity.User{TelegramID: userID, QuietFrom: "23:00", QuietTo: "08:00", QuietMode: entity.QuietSilent,
+			Timezone: "UTC"}
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("quiet.updated(quiet.silent(23:00, 08:00, UTC))")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.QuietUpdated(&user)
+	})
+
+	t.Run("settings", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client := messenger(t)
+		body, err := marshalJSON("quiet.current(quiet.off)<code>/quiet 23:00-08:00</code>, " +
+			"<code>/quiet 23:00-08:00 silent Europe/Moscow</code>, <code>/quiet off</code>")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		serviceMessenger.QuietSettings(&entity.User{TelegramID: userID, QuietMode: entity.QuietHold})
+	})
+}
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+
+	serviceMessenger, client := messenger(t)
+
+	t.Run("sent", func(t *testing.T) {
+		t.Parallel()
+
+		body, err := marshalJSON("sent")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		require.ErrorIs(t, serviceMessenger.Send(userID, "sent", "HTML", false), nil)
+	})
+
+	t.Run("with parse mode", func(t *testing.T) {
+		t.Parallel()
+
+		body := []byte(`{"chat_id":1,"text":"\u003cb\u003ebold\u003c/b\u003e","parse_mode":"HTML"}`)
+		client.EXPECT().Post(url, body).Return(okResponse(), nil).Times(1)
+		require.ErrorIs(t, serviceMessenger.Send(userID, "<b>bold</b>", "HTML", false), nil)
+	})
+
+	t.Run("too many requests", func(t *testing.T) {
+		t.Parallel()
+
+		body, err := marshalJSON("flood")
+		require.ErrorIs(t, err, nil)
+		client.EXPECT().Post(url, body).Return(&http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Body: io.NopCloser(strings.NewReader(
+				`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`)),
+		}, nil).Times(1)
+
+		retryAfter, ok := errors.TelegramRetryAfter(serviceMessenger.Send(userID, "flood", "HTML", false))
+		require.True(t, ok)
+		require.Equal(t, 7*time.Second, retryAfter)
+	})
+}
+
+func TestSendMedia(t *testing.T) {
+	t.Parallel()
+
+	serviceMessenger, client := messenger(t)
+
+	t.Run("photo with caption", func(t *testing.T) {
+		t.Parallel()
+
+		body := []byte(`{"caption":"text","chat_id":1,"parse_mode":"HTML","photo":"https://example.com/1.jpg"}`)
+		client.EXPECT().Post(testBaseURL+token+"/sendPhoto", body).Return(okResponse(), nil).Times(1)
+
+		media := []entity.Media{{Type: entity.AttachmentPhoto, URL: "https://example.com/1.jpg"}}
+		require.ErrorIs(t, serviceMessenger.SendMedia(userID, media, "text", "HTML", false), nil)
+	})
+
+	t.Run("document without caption", func(t *testing.T) {
+		t.Parallel()
+
+		body := []byte(`{"chat_id":1,"document":"https://example.com/1.pdf"}`)
+		client.EXPECT().Post(testBaseURL+token+"/sendDocument", body).Return(okResponse(), nil).Times(1)
+
+		media := []entity.Media{{Type: entity.AttachmentDoc, URL: "https://example.com/1.pdf"}}
+		require.ErrorIs(t, serviceMessenger.SendMedia(userID, media, "", "HTML", false), nil)
+	})
+
+	t.Run("album", func(t *testing.T) {
+		t.Parallel()
+
+		body := []byte(`{"chat_id":1,"media":[` +
+			`{"type":"photo","media":"https://example.com/2.jpg","caption":"album","parse_mode":"HTML"},` +
+			`{"type":"video","media":"https://example.com/2.mp4"}]}`)
+		client.EXPECT().Post(testBaseURL+token+"/sendMediaGroup", body).Return(okResponse(), nil).Times(1)
+
+		media := []entity.Media{
+			{Type: entity.AttachmentPhoto, URL: "https://example.com/2.jpg"},
+			{Type: entity.AttachmentVideo, URL: "https://example.com/2.mp4"},
+		}
+		require.ErrorIs(t, serviceMessenger.SendMedia(userID, media, "album", "HTML", false), nil)
+	})
+
+	t.Run("silent photo", func(t *testing.T) {
+		t.Parallel()
+
+		body := []byte(`{"chat_id":1,"disable_notification":true,"photo":"https://example.com/3.jpg"}`)
+		client.EXPECT().Post(testBaseURL+token+"/sendPhoto", body).Return(okResponse(), nil).Times(1)
+
+		media := []entity.Media{{Type: entity.AttachmentPhoto, URL: "https://example.com/3.jpg"}}
+		require.ErrorIs(t, serviceMessenger.SendMedia(userID, media, "", "HTML", true), nil)
+	})
+
+	t.Run("silent album", func(t *testing.T) {
+		t.Parallel()
+
+		body := []byte(`{"chat_id":1,"media":[` +
+			`{"type":"photo","media":"https://example.com/4.jpg"},` +
+			`{"type":"photo","media":"https://example.com/5.jpg"}],"disable_notification":true}`)
+		client.EXPECT().Post(testBaseURL+token+"/sendMediaGroup", body).Return(okResponse(), nil).Times(1)
+
+		media := []entity.Media{
+			{Type: entity.AttachmentPhoto, URL: "https://example.com/4.jpg"},
+			{Type: entity.AttachmentPhoto, URL: "https://example.com/5.jpg"},
+		}
+		require.ErrorIs(t, serviceMessenger.SendMedia(userID, media, "", "HTML", true), nil)
+	})
+}
+
+func TestSend_blocked(t *testing.T) {
+	t.Parallel()
+
+	forbidden := func(description string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body: io.NopCloser(strings.NewReader(
+				`{"ok":false,"error_code":403,"description":"Forbidden: ` + description + `"}`)),
+		}
+	}
+
+	t.Run("bot was blocked", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client, userRepo := messengerWithRepo(t)
+
+		client.EXPECT().Post(url, gomock.Any()).Return(forbidden("bot was blocked by the user"), nil).Times(1)
+		userRepo.EXPECT().SetActive(uint64(userID), false).Return(nil).Times(1)
+
+		err := serviceMessenger.Send(userID, "blocked", "", false)
+		require.True(t, errors.IsTelegramBlocked(err))
+		require.True(t, errors.IsTelegramPermanent(err))
+	})
+
+	t.Run("user is deactivated", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client, userRepo := messengerWithRepo(t)
+
+		client.EXPECT().Post(url, gomock.Any()).Return(forbidden("user is deactivated"), nil).Times(1)
+		userRepo.EXPECT().SetActive(uint64(userID), false).Return(nil).Times(1)
+
+		require.True(t, errors.IsTelegramBlocked(serviceMessenger.Send(userID, "deactivated", "", false)))
+	})
+
+	t.Run("other forbidden", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, client, _ := messengerWithRepo(t)
+
+		client.EXPECT().Post(url, gomock.Any()).Return(forbidden("bot can't send messages to bots"), nil).Times(1)
+
+		err := serviceMessenger.Send(userID, "bot", "", false)
+		require.False(t, errors.IsTelegramBlocked(err))
+		require.True(t, errors.IsTelegramPermanent(err))
+	})
+}
+
+func TestSendMessage_with_error(t *testing.T) {
+	t.Parallel()
+
+	mockCtl := gomock.NewController(t)
+	client := mocks.NewMockInterfaceClient(mockCtl)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+	userRepo := mocks.NewMockUserRepo(mockCtl)
+	logger.EXPECT().Debug(gomock.Any()).AnyTimes()
+	serviceMessenger := service.NewMessenger(token, testBaseURL, client, mocks.NewMockSource(mockCtl),
+		userRepo, markup.HTML, i18n.Keys{}, unlimited(), logger)
+
+	t.Run("error is logged", func(t *testing.T) {
+		t.Parallel()
+
+		client.EXPECT().Post(url, gomock.Any()).Return(nil, io.ErrUnexpectedEOF).Times(1)
+		logger.EXPECT().Error(gomock.Any()).Times(1)
+		serviceMessenger.URLAdded(telegramUser)
 	})
 }
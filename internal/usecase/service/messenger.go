@@ -2,94 +2,572 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/jokius/news-telegram-bot/internal/entity"
 	"github.com/jokius/news-telegram-bot/internal/usecase"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
 	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
 	"github.com/jokius/news-telegram-bot/pkg/logger"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+	"github.com/jokius/news-telegram-bot/pkg/ratelimit"
 )
 
-// Messenger - messenger to telegram.
+// Messenger - messenger to telegram, replies are translated to language of the user.
+// Every chat message request waits for telegram rate limits.
 type Messenger struct {
-	baseURL string
-	token   string
-	client  httpclient.InterfaceClient
-	source  usecase.Source
-	logger  logger.InterfaceLogger
+	baseURL    string
+	token      string
+	client     httpclient.InterfaceClient
+	source     usecase.Source
+	userRepo   usecase.UserRepo
+	renderer   markup.Renderer
+	translator i18n.Translator
+	limiter    *ratelimit.Limiter
+	logger     logger.InterfaceLogger
 }
 
 func NewMessenger(token, baseURL string,
 	client httpclient.InterfaceClient,
 	source usecase.Source,
+	userRepo usecase.UserRepo,
+	renderer markup.Renderer,
+	translator i18n.Translator,
+	limiter *ratelimit.Limiter,
 	l logger.InterfaceLogger) *Messenger {
 	if lastCh := baseURL[len(baseURL)-1:]; lastCh != "/" {
 		baseURL += "/"
 	}
 
-	return &Messenger{baseURL, token, client, source, l}
+	return &Messenger{baseURL, token, client, source, userRepo, renderer, translator, limiter, l}
 }
 
-func (m *Messenger) URLAdded(id uint64) {
-	m.sendMessage(id, "Ссылка на группу добавлена")
+func (m *Messenger) URLAdded(user *entity.User) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("url_added")))
 }
 
-func (m *Messenger) RemovedGroup(id uint64) {
-	m.sendMessage(id, "Ссылка на группу удалена")
+func (m *Messenger) RemovedGroup(user *entity.User) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("group_removed")))
 }
 
-func (m *Messenger) StartDateUpdated(id uint64) {
-	m.sendMessage(id, "Дата начала проверки обновлена")
+func (m *Messenger) StartDateUpdated(user *entity.User) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("start_date_updated")))
 }
 
-func (m *Messenger) GroupList(id uint64, groups []string) {
-	list := strings.Join(groups, "\n")
-	m.sendMessage(id, "Список групп:\n"+list)
+// SubscriptionList - page of subscriptions with buttons to manage them.
+func (m *Messenger) SubscriptionList(user *entity.User, subscriptions []entity.Subscription, page int) {
+	parts, keyboard := subscriptionList(m.localizer(user), subscriptions, page, time.Now().In(user.Location()))
+
+	m.sendKeyboard("sendMessage", keyboardMessage{
+		ChatID:      user.TelegramID,
+		Text:        m.renderer.Render(parts),
+		ParseMode:   m.renderer.ParseMode(),
+		ReplyMarkup: keyboard,
+	})
+}
+
+// EditSubscriptionList - replace list message after button press, keyboard is removed from empty list.
+func (m *Messenger) EditSubscriptionList(user *entity.User, messageID uint64, subscriptions []entity.Subscription,
+	page int) {
+	parts, keyboard := subscriptionList(m.localizer(user), subscriptions, page, time.Now().In(user.Location()))
+
+	m.sendKeyboard("editMessageText", keyboardMessage{
+		ChatID:      user.TelegramID,
+		MessageID:   messageID,
+		Text:        m.renderer.Render(parts),
+		ParseMode:   m.renderer.ParseMode(),
+		ReplyMarkup: keyboard,
+	})
 }
 
-func (m *Messenger) IncorrectFormat(id uint64, command string) {
-	var text string
+// SubscriptionUpdated - answer to button press, telegram shows it as notification.
+func (m *Messenger) SubscriptionUpdated(user *entity.User, callbackID, action string,
+	subscription *entity.Subscription) {
+	var (
+		text string
+		l    = m.localizer(user)
+	)
+
+	switch {
+	case action == entity.CallbackRemove:
+		text = l.t("callback.removed")
+	case action == entity.CallbackPause && subscription.Paused:
+		text = l.t("callback.paused")
+	case action == entity.CallbackPause:
+		text = l.t("callback.resumed")
+	case action == entity.CallbackMute && subscription.MutedUntil != nil:
+		hours := int(time.Until(*subscription.MutedUntil).Round(time.Hour) / time.Hour)
+		text = l.plural("callback.muted", hours)
+	case action == entity.CallbackMute:
+		text = l.t("callback.unmuted")
+	}
 
-	switch command {
-	case "/add_url":
-		text = "Правильный формат: /add_url ссылка на группу"
-	case "/del_group":
-		text = "Правильный формат: /del_group ссылка на группу"
-	case "/start_date":
-		text = "Правильный формат: /start_date dd.mm.yyyy"
+	m.answerCallback(user, callbackID, text)
+}
+
+func (m *Messenger) SubscriptionNotFound(user *entity.User, callbackID string) {
+	m.answerCallback(user, callbackID, m.localizer(user).t("callback.not_found"))
+}
+
+// CallbackFailed - answer to button press which failed, details are sent with UnknownError.
+func (m *Messenger) CallbackFailed(user *entity.User, callbackID string) {
+	m.answerCallback(user, callbackID, m.localizer(user).t("callback.failed"))
+}
+
+func (m *Messenger) SubscriptionPaused(user *entity.User, subscription *entity.Subscription) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("subscription.paused")), markup.CodeText(subscription.Feed.Name))
+}
+
+// SubscriptionResumed - resumed subscription with number of missed posts which are sent or skipped.
+func (m *Messenger) SubscriptionResumed(user *entity.User, subscription *entity.Subscription, missed int64,
+	catchUp bool) {
+	var (
+		text string
+		l    = m.localizer(user)
+	)
+
+	switch {
+	case missed == 0:
+		text = l.t("subscription.resumed")
+	case catchUp:
+		text = l.plural("subscription.caught_up", int(missed))
 	default:
-		text = "Неизвестная команда"
+		text = l.plural("subscription.skipped", int(missed))
+	}
+
+	m.sendMessage(user, markup.Text(text), markup.CodeText(subscription.Feed.Name))
+}
+
+func (m *Messenger) SubscriptionMuted(user *entity.User, subscription *entity.Subscription) {
+	mutedUntil := subscription.MutedUntil.In(user.Location()).Format("02.01 15:04")
+	text := m.localizer(user).t("subscription.muted", mutedUntil)
+
+	m.sendMessage(user, markup.Text(text), markup.CodeText(subscription.Feed.Name))
+}
+
+func (m *Messenger) CheckIntervalUpdated(user *entity.User, subscription *entity.Subscription) {
+	l := m.localizer(user)
+
+	text := l.t("interval.auto")
+	if subscription.CheckInterval > 0 {
+		text = l.t("interval.updated", shortDuration(time.Duration(subscription.CheckInterval)*time.Second))
+	}
+
+	m.sendMessage(user, markup.Text(text), markup.CodeText(subscription.Feed.Name))
+}
+
+func (m *Messenger) Welcome(user *entity.User, commands []entity.BotCommand) {
+	l := m.localizer(user)
+	parts := []markup.Part{markup.Text(l.t("welcome") + "\n\n")}
+
+	m.sendMessage(user, append(parts, helpParts(l, commands)...)...)
+}
+
+func (m *Messenger) Help(user *entity.User, commands []entity.BotCommand) {
+	m.sendMessage(user, helpParts(m.localizer(user), commands)...)
+}
+
+// helpParts - usage and description of every command.
+func helpParts(l localizer, commands []entity.BotCommand) []markup.Part {
+	parts := []markup.Part{markup.BoldText(l.t("help.header"))}
+
+	for _, command := range commands {
+		parts = append(parts,
+			markup.Text("\n"),
+			markup.CodeText(usage(l, command)),
+			markup.Text(" — "+l.t(command.Description)),
+		)
+
+		if len(command.Aliases) > 0 {
+			parts = append(parts, markup.ItalicText(l.t("help.aliases", strings.Join(command.Aliases, ", "))))
+		}
+	}
+
+	return parts
+}
+
+func usage(l localizer, command entity.BotCommand) string {
+	if command.Args == "" {
+		return "/" + command.Command
+	}
+
+	return "/" + command.Command + " " + l.t(command.Args)
+}
+
+func (m *Messenger) UnknownCommand(user *entity.User) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("unknown_command")))
+}
+
+func (m *Messenger) IncorrectFormat(user *entity.User, command entity.BotCommand) {
+	l := m.localizer(user)
+
+	m.sendMessage(user, markup.Text(l.t("incorrect_format")), markup.CodeText(usage(l, command)))
+}
+
+func (m *Messenger) UnknownSource(user *entity.User, url string) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("unknown_source")), markup.CodeText(url))
+}
+
+func (m *Messenger) UnknownError(user *entity.User, text string) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("unknown_error")), markup.CodeText(text))
+}
+
+// FiltersUpdated - new filters of subscription, empty filters are removed ones.
+func (m *Messenger) FiltersUpdated(user *entity.User, filters entity.Filters) {
+	l := m.localizer(user)
+	if len(filters) == 0 {
+		m.sendMessage(user, markup.Text(l.t("filters.cleared")))
+
+		return
 	}
 
-	m.sendMessage(id, text)
+	m.sendMessage(user, markup.Text(l.t("filters.updated")), markup.CodeText(filters.String()))
 }
 
-func (m *Messenger) UnknownSource(id uint64, url string) {
-	m.sendMessage(id, "Неизвестный источник: "+url)
+func (m *Messenger) IncorrectFilter(user *entity.User, filter string) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("filters.incorrect")), markup.CodeText(filter))
 }
 
-func (m *Messenger) UnknownError(id uint64, text string) {
-	m.sendMessage(id, "Неизвестная ошибка: "+text)
+func (m *Messenger) NotSubscribed(user *entity.User, url string) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("not_subscribed")), markup.CodeText(url))
 }
 
-func (m *Messenger) Message(id uint64, text string) {
-	m.sendMessage(id, text)
+func (m *Messenger) LanguageUpdated(user *entity.User) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("lang.updated")))
 }
 
-func (m *Messenger) sendMessage(id uint64, message string) {
-	url := m.baseURL + m.token + "/sendMessage"
+// LanguageList - language chosen by /lang and commands to change it, empty current is telegram language.
+func (m *Messenger) LanguageList(user *entity.User, current string, languages []string) {
+	l := m.localizer(user)
+	if current == "" {
+		current = l.t("lang.auto")
+	}
+
+	parts := []markup.Part{markup.Text(l.t("lang.current", current))}
+	for i, language := range append(languages, "auto") {
+		if i > 0 {
+			parts = append(parts, markup.Text(", "))
+		}
+
+		parts = append(parts, markup.CodeText("/lang "+language))
+	}
+
+	m.sendMessage(user, parts...)
+}
+
+// DigestUpdated - new digest mode with time of the next digest in timezone of the user.
+func (m *Messenger) DigestUpdated(user *entity.User) {
+	l := m.localizer(user)
+	text := l.t("digest.updated", digestMode(l, user))
+
+	if user.NextDigestAt != nil && user.Digest() {
+		text += "\n" + l.t("digest.next", user.NextDigestAt.In(user.Location()).Format("02.01 15:04"))
+	}
+
+	m.sendMessage(user, markup.Text(text))
+}
+
+// DigestSettings - current digest mode and commands to change it.
+func (m *Messenger) DigestSettings(user *entity.User) {
+	l := m.localizer(user)
+	parts := []markup.Part{markup.Text(l.t("digest.current", digestMode(l, user)))}
+
+	for i, command := range []string{"/digest instant", "/digest hourly", "/digest daily 08:00 Europe/Moscow"} {
+		if i > 0 {
+			parts = append(parts, markup.Text(", "))
+		}
+
+		parts = append(parts, markup.CodeText(command))
+	}
+
+	m.sendMessage(user, parts...)
+}
+
+func digestMode(l localizer, user *entity.User) string {
+	switch user.DigestMode {
+	case entity.DigestHourly:
+		return l.t("digest.hourly")
+	case entity.DigestDaily:
+		return l.t("digest.daily", user.DigestAt, user.Timezone)
+	default:
+		return l.t("digest.instant")
+	}
+}
+
+func (m *Messenger) IncorrectTimezone(user *entity.User, timezone string) {
+	m.sendMessage(user, markup.Text(m.localizer(user).t("incorrect_timezone")), markup.CodeText(timezone))
+}
+
+// QuietUpdated - new quiet hours of the user.
+func (m *Messenger) QuietUpdated(user *entity.User) {
+	l := m.localizer(user)
+
+	m.sendMessage(user, markup.Text(l.t("quiet.updated", quietHours(l, user))))
+}
+
+// QuietSettings - current quiet hours and commands to change them.
+func (m *Messenger) QuietSettings(user *entity.User) {
+	l := m.localizer(user)
+	parts := []markup.Part{markup.Text(l.t("quiet.current", quietHours(l, user)))}
+
+	for i, command := range []string{"/quiet 23:00-08:00", "/quiet 23:00-08:00 silent Europe/Moscow", "/quiet off"} {
+		if i > 0 {
+			parts = append(parts, markup.Text(", "))
+		}
+
+		parts = append(parts, markup.CodeText(command))
+	}
+
+	m.sendMessage(user, parts...)
+}
+
+func quietHours(l localizer, user *entity.User) string {
+	switch {
+	case !user.Quiet():
+		return l.t("quiet.off")
+	case user.QuietMode == entity.QuietSilent:
+		return l.t("quiet.silent", user.QuietFrom, user.QuietTo, user.Timezone)
+	default:
+		return l.t("quiet.hold", user.QuietFrom, user.QuietTo, user.Timezone)
+	}
+}
+
+// Send - send message and return telegram error, for delivery with retries. Empty parseMode is plain text,
+// silent message comes without sound.
+func (m *Messenger) Send(id uint64, message, parseMode string, silent bool) error {
 	params := struct {
-		ChatID uint64 `json:"chat_id"`
-		Text   string `json:"text"`
-	}{id, message}
+		ChatID              uint64 `json:"chat_id"`
+		Text                string `json:"text"`
+		ParseMode           string `json:"parse_mode,omitempty"`
+		DisableNotification bool   `json:"disable_notification,omitempty"`
+	}{id, message, parseMode, silent}
+
+	return m.send(id, "sendMessage", params)
+}
+
+// keyboardMessage - sendMessage and editMessageText params.
+type keyboardMessage struct {
+	ChatID      uint64                         `json:"chat_id"`
+	MessageID   uint64                         `json:"message_id,omitempty"`
+	Text        string                         `json:"text"`
+	ParseMode   string                         `json:"parse_mode,omitempty"`
+	ReplyMarkup *entity.TelegramInlineKeyboard `json:"reply_markup,omitempty"`
+}
+
+func (m *Messenger) sendKeyboard(method string, message keyboardMessage) {
+	if err := m.send(message.ChatID, method, message); err != nil {
+		m.logger.Error(fmt.Errorf("`m.sendKeyboard` %s something wrong: %w", method, err))
+	}
+}
+
+// answerCallback - stop loading animation of the pressed button, text is optional notification.
+// It isn't a chat message and doesn't spend rate limit of the chat.
+func (m *Messenger) answerCallback(user *entity.User, callbackID, text string) {
+	params := struct {
+		CallbackQueryID string `json:"callback_query_id"`
+		Text            string `json:"text,omitempty"`
+	}{callbackID, text}
+
+	if err := m.call("answerCallbackQuery", params, nil); err != nil {
+		m.logger.Error(fmt.Errorf("`m.answerCallback` user %d something wrong: %w", user.TelegramID, err))
+	}
+}
+
+// SetCommands - command list of telegram menu, empty languageCode is for users without own list.
+// Called once on start, it isn't limited.
+func (m *Messenger) SetCommands(commands []entity.BotCommand, languageCode string) error {
+	l := localizer{m.translator, languageCode}
+
+	translated := make([]entity.BotCommand, len(commands))
+	for i, command := range commands {
+		translated[i] = entity.BotCommand{Command: command.Command, Description: l.t(command.Description)}
+	}
+
+	params := struct {
+		Commands     []entity.BotCommand `json:"commands"`
+		LanguageCode string              `json:"language_code,omitempty"`
+	}{translated, languageCode}
+
+	return m.call("setMyCommands", params, nil)
+}
+
+// BotUsername - username of the bot, commands in group chats are addressed to it by @username suffix.
+// Called once on start, it isn't limited.
+func (m *Messenger) BotUsername() (string, error) {
+	var bot entity.TelegramUser
+	if err := m.call("getMe", struct{}{}, &bot); err != nil {
+		return "", fmt.Errorf("`m.BotUsername` something wrong: %w", err)
+	}
+
+	return bot.Username, nil
+}
+
+func (m *Messenger) deactivate(id uint64) {
+	if err := m.userRepo.SetActive(id, false); err != nil {
+		m.logger.Error(fmt.Errorf("`m.deactivate` something wrong: %w", err))
+	}
+}
+
+var mediaMethods = map[string]string{
+	"photo":    "sendPhoto",
+	"video":    "sendVideo",
+	"document": "sendDocument",
+}
+
+// inputMedia - item of sendMediaGroup album.
+type inputMedia struct {
+	Type      string `json:"type"`
+	Media     string `json:"media"`
+	Caption   string `json:"caption,omitempty"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+// SendMedia - send one file with sendPhoto, sendVideo or sendDocument, or album of 2-10 files with sendMediaGroup.
+// Caption is added to the first file.
+func (m *Messenger) SendMedia(id uint64, media []entity.Media, caption, parseMode string, silent bool) error {
+	var (
+		method string
+		params interface{}
+	)
+
+	if len(media) == 1 {
+		inputType := mediaInputType(media[0].Type)
+		method = mediaMethods[inputType]
+		file := map[string]interface{}{"chat_id": id, inputType: media[0].URL}
+
+		if caption != "" {
+			file["caption"] = caption
+			file["parse_mode"] = parseMode
+		}
+
+		if silent {
+			file["disable_notification"] = true
+		}
 
+		params = file
+	} else {
+		items := make([]inputMedia, len(media))
+		for i := range media {
+			items[i] = inputMedia{Type: mediaInputType(media[i].Type), Media: media[i].URL}
+		}
+
+		if caption != "" {
+			items[0].Caption = caption
+			items[0].ParseMode = parseMode
+		}
+
+		method = "sendMediaGroup"
+		params = struct {
+			ChatID              uint64       `json:"chat_id"`
+			Media               []inputMedia `json:"media"`
+			DisableNotification bool         `json:"disable_notification,omitempty"`
+		}{id, items, silent}
+	}
+
+	return m.send(id, method, params)
+}
+
+// mediaInputType - telegram input media type, it is the name of the file param too.
+func mediaInputType(attachmentType string) string {
+	switch attachmentType {
+	case entity.AttachmentPhoto:
+		return "photo"
+	case entity.AttachmentVideo:
+		return "video"
+	default:
+		return "document"
+	}
+}
+
+// sendMessage - long message is split to a few ones.
+func (m *Messenger) sendMessage(user *entity.User, parts ...markup.Part) {
+	for _, text := range markup.Split(m.renderer, parts, markup.MessageLimit, markup.MessageLimit) {
+		if err := m.Send(user.TelegramID, text, m.renderer.ParseMode(), false); err != nil {
+			m.logger.Error(fmt.Errorf("`m.sendMessage` something wrong: %w", err))
+
+			return
+		}
+	}
+}
+
+// send - request with message to the chat, it waits for rate limits of telegram.
+func (m *Messenger) send(id uint64, method string, params interface{}) error {
+	if err := m.wait(id); err != nil {
+		return err
+	}
+
+	err := m.call(method, params, nil)
+	if errors.IsTelegramBlocked(err) {
+		m.deactivate(id)
+	}
+
+	return err
+}
+
+// wait - chats of the bot are private ones with users, so the telegram limit of group chats isn't applied.
+func (m *Messenger) wait(id uint64) error {
+	if depth := m.limiter.Depth(); depth > 0 {
+		m.logger.Debug(fmt.Sprintf("messenger queue depth: %d", depth))
+	}
+
+	return m.limiter.Wait(context.Background(), int64(id), false)
+}
+
+// QueueDepth - count of messages waiting for rate limits.
+func (m *Messenger) QueueDepth() int {
+	return m.limiter.Depth()
+}
+
+func (m *Messenger) call(method string, params, result interface{}) error {
 	body, err := json.Marshal(params)
 	if err != nil {
-		m.logger.Fatal(fmt.Errorf("`m.sendMessage` something wrong: %w", err))
+		return err
+	}
+
+	res, err := m.client.Post(m.baseURL+m.token+"/"+method, body)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	var response entity.TelegramResponse
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return fmt.Errorf("%s status %d: %w", method, res.StatusCode, err)
+	}
+
+	if !response.Ok {
+		retryAfter := time.Duration(response.Parameters.RetryAfter) * time.Second
+
+		return errors.NewTelegramError(response.ErrorCode, response.Description, retryAfter)
 	}
 
-	if _, err = m.client.Post(url, body); err != nil {
-		m.logger.Fatal(fmt.Errorf("`m.sendMessage` something wrong: %w", err))
+	if result == nil {
+		return nil
 	}
+
+	return json.Unmarshal(response.Result, result)
+}
+
+// shortDuration - duration in the largest whole unit like 15m, 2h, 1d or 1w.
+func shortDuration(duration time.Duration) string {
+	for _, unit := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"w", 7 * 24 * time.Hour},
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+	} {
+		if duration%unit.duration == 0 {
+			return fmt.Sprintf("%d%s", duration/unit.duration, unit.name)
+		}
+	}
+
+	return fmt.Sprintf("%dm", duration/time.Minute)
 }
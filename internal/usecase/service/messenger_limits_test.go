@@ -0,0 +1,234 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+	"github.com/jokius/news-telegram-bot/pkg/mocks"
+	"github.com/jokius/news-telegram-bot/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clockTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []clockTimer
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, clockTimer{c.now.Add(d), ch})
+
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	var timers []clockTimer
+
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			timers = append(timers, t)
+
+			continue
+		}
+
+		t.ch <- c.now
+	}
+
+	c.timers = timers
+}
+
+func (c *fakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+type telegramRequest struct {
+	method string
+	chatID uint64
+	text   string
+}
+
+// fakeTelegram - local bot api server, it records requests and answers ok.
+type fakeTelegram struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []telegramRequest
+}
+
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	t.Helper()
+
+	telegram := &fakeTelegram{}
+	telegram.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			ChatID uint64 `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		telegram.mu.Lock()
+		telegram.requests = append(telegram.requests, telegramRequest{path.Base(r.URL.Path), params.ChatID, params.Text})
+		telegram.mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(telegram.Close)
+
+	return telegram
+}
+
+func (s *fakeTelegram) Requests() []telegramRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]telegramRequest{}, s.requests...)
+}
+
+func (s *fakeTelegram) waitRequests(t *testing.T, count int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool { return len(s.Requests()) == count }, time.Second, time.Millisecond)
+}
+
+func limitedMessenger(t *testing.T) (*service.Messenger, *fakeTelegram, *fakeClock) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	logger := mocks.NewMockInterfaceLogger(mockCtl)
+	userRepo := mocks.NewMockUserRepo(mockCtl)
+	logger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	telegram := newFakeTelegram(t)
+	clock := &fakeClock{now: time.Date(2021, 11, 26, 12, 0, 0, 0, time.UTC)}
+	limiter := ratelimit.New(ratelimit.PerKey(1, time.Second), ratelimit.UseClock(clock))
+	newMessenger := service.NewMessenger(token, telegram.URL, httpclient.NewClient(), mocks.NewMockSource(mockCtl),
+		userRepo, markup.HTML, i18n.Keys{}, limiter, logger)
+
+	return newMessenger, telegram, clock
+}
+
+func waitClockTimers(t *testing.T, clock *fakeClock, count int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool { return clock.Timers() >= count }, time.Second, time.Millisecond)
+}
+
+func TestMessengerLimits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("every part of long message waits for chat limit", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, telegram, clock := limitedMessenger(t)
+
+		go serviceMessenger.UnknownError(telegramUser, strings.Repeat("error ", markup.MessageLimit/4))
+
+		waitClockTimers(t, clock, 1)
+		telegram.waitRequests(t, 1)
+
+		clock.Advance(time.Second)
+		telegram.waitRequests(t, 2)
+
+		for _, request := range telegram.Requests() {
+			assert.Equal(t, "sendMessage", request.method)
+			assert.Equal(t, uint64(userID), request.chatID)
+		}
+	})
+
+	t.Run("callback answer doesn't wait for chat limit", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, telegram, _ := limitedMessenger(t)
+
+		assert.ErrorIs(t, serviceMessenger.Send(userID, "text", "", false), nil)
+
+		done := make(chan struct{})
+
+		go func() {
+			serviceMessenger.SubscriptionNotFound(telegramUser, "callback")
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("callback answer waits for chat limit")
+		}
+
+		assert.Equal(t, "answerCallbackQuery", telegram.Requests()[1].method)
+	})
+
+	t.Run("queued messages are sent in order", func(t *testing.T) {
+		t.Parallel()
+
+		serviceMessenger, telegram, clock := limitedMessenger(t)
+
+		assert.ErrorIs(t, serviceMessenger.Send(userID, "first", "", false), nil)
+
+		for i, text := range []string{"second", "third"} {
+			go func(text string) {
+				assert.ErrorIs(t, serviceMessenger.Send(userID, text, "", false), nil)
+			}(text)
+
+			depth := i + 1
+			require.Eventually(t, func() bool { return serviceMessenger.QueueDepth() == depth },
+				time.Second, time.Millisecond)
+		}
+
+		waitClockTimers(t, clock, 1)
+		clock.Advance(time.Second)
+		telegram.waitRequests(t, 2)
+
+		waitClockTimers(t, clock, 1)
+		clock.Advance(time.Second)
+		telegram.waitRequests(t, 3)
+
+		texts := make([]string, 0, 3)
+		for _, request := range telegram.Requests() {
+			texts = append(texts, request.text)
+		}
+
+		assert.Equal(t, []string{"first", "second", "third"}, texts)
+		assert.Equal(t, 0, serviceMessenger.QueueDepth())
+	})
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+)
+
+const (
+	_telegramAlbumLimit = 10
+)
+
+var (
+	// vkMention - [id1|Name], [club1|Name] or [https://example.com|Name].
+	vkMention = regexp.MustCompile(`\[((?:id|club|public|event)\d+|https?://[^|\]]+)\|([^\]]+)\]`)
+
+	// mediaExtensions - files which telegram sends by url, others stay links in the text.
+	mediaExtensions = map[string][]string{
+		entity.AttachmentVideo: {".mp4"},
+		entity.AttachmentDoc:   {".pdf", ".zip", ".gif"},
+	}
+
+	// attachmentLabels - catalog keys of attachment links.
+	attachmentLabels = map[string]string{
+		entity.AttachmentPhoto: "post.photo",
+		entity.AttachmentVideo: "post.video",
+		entity.AttachmentAudio: "post.audio",
+		entity.AttachmentDoc:   "post.doc",
+		entity.AttachmentLink:  "post.link",
+	}
+)
+
+// PostDeliveries - post as telegram messages without chat: albums of post media, the first one with caption,
+// and the rest of the text. Post without media is sent as text messages only. Labels are translated to lang.
+func PostDeliveries(post *entity.Post, renderer markup.Renderer, translator i18n.Translator,
+	lang string) []entity.Delivery {
+	l := localizer{translator, lang}
+
+	media, attachments := postMedia(post.Attachments)
+	if len(media) == 0 {
+		return textDeliveries(PostMessages(post, renderer, translator, lang), renderer)
+	}
+
+	textPost := *post
+	textPost.Attachments = attachments
+
+	texts := markup.Split(renderer, postParts(l, &textPost), markup.CaptionLimit, markup.MessageLimit)
+
+	var deliveries []entity.Delivery
+
+	for i, album := range mediaAlbums(media) {
+		delivery := entity.Delivery{Media: album, ParseMode: renderer.ParseMode()}
+		if i == 0 && len(texts) > 0 {
+			delivery.Text = texts[0]
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if len(texts) > 1 {
+		deliveries = append(deliveries, textDeliveries(texts[1:], renderer)...)
+	}
+
+	return deliveries
+}
+
+// mediaFallback - text of media delivery with file urls instead of files, false if parse mode is unknown.
+func mediaFallback(delivery *entity.Delivery) (string, bool) {
+	renderer, err := markup.New(delivery.ParseMode)
+	if err != nil {
+		return "", false
+	}
+
+	parts := make([]markup.Part, 0, len(delivery.Media))
+	for _, file := range delivery.Media {
+		parts = append(parts, markup.Text("\n"+file.URL))
+	}
+
+	text := strings.TrimPrefix(delivery.Text+renderer.Render(parts), "\n")
+
+	return text, true
+}
+
+// PostMessages - post rendered to telegram messages, every message fits the telegram limit.
+func PostMessages(post *entity.Post, renderer markup.Renderer, translator i18n.Translator, lang string) []string {
+	return markup.Split(renderer, postParts(localizer{translator, lang}, post), markup.MessageLimit, markup.MessageLimit)
+}
+
+func textDeliveries(texts []string, renderer markup.Renderer) []entity.Delivery {
+	deliveries := make([]entity.Delivery, len(texts))
+	for i, text := range texts {
+		deliveries[i] = entity.Delivery{Text: text, ParseMode: renderer.ParseMode()}
+	}
+
+	return deliveries
+}
+
+// postMedia - attachments which can be sent as files and the rest of them.
+func postMedia(attachments []entity.Attachment) (media []entity.Media, rest []entity.Attachment) {
+	for _, attachment := range attachments {
+		if isMedia(attachment) {
+			media = append(media, entity.Media{Type: attachment.Type, URL: attachment.URL})
+		} else {
+			rest = append(rest, attachment)
+		}
+	}
+
+	return
+}
+
+func isMedia(attachment entity.Attachment) bool {
+	if attachment.Type == entity.AttachmentPhoto {
+		return attachment.URL != ""
+	}
+
+	u, err := url.Parse(attachment.URL)
+	if err != nil {
+		return false
+	}
+
+	ext := strings.ToLower(path.Ext(u.Path))
+	for _, mediaExt := range mediaExtensions[attachment.Type] {
+		if ext == mediaExt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mediaAlbums - telegram albums don't mix documents with photos and videos and have up to 10 files.
+func mediaAlbums(media []entity.Media) [][]entity.Media {
+	var visual, documents []entity.Media
+
+	for _, file := range media {
+		if file.Type == entity.AttachmentDoc {
+			documents = append(documents, file)
+		} else {
+			visual = append(visual, file)
+		}
+	}
+
+	var albums [][]entity.Media
+
+	for _, files := range [][]entity.Media{visual, documents} {
+		for len(files) > 0 {
+			size := len(files)
+			if size > _telegramAlbumLimit {
+				size = _telegramAlbumLimit
+			}
+
+			albums = append(albums, files[:size])
+			files = files[size:]
+		}
+	}
+
+	return albums
+}
+
+// postParts - post blocks with the source link as a footer.
+func postParts(l localizer, post *entity.Post) []markup.Part {
+	blocks := postBlocks(l, post)
+	if post.Link != "" {
+		blocks = append(blocks, []markup.Part{markup.LinkText(post.Link, post.Link)})
+	}
+
+	return joinBlocks(blocks)
+}
+
+// postBlocks - title, text, attachments and reposts, blocks are separated by empty line.
+func postBlocks(l localizer, post *entity.Post) [][]markup.Part {
+	var blocks [][]markup.Part
+
+	if title := strings.TrimSpace(post.Title); title != "" {
+		blocks = append(blocks, []markup.Part{markup.BoldText(title)})
+	}
+
+	if text := strings.TrimSpace(post.Text); text != "" {
+		blocks = append(blocks, vkTextParts(text))
+	}
+
+	if attachments := attachmentParts(l, post.Attachments); len(attachments) > 0 {
+		blocks = append(blocks, attachments)
+	}
+
+	if repost := post.Repost; repost != nil {
+		blocks = append(blocks, []markup.Part{
+			markup.ItalicText(l.t("post.repost")),
+			markup.LinkText(repost.Author, repost.Link),
+		})
+		blocks = append(blocks, postBlocks(l, repost)...)
+	}
+
+	return blocks
+}
+
+// vkTextParts - text with vk mentions as links.
+func vkTextParts(text string) []markup.Part {
+	var (
+		parts []markup.Part
+		start int
+	)
+
+	for _, match := range vkMention.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > start {
+			parts = append(parts, markup.Text(text[start:match[0]]))
+		}
+
+		href := text[match[2]:match[3]]
+		if !strings.HasPrefix(href, "http") {
+			href = "https://vk.com/" + href
+		}
+
+		parts = append(parts, markup.LinkText(text[match[4]:match[5]], href))
+		start = match[1]
+	}
+
+	if start < len(text) {
+		parts = append(parts, markup.Text(text[start:]))
+	}
+
+	return parts
+}
+
+func attachmentParts(l localizer, attachments []entity.Attachment) []markup.Part {
+	var parts []markup.Part
+
+	for _, attachment := range attachments {
+		if attachment.URL == "" {
+			continue
+		}
+
+		key, ok := attachmentLabels[attachment.Type]
+		if !ok {
+			key = attachmentLabels[entity.AttachmentDoc]
+		}
+
+		label := l.t(key)
+
+		if title := strings.TrimSpace(attachment.Title); title != "" {
+			label += ": " + title
+		}
+
+		if len(parts) > 0 {
+			parts = append(parts, markup.Text("\n"))
+		}
+
+		parts = append(parts, markup.LinkText(label, attachment.URL))
+	}
+
+	return parts
+}
+
+func joinBlocks(blocks [][]markup.Part) []markup.Part {
+	var parts []markup.Part
+
+	for i, block := range blocks {
+		if i > 0 {
+			parts = append(parts, markup.Text("\n\n"))
+		}
+
+		parts = append(parts, block...)
+	}
+
+	return parts
+}
@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostMessages(t *testing.T) {
+	t.Parallel()
+
+	t.Run("full post", func(t *testing.T) {
+		t.Parallel()
+
+		post := entity.Post{
+			Date:  time.Now(),
+			Title: "Title <1>",
+			Text:  "Новость от [club1|Тестовой группы] & друзей",
+			Link:  "https://vk.com/test_group?w=wall-1_12",
+			Attachments: []entity.Attachment{
+				{Type: entity.AttachmentPhoto, URL: "https://sun.userapi.com/x.jpg"},
+				{Type: entity.AttachmentLink, URL: "https://example.com/?a=1&b=2", Title: "Статья"},
+			},
+			Repost: &entity.Post{Author: "Иван Петров", Link: "https://vk.com/wall5_7", Text: "Оригинал"},
+		}
+
+		assert.Equal(t, []string{
+			"<b>Title &lt;1&gt;</b>\n\n" +
+				`Новость от <a href="https://vk.com/club1">Тестовой группы</a> &amp; друзей` + "\n\n" +
+				`<a href="https://sun.userapi.com/x.jpg">post.photo</a>` + "\n" +
+				`<a href="https://example.com/?a=1&amp;b=2">post.link: Статья</a>` + "\n\n" +
+				`<i>post.repost</i><a href="https://vk.com/wall5_7">Иван Петров</a>` + "\n\n" +
+				"Оригинал\n\n" +
+				`<a href="https://vk.com/test_group?w=wall-1_12">https://vk.com/test_group?w=wall-1_12</a>`,
+		}, service.PostMessages(&post, markup.HTML, i18n.Keys{}, ""))
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		t.Parallel()
+
+		post := entity.Post{Text: "*не жирный* [club1|группа_1] (1.5)", Link: "https://vk.com/wall-1_1"}
+
+		assert.Equal(t, []string{
+			"\\*не жирный\\* [группа\\_1](https://vk.com/club1) \\(1\\.5\\)\n\n" +
+				"[https://vk\\.com/wall\\-1\\_1](https://vk.com/wall-1_1)",
+		}, service.PostMessages(&post, markup.MarkdownV2, i18n.Keys{}, ""))
+	})
+
+	t.Run("long post", func(t *testing.T) {
+		t.Parallel()
+
+		paragraph := strings.Repeat("слово ", 500)
+		post := entity.Post{
+			Title: "Title",
+			Text:  paragraph + "\n" + paragraph + "[id1|" + strings.Repeat("😀", 100) + "]",
+			Link:  "https://vk.com/wall1_1",
+		}
+
+		messages := service.PostMessages(&post, markup.HTML, i18n.Keys{}, "")
+		assert.Equal(t, 2, len(messages))
+
+		for _, message := range messages {
+			text := strings.NewReplacer("<b>", "", "</b>", "", `<a href="https://vk.com/id1">`, "", "</a>", "",
+				`<a href="https://vk.com/wall1_1">`, "").Replace(message)
+			assert.LessOrEqual(t, len(utf16.Encode([]rune(text))), 4096)
+			assert.Equal(t, strings.Count(message, "<a "), strings.Count(message, "</a>"))
+		}
+
+		assert.True(t, strings.HasPrefix(messages[0], "<b>Title</b>\n\nслово"))
+		assert.True(t, strings.HasPrefix(messages[1], "слово"))
+		assert.Contains(t, messages[1], `<a href="https://vk.com/id1">😀`)
+	})
+
+	t.Run("long word", func(t *testing.T) {
+		t.Parallel()
+
+		post := entity.Post{Text: strings.Repeat("a", 5000)}
+
+		messages := service.PostMessages(&post, markup.HTML, i18n.Keys{}, "")
+		assert.Equal(t, []string{strings.Repeat("a", 4096), strings.Repeat("a", 904)}, messages)
+	})
+}
+
+func TestPostDeliveries(t *testing.T) {
+	t.Parallel()
+
+	photo := func(i int) entity.Attachment {
+		return entity.Attachment{Type: entity.AttachmentPhoto, URL: "https://sun.userapi.com/" + strconv.Itoa(i) + ".jpg"}
+	}
+
+	t.Run("text post", func(t *testing.T) {
+		t.Parallel()
+
+		post := entity.Post{Text: "text", Link: "https://vk.com/wall1_1"}
+
+		assert.Equal(t, []entity.Delivery{
+			{Text: "text\n\n" + `<a href="https://vk.com/wall1_1">https://vk.com/wall1_1</a>`, ParseMode: "HTML"},
+		}, service.PostDeliveries(&post, markup.HTML, i18n.Keys{}, ""))
+	})
+
+	t.Run("photo with caption", func(t *testing.T) {
+		t.Parallel()
+
+		post := entity.Post{
+			Text: "text",
+			Link: "https://vk.com/wall1_1",
+			Attachments: []entity.Attachment{
+				photo(1),
+				{Type: entity.AttachmentVideo, URL: "https://vk.com/video1_1", Title: "video"},
+			},
+		}
+
+		assert.Equal(t, []entity.Delivery{{
+			Text: "text\n\n" + `<a href="https://vk.com/video1_1">post.video: video</a>` + "\n\n" +
+				`<a href="https://vk.com/wall1_1">https://vk.com/wall1_1</a>`,
+			ParseMode: "HTML",
+			Media:     entity.DeliveryMedia{{Type: entity.AttachmentPhoto, URL: "https://sun.userapi.com/1.jpg"}},
+		}}, service.PostDeliveries(&post, markup.HTML, i18n.Keys{}, ""))
+	})
+
+	t.Run("albums and overflow text", func(t *testing.T) {
+		t.Parallel()
+
+		post := entity.Post{Text: strings.Repeat("слово ", 300), Link: "https://vk.com/wall1_1"}
+		for i := 0; i < 12; i++ {
+			post.Attachments = append(post.Attachments, photo(i))
+		}
+
+		post.Attachments = append(post.Attachments,
+			entity.Attachment{Type: entity.AttachmentDoc, URL: "https://example.com/report.PDF"},
+			entity.Attachment{Type: entity.AttachmentDoc, URL: "https://vk.com/doc1_1?hash=1", Title: "doc"},
+		)
+
+		deliveries := service.PostDeliveries(&post, markup.HTML, i18n.Keys{}, "")
+		assert.Equal(t, 4, len(deliveries))
+
+		assert.Equal(t, 10, len(deliveries[0].Media))
+		assert.LessOrEqual(t, len(utf16.Encode([]rune(deliveries[0].Text))), 1024)
+		assert.True(t, strings.HasPrefix(deliveries[0].Text, "слово"))
+
+		assert.Equal(t, 2, len(deliveries[1].Media))
+		assert.Empty(t, deliveries[1].Text)
+
+		assert.Equal(t, entity.DeliveryMedia{{Type: entity.AttachmentDoc, URL: "https://example.com/report.PDF"}},
+			deliveries[2].Media)
+
+		assert.Empty(t, deliveries[3].Media)
+		assert.Contains(t, deliveries[3].Text, `<a href="https://vk.com/doc1_1?hash=1">post.doc: doc</a>`)
+	})
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+)
+
+// quietDeliveries - deliveries in quiet hours of the user wait for their end or come without sound.
+// Held deliveries are saved with the first attempt at the end of quiet hours, so they survive restarts.
+func quietDeliveries(user *entity.User, deliveries []entity.Delivery, t time.Time) {
+	until := user.QuietUntil(t)
+	if until == nil {
+		return
+	}
+
+	for i := range deliveries {
+		if user.QuietMode == entity.QuietSilent {
+			deliveries[i].DisableNotification = true
+		} else {
+			deliveries[i].NextAttemptAt = *until
+		}
+	}
+}
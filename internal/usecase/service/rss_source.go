@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+type RssSource struct {
+	name   string
+	client httpclient.InterfaceClient
+}
+
+var (
+	htmlTags    = regexp.MustCompile(`<(?:[^>"']|"[^"]*"|'[^']*')*>`)
+	feedFormats = []string{
+		time.RFC1123Z,
+		time.RFC1123,
+		time.RFC3339,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		"2 Jan 2006 15:04:05 -0700",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	}
+)
+
+func NewRssSource(client httpclient.InterfaceClient) *RssSource {
+	return &RssSource{"rss", client}
+}
+
+func (r *RssSource) Name() string {
+	return r.name
+}
+
+// GroupName - feed url is the group name, it is recognized by path like /feed, /rss or *.xml.
+// Urls of vk and telegram are never feeds, even when they are not groups there.
+func (r *RssSource) GroupName(u *url.URL) (string, bool) {
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", false
+	}
+
+	if host := hostName(u); vkHosts[host] || telegramHosts[host] {
+		return "", false
+	}
+
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".rss", ".xml", ".atom", ".rdf":
+		return u.String(), true
+	}
+
+	for _, segment := range pathSegments(u) {
+		switch strings.ToLower(segment) {
+		case "rss", "feed", "feeds", "atom", "rss.php":
+			return u.String(), true
+		}
+	}
+
+	return "", false
+}
+
+// GetPosts - feeds have no pagination, so the whole feed is a single page.
+func (r *RssSource) GetPosts(_ context.Context, group, _ string) (entity.PostsPage, error) {
+	feed, err := r.GetFeed(group)
+	if err != nil {
+		return entity.PostsPage{}, err
+	}
+
+	for i := range feed.Posts {
+		if feed.Posts[i].Author == "" {
+			feed.Posts[i].Author = feed.Title
+		}
+	}
+
+	return entity.PostsPage{Posts: feed.Posts}, nil
+}
+
+func (r *RssSource) GetFeed(feedURL string) (feed entity.RssFeed, err error) {
+	res, err := r.client.Get(feedURL)
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	// removed feed
+	if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone {
+		return feed, fmt.Errorf("`r.GetFeed` %s: %w", feedURL, errors.ErrGroupUnavailable)
+	}
+
+	if res.StatusCode >= 300 {
+		return feed, fmt.Errorf("`r.GetFeed` %s: status %d", feedURL, res.StatusCode)
+	}
+
+	return ParseFeed(res.Body)
+}
+
+// ParseFeed - parse rss 2.0, rss 1.0 or atom 1.0 document, posts are sorted from new to old.
+func ParseFeed(body io.Reader) (feed entity.RssFeed, err error) {
+	var response entity.FeedResponse
+
+	decoder := xml.NewDecoder(body)
+	decoder.CharsetReader = charsetReader
+
+	if err = decoder.Decode(&response); err != nil {
+		return
+	}
+
+	feed.Title = strings.TrimSpace(response.Title)
+	if feed.Title == "" {
+		feed.Title = strings.TrimSpace(response.Channel.Title)
+	}
+
+	items := append(response.Channel.Items, response.Items...)
+	for i := range items {
+		feed.Posts = append(feed.Posts, feedItemPost(&items[i]))
+	}
+
+	for i := range response.Entries {
+		feed.Posts = append(feed.Posts, atomEntryPost(&response.Entries[i]))
+	}
+
+	sort.SliceStable(feed.Posts, func(i, j int) bool {
+		return feed.Posts[i].Date.After(feed.Posts[j].Date)
+	})
+
+	return feed, nil
+}
+
+// charsetReader - decode document in encoding from xml declaration like windows-1251 to utf-8.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("`charsetReader` something wrong: %w", err)
+	}
+
+	return encoding.NewDecoder().Reader(input), nil
+}
+
+func feedItemPost(item *entity.FeedItem) entity.Post {
+	post := entity.Post{
+		ID:     firstNotEmpty(item.GUID, item.About, item.Link, item.Title),
+		Author: cleanText(item.Creator),
+		Title:  cleanText(item.Title),
+		Link:   strings.TrimSpace(item.Link),
+		Text:   cleanText(item.Description),
+		Date:   parseFeedDate(firstNotEmpty(item.PubDate, item.Date)),
+	}
+
+	for _, enclosure := range item.Enclosures {
+		post.Attachments = append(post.Attachments, entity.Attachment{
+			Type: enclosureType(enclosure.Type),
+			URL:  enclosure.URL,
+		})
+	}
+
+	return post
+}
+
+// enclosureType - attachment type by mime type.
+func enclosureType(mimeType string) string {
+	switch strings.Split(mimeType, "/")[0] {
+	case "image":
+		return entity.AttachmentPhoto
+	case "video":
+		return entity.AttachmentVideo
+	case "audio":
+		return entity.AttachmentAudio
+	default:
+		return entity.AttachmentDoc
+	}
+}
+
+func atomEntryPost(entry *entity.AtomEntry) entity.Post {
+	var link string
+
+	for _, l := range entry.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			link = l.Href
+
+			break
+		}
+	}
+
+	return entity.Post{
+		ID:     firstNotEmpty(entry.ID, link, entry.Title),
+		Author: cleanText(entry.Author.Name),
+		Title:  cleanText(entry.Title),
+		Link:   strings.TrimSpace(link),
+		Text:   cleanText(firstNotEmpty(entry.Summary, entry.Content)),
+		Date:   parseFeedDate(firstNotEmpty(entry.Published, entry.Updated)),
+	}
+}
+
+func parseFeedDate(value string) time.Time {
+	for _, format := range feedFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t.UTC()
+		}
+	}
+
+	return time.Time{}
+}
+
+func cleanText(text string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTags.ReplaceAllString(text, "")))
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
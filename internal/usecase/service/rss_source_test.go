@@ -0,0 +1,189 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func feedFixture(t *testing.T, name string) entity.RssFeed {
+	t.Helper()
+
+	file, err := os.Open("testdata/rss/" + name)
+	require.ErrorIs(t, err, nil)
+
+	defer file.Close()
+
+	feed, err := service.ParseFeed(file)
+	require.ErrorIs(t, err, nil)
+
+	return feed
+}
+
+func TestRssGroupName(t *testing.T) {
+	t.Parallel()
+
+	source := service.NewRssSource(httpclient.NewClient())
+
+	t.Run("feed urls", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rawURL := range []string{
+			"https://example.com/rss.xml",
+			"https://example.com/blog/feed/",
+			"http://example.com/atom?lang=en",
+		} {
+			u, err := neturl.Parse(rawURL)
+			require.ErrorIs(t, err, nil)
+
+			name, ok := source.GroupName(u)
+			assert.True(t, ok)
+			assert.Equal(t, rawURL, name)
+		}
+	})
+
+	t.Run("not feed urls", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rawURL := range []string{
+			"https://example.com/page",
+			"ftp://example.com/rss.xml",
+			"https://vk.com/feed",
+			"https://t.me/s/rss",
+		} {
+			u, err := neturl.Parse(rawURL)
+			require.ErrorIs(t, err, nil)
+
+			_, ok := source.GroupName(u)
+			assert.False(t, ok)
+		}
+	})
+}
+
+func TestParseFeed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rss 2.0", func(t *testing.T) {
+		t.Parallel()
+
+		feed := feedFixture(t, "rss2.xml")
+		assert.Equal(t, "Test RSS 2.0", feed.Title)
+		assert.Equal(t, []entity.Post{
+			{
+				ID:    "post-2",
+				Title: "Second & last",
+				Link:  "https://example.com/2",
+				Text:  "Second post",
+				Date:  time.Date(2021, 11, 16, 7, 0, 0, 0, time.UTC),
+				Attachments: []entity.Attachment{
+					{Type: entity.AttachmentPhoto, URL: "https://example.com/2.jpg"},
+				},
+			},
+			{
+				ID:    "https://example.com/1",
+				Title: "First",
+				Link:  "https://example.com/1",
+				Text:  "First post",
+				Date:  time.Date(2021, 11, 15, 10, 0, 0, 0, time.UTC),
+			},
+		}, feed.Posts)
+	})
+
+	t.Run("rss 2.0 in windows-1251", func(t *testing.T) {
+		t.Parallel()
+
+		feed := feedFixture(t, "rss2_windows1251.xml")
+		assert.Equal(t, "Новости", feed.Title)
+		assert.Equal(t, []entity.Post{{
+			ID:    "https://example.ru/1",
+			Title: "Первая новость",
+			Link:  "https://example.ru/1",
+			Text:  "Текст новости",
+			Date:  time.Date(2021, 11, 15, 10, 0, 0, 0, time.UTC),
+		}}, feed.Posts)
+	})
+
+	t.Run("rss 1.0", func(t *testing.T) {
+		t.Parallel()
+
+		feed := feedFixture(t, "rss1.xml")
+		assert.Equal(t, "Test RSS 1.0", feed.Title)
+		assert.Equal(t, []entity.Post{{
+			ID:     "https://example.com/rdf/1",
+			Author: "Author",
+			Title:  "RDF item",
+			Link:   "https://example.com/rdf/1",
+			Text:   "RDF description",
+			Date:   time.Date(2021, 11, 15, 10, 0, 0, 0, time.UTC),
+		}}, feed.Posts)
+	})
+
+	t.Run("atom 1.0", func(t *testing.T) {
+		t.Parallel()
+
+		feed := feedFixture(t, "atom.xml")
+		assert.Equal(t, "Test Atom", feed.Title)
+		assert.Equal(t, []entity.Post{{
+			ID:     "urn:uuid:entry-1",
+			Author: "Writer",
+			Title:  "Atom entry",
+			Link:   "https://example.com/atom/1",
+			Text:   "Atom summary",
+			Date:   time.Date(2021, 11, 15, 7, 0, 0, 0, time.UTC),
+		}}, feed.Posts)
+	})
+}
+
+func TestRssGetPosts(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata/rss")))
+	t.Cleanup(server.Close)
+
+	source := service.NewRssSource(httpclient.NewClient())
+
+	t.Run("single page with feed title as author", func(t *testing.T) {
+		t.Parallel()
+
+		page, err := source.GetPosts(context.Background(), server.URL+"/rss2.xml", "")
+		assert.ErrorIs(t, err, nil)
+		assert.Empty(t, page.Next)
+		assert.Len(t, page.Posts, 2)
+		assert.Equal(t, "Test RSS 2.0", page.Posts[0].Author)
+	})
+}
+
+func TestGetFeed(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata/rss")))
+	t.Cleanup(server.Close)
+
+	source := service.NewRssSource(httpclient.NewClient())
+
+	t.Run("get feed", func(t *testing.T) {
+		t.Parallel()
+
+		feed, err := source.GetFeed(server.URL + "/atom.xml")
+		assert.ErrorIs(t, err, nil)
+		assert.Len(t, feed.Posts, 1)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := source.GetFeed(server.URL + "/unknown.xml")
+		assert.ErrorIs(t, err, errors.ErrGroupUnavailable)
+	})
+}
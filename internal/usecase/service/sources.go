@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jokius/news-telegram-bot/internal/usecase"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+)
+
+// SourceRegistry - finds source by group url.
+type SourceRegistry struct {
+	sources []usecase.Source
+}
+
+func NewSourceRegistry(sources ...usecase.Source) *SourceRegistry {
+	return &SourceRegistry{sources}
+}
+
+func (r *SourceRegistry) Find(rawURL string) (usecase.Source, string, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", errors.ErrUnknownSource, rawURL)
+	}
+
+	for _, source := range r.sources {
+		if name, ok := source.GroupName(u); ok {
+			return source, name, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w: %s", errors.ErrUnknownSource, rawURL)
+}
+
+func (r *SourceRegistry) All() []usecase.Source {
+	return r.sources
+}
+
+func parseURL(rawURL string) (*url.URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	return url.Parse(rawURL)
+}
+
+func hostName(u *url.URL) string {
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+func pathSegments(u *url.URL) []string {
+	var segments []string
+
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	return segments
+}
@@ -0,0 +1,53 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	vk, _ := sourceVk(t)
+	rss := service.NewRssSource(httpclient.NewClient())
+	registry := service.NewSourceRegistry(vk, rss)
+
+	t.Run("find vk group", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rawURL := range []string{
+			"https://vk.com/test_group",
+			"http://www.vk.com/test_group?w=wall-1_2",
+			"https://m.vk.com/test_group/",
+			"vk.com/test_group",
+		} {
+			source, name, err := registry.Find(rawURL)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, vk, source)
+			assert.Equal(t, "test_group", name)
+		}
+	})
+
+	t.Run("find rss feed", func(t *testing.T) {
+		t.Parallel()
+
+		source, name, err := registry.Find("https://example.com/feed")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, rss, source)
+		assert.Equal(t, "https://example.com/feed", name)
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rawURL := range []string{"https://example.com/test_group", "https://vk.com/", "https://vk.com/feed", "%"} {
+			source, _, err := registry.Find(rawURL)
+			assert.ErrorIs(t, err, errors.ErrUnknownSource)
+			assert.Nil(t, source)
+		}
+	})
+}
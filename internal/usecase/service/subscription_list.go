@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+)
+
+const (
+	_subscriptionsPageSize = 5
+)
+
+// subscriptionList - page of subscriptions with inline keyboard, a row of buttons for every subscription.
+// Page out of range is moved to the last one, it happens after removing. Time t is in the timezone of the user.
+func subscriptionList(l localizer, subscriptions []entity.Subscription, page int,
+	t time.Time) ([]markup.Part, *entity.TelegramInlineKeyboard) {
+	if len(subscriptions) == 0 {
+		return []markup.Part{markup.Text(l.t("list.empty"))}, nil
+	}
+
+	pages := (len(subscriptions) + _subscriptionsPageSize - 1) / _subscriptionsPageSize
+	if page >= pages {
+		page = pages - 1
+	}
+
+	offset := page * _subscriptionsPageSize
+	end := offset + _subscriptionsPageSize
+
+	if end > len(subscriptions) {
+		end = len(subscriptions)
+	}
+
+	parts := []markup.Part{markup.BoldText(l.plural("list.header", len(subscriptions)))}
+	keyboard := &entity.TelegramInlineKeyboard{}
+
+	for i := offset; i < end; i++ {
+		subscription := &subscriptions[i]
+		number := i + 1
+
+		parts = append(parts, markup.Text(fmt.Sprintf("\n%d. ", number)), markup.CodeText(subscription.Feed.Name))
+		if state := subscriptionState(l, subscription, t); state != "" {
+			parts = append(parts, markup.ItalicText(" "+state))
+		}
+
+		if len(subscription.Filters) > 0 {
+			parts = append(parts, markup.Text("\n    "), markup.ItalicText(l.t("list.filters", subscription.Filters.String())))
+		}
+
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, subscriptionButtons(l, subscription, number, page, t))
+	}
+
+	if pages > 1 {
+		parts = append(parts, markup.Text("\n\n"+l.t("list.page", page+1, pages)))
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, pageButtons(l, page, pages))
+	}
+
+	return parts, keyboard
+}
+
+func subscriptionState(l localizer, subscription *entity.Subscription, t time.Time) string {
+	switch {
+	case subscription.BrokenAt != nil:
+		return l.t("list.unavailable")
+	case subscription.Paused:
+		return l.t("list.paused")
+	case subscription.Muted(t):
+		return l.t("list.muted", subscription.MutedUntil.In(t.Location()).Format("02.01 15:04"))
+	default:
+		return ""
+	}
+}
+
+func subscriptionButtons(l localizer, subscription *entity.Subscription, number, page int,
+	t time.Time) []entity.TelegramInlineButton {
+	pause := "list.pause"
+	if subscription.Paused {
+		pause = "list.resume"
+	}
+
+	mute := "list.mute"
+	if subscription.Muted(t) {
+		mute = "list.unmute"
+	}
+
+	return []entity.TelegramInlineButton{
+		button(l.t("list.remove", number), entity.CallbackRemove, subscription.ID, page),
+		button(l.t(pause, number), entity.CallbackPause, subscription.ID, page),
+		button(l.t(mute, number), entity.CallbackMute, subscription.ID, page),
+	}
+}
+
+func pageButtons(l localizer, page, pages int) []entity.TelegramInlineButton {
+	var buttons []entity.TelegramInlineButton
+
+	if page > 0 {
+		buttons = append(buttons, button(l.t("list.prev"), entity.CallbackList, 0, page-1))
+	}
+
+	if page < pages-1 {
+		buttons = append(buttons, button(l.t("list.next"), entity.CallbackList, 0, page+1))
+	}
+
+	return buttons
+}
+
+func button(text, action string, subscriptionID uint64, page int) entity.TelegramInlineButton {
+	data := entity.CallbackData{Action: action, SubscriptionID: subscriptionID, Page: page}
+
+	return entity.TelegramInlineButton{Text: text, CallbackData: data.String()}
+}
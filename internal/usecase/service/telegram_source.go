@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+)
+
+// TelegramSource - public telegram channels through https://t.me/s/<channel> web preview.
+type TelegramSource struct {
+	name    string
+	baseURL string
+	client  httpclient.InterfaceClient
+}
+
+var (
+	channelName     = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{3,31}$`)
+	channelPostAttr = regexp.MustCompile(`data-post="([^"/]+)/(\d+)"`)
+	channelPostTime = regexp.MustCompile(`<time[^>]*datetime="([^"]+)"`)
+	channelPostText = regexp.MustCompile(`<div class="tgme_widget_message_text[^"]*js-message_text[^"]*"[^>]*>`)
+	htmlLineBreak   = regexp.MustCompile(`<br\s*/?>`)
+	htmlTag         = regexp.MustCompile(`<(/?)([A-Za-z][A-Za-z0-9]*)(?:[^>"']|"[^"]*"|'[^']*')*>`)
+	telegramHosts   = map[string]bool{"t.me": true, "telegram.me": true}
+)
+
+func NewTelegramSource(baseURL string, client httpclient.InterfaceClient) *TelegramSource {
+	if lastCh := baseURL[len(baseURL)-1:]; lastCh != "/" {
+		baseURL += "/"
+	}
+
+	return &TelegramSource{"telegram", baseURL, client}
+}
+
+func (s *TelegramSource) Name() string {
+	return s.name
+}
+
+func (s *TelegramSource) GroupName(u *url.URL) (string, bool) {
+	if !telegramHosts[hostName(u)] {
+		return "", false
+	}
+
+	segments := pathSegments(u)
+	if len(segments) > 1 && segments[0] == "s" {
+		segments = segments[1:]
+	}
+
+	if len(segments) == 0 || !channelName.MatchString(segments[0]) {
+		return "", false
+	}
+
+	switch strings.ToLower(segments[0]) {
+	case "joinchat", "addstickers", "share", "proxy", "socks", "iv":
+		return "", false
+	}
+
+	return strings.ToLower(segments[0]), true
+}
+
+// GetPosts - cursor is the id of the oldest post from the previous page.
+func (s *TelegramSource) GetPosts(_ context.Context, group, cursor string) (page entity.PostsPage, err error) {
+	var before uint64
+
+	if cursor != "" {
+		if before, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+			return
+		}
+	}
+
+	posts, err := s.GetChannelPosts(group, before)
+	if err != nil || len(posts) == 0 {
+		return
+	}
+
+	for i := len(posts) - 1; i >= 0; i-- {
+		posts[i].Author = group
+		page.Posts = append(page.Posts, posts[i])
+	}
+
+	if oldest := posts[0].ID; oldest != "1" {
+		page.Next = oldest
+	}
+
+	return page, nil
+}
+
+// GetChannelPosts - page of channel posts older than before (0 - the latest), sorted from old to new.
+func (s *TelegramSource) GetChannelPosts(channel string, before uint64) ([]entity.Post, error) {
+	pageURL := s.baseURL + url.PathEscape(channel)
+	if before != 0 {
+		pageURL += "?before=" + strconv.FormatUint(before, 10)
+	}
+
+	res, err := s.client.Get(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	// missing channel or channel without web preview is redirected from the preview to its contact page
+	redirected := res.Request != nil && !strings.HasPrefix(res.Request.URL.String(), s.baseURL)
+	if res.StatusCode == http.StatusNotFound || redirected {
+		return nil, fmt.Errorf("`s.GetChannelPosts` %s: %w", channel, errors.ErrGroupUnavailable)
+	}
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("`s.GetChannelPosts` %s: status %d", channel, res.StatusCode)
+	}
+
+	return ParseChannelPage(res.Body)
+}
+
+// ParseChannelPage - parse posts from t.me/s/<channel> html page.
+func ParseChannelPage(body io.Reader) ([]entity.Post, error) {
+	page, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	content := string(page)
+	bounds := channelPostAttr.FindAllStringSubmatchIndex(content, -1)
+	posts := make([]entity.Post, 0, len(bounds))
+
+	for i, bound := range bounds {
+		end := len(content)
+		if i+1 < len(bounds) {
+			end = bounds[i+1][0]
+		}
+
+		channel, id := content[bound[2]:bound[3]], content[bound[4]:bound[5]]
+		posts = append(posts, channelPost(channel, id, content[bound[1]:end]))
+	}
+
+	return posts, nil
+}
+
+func channelPost(channel, id, content string) entity.Post {
+	post := entity.Post{
+		ID:   id,
+		Link: "https://t.me/" + channel + "/" + id,
+	}
+
+	if match := channelPostTime.FindStringSubmatch(content); match != nil {
+		if date, err := time.Parse(time.RFC3339, match[1]); err == nil {
+			post.Date = date.UTC()
+		}
+	}
+
+	if bound := channelPostText.FindStringIndex(content); bound != nil {
+		text := innerHTML(content[bound[1]:], "div")
+		post.Text = cleanText(htmlLineBreak.ReplaceAllString(text, "\n"))
+	}
+
+	return post
+}
+
+// innerHTML - content of the element whose start tag is right before content, it ends at the closing tag
+// of the same depth, so nested elements with the same tag are kept.
+func innerHTML(content, tag string) string {
+	depth := 1
+
+	for _, bound := range htmlTag.FindAllStringSubmatchIndex(content, -1) {
+		name, selfClosing := content[bound[4]:bound[5]], strings.HasSuffix(content[bound[0]:bound[1]], "/>")
+		if !strings.EqualFold(name, tag) || selfClosing {
+			continue
+		}
+
+		if closing := bound[3] > bound[2]; !closing {
+			depth++
+
+			continue
+		}
+
+		if depth--; depth == 0 {
+			return content[:bound[0]]
+		}
+	}
+
+	return content
+}
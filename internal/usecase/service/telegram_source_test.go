@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func channelServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/s/missing_channel":
+			http.Redirect(w, r, "/missing_channel", http.StatusFound)
+		case r.URL.Path == "/missing_channel":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path != "/s/test_channel":
+			http.NotFound(w, r)
+		case r.URL.Query().Get("before") == "41":
+			http.ServeFile(w, r, "testdata/telegram/channel_before_41.html")
+		default:
+			http.ServeFile(w, r, "testdata/telegram/channel.html")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestTelegramGroupName(t *testing.T) {
+	t.Parallel()
+
+	source := service.NewTelegramSource("https://t.me/s", httpclient.NewClient())
+
+	t.Run("channel urls", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rawURL := range []string{"https://t.me/Test_Channel", "https://t.me/s/test_channel", "https://telegram.me/test_channel/42"} {
+			u, err := neturl.Parse(rawURL)
+			require.ErrorIs(t, err, nil)
+
+			name, ok := source.GroupName(u)
+			assert.True(t, ok)
+			assert.Equal(t, "test_channel", name)
+		}
+	})
+
+	t.Run("not channel urls", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rawURL := range []string{"https://t.me/joinchat/AAAA", "https://t.me/+AAAA", "https://vk.com/test_channel"} {
+			u, err := neturl.Parse(rawURL)
+			require.ErrorIs(t, err, nil)
+
+			_, ok := source.GroupName(u)
+			assert.False(t, ok)
+		}
+	})
+}
+
+func TestGetChannelPosts(t *testing.T) {
+	t.Parallel()
+
+	server := channelServer(t)
+	source := service.NewTelegramSource(server.URL+"/s/", httpclient.NewClient())
+
+	t.Run("latest posts", func(t *testing.T) {
+		t.Parallel()
+
+		posts, err := source.GetChannelPosts("test_channel", 0)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, []entity.Post{
+			{
+				ID:   "41",
+				Date: time.Date(2021, 11, 15, 10, 0, 0, 0, time.UTC),
+				Text: "First post\nsecond line & more",
+				Link: "https://t.me/test_channel/41",
+			},
+			{
+				ID:   "42",
+				Date: time.Date(2021, 11, 16, 9, 30, 0, 0, time.UTC),
+				Text: "Reply with link",
+				Link: "https://t.me/test_channel/42",
+			},
+		}, posts)
+	})
+
+	t.Run("posts before", func(t *testing.T) {
+		t.Parallel()
+
+		posts, err := source.GetChannelPosts("test_channel", 41)
+		assert.ErrorIs(t, err, nil)
+		assert.Len(t, posts, 1)
+		assert.Equal(t, "40", posts[0].ID)
+	})
+
+	t.Run("pages from new to old", func(t *testing.T) {
+		t.Parallel()
+
+		page, err := source.GetPosts(context.Background(), "test_channel", "")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "41", page.Next)
+		assert.Equal(t, "42", page.Posts[0].ID)
+		assert.Equal(t, "test_channel", page.Posts[0].Author)
+
+		page, err = source.GetPosts(context.Background(), "test_channel", page.Next)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "40", page.Next)
+		assert.Equal(t, "40", page.Posts[0].ID)
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := source.GetChannelPosts("unknown", 0)
+		assert.ErrorIs(t, err, errors.ErrGroupUnavailable)
+	})
+
+	t.Run("channel redirected to contact page", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := source.GetChannelPosts("missing_channel", 0)
+		assert.ErrorIs(t, err, errors.ErrGroupUnavailable)
+	})
+}
+
+func TestParseChannelPage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text with nested divs", func(t *testing.T) {
+		t.Parallel()
+
+		page, err := os.Open("testdata/telegram/channel_nested_text.html")
+		require.ErrorIs(t, err, nil)
+
+		defer page.Close()
+
+		posts, err := service.ParseChannelPage(page)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, []entity.Post{{
+			ID:   "43",
+			Date: time.Date(2021, 11, 17, 8, 0, 0, 0, time.UTC),
+			Text: "Before quote\nQuoted text\nAfter quote",
+			Link: "https://t.me/test_channel/43",
+		}}, posts)
+	})
+}
@@ -1,38 +1,248 @@
 package service
 
 import (
+	"context"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jokius/news-telegram-bot/internal/entity"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
 	"github.com/jokius/news-telegram-bot/pkg/httpclient"
 )
 
+// VkSource - posts of vk walls. Requests rejected by flood control are retried floodRetries times,
+// the pause before retry starts from floodDelay and doubles.
 type VkSource struct {
-	name   string
-	token  string
-	client httpclient.InterfaceClient
+	name         string
+	token        string
+	floodDelay   time.Duration
+	floodRetries int
+	client       httpclient.InterfaceClient
 }
 
+var (
+	vkScreenName = regexp.MustCompile(`^[A-Za-z0-9_.]{2,32}$`)
+	vkObjectPath = regexp.MustCompile(`(?i)^([a-z]+-?\d+(_\d+)+|id\d+)$`)
+	vkGroupID    = regexp.MustCompile(`(?i)^(club|public|event)\d+$`)
+	vkHosts      = map[string]bool{"vk.com": true, "m.vk.com": true, "vk.ru": true, "m.vk.ru": true}
+)
+
 const (
-	baseURL = "https://api.vk.com/method/wall.get?v=5.131&count=100"
+	baseURL = "https://api.vk.com/method/wall.get?v=5.131&count=100&extended=1"
 )
 
-func NewVkSource(token string, client httpclient.InterfaceClient) *VkSource {
-	return &VkSource{"vk", token, client}
+func NewVkSource(token string, floodDelay time.Duration, floodRetries int,
+	client httpclient.InterfaceClient) *VkSource {
+	return &VkSource{"vk", token, floodDelay, floodRetries, client}
 }
 
 func (v *VkSource) Name() string {
 	return v.name
 }
 
-func (v *VkSource) GetGroupMessages(id string, offset int) (entity.VkResult, error) {
+// GroupName - first path segment is the group, it is a screen name or club/public id.
+// Vk ignores case of them, so the name is lowercase and the same group is one feed.
+func (v *VkSource) GroupName(u *url.URL) (string, bool) {
+	if !vkHosts[hostName(u)] {
+		return "", false
+	}
+
+	segments := pathSegments(u)
+	if len(segments) == 0 {
+		return "", false
+	}
+
+	name := strings.ToLower(segments[0])
+	if vkGroupID.MatchString(name) {
+		return name, true
+	}
+
+	// posts, photos and user pages look like screen names
+	if !vkScreenName.MatchString(name) || vkObjectPath.MatchString(name) {
+		return "", false
+	}
+
+	switch name {
+	case "feed", "im", "friends", "groups", "search", "settings", "apps", "music", "video", "away.php":
+		return "", false
+	}
+
+	return name, true
+}
+
+// GetPosts - cursor is the offset of the wall.
+func (v *VkSource) GetPosts(ctx context.Context, group, cursor string) (page entity.PostsPage, err error) {
+	offset := 0
+
+	if cursor != "" {
+		if offset, err = strconv.Atoi(cursor); err != nil {
+			return
+		}
+	}
+
+	result, err := v.GetGroupMessages(ctx, group, offset)
+	if err != nil || len(result.Messages) == 0 {
+		return
+	}
+
+	authors := vkAuthors(result)
+
+	for i := range result.Messages {
+		page.Posts = append(page.Posts, vkPost(group, &result.Messages[i], authors))
+	}
+
+	page.Next = strconv.Itoa(offset + len(result.Messages))
+
+	return page, nil
+}
+
+// GetGroupMessages - page of the wall, error object of the response is returned as errors.VkError.
+// Canceled ctx stops waiting for flood control.
+func (v *VkSource) GetGroupMessages(ctx context.Context, id string, offset int) (entity.VkResult, error) {
 	url := baseURL +
 		"&access_token=" + v.token +
 		"&domain=" + id +
 		"&offset=" + strconv.Itoa(offset)
 
-	var response entity.VkResponse
-	err := v.client.GetJSON(url, &response)
+	delay := v.floodDelay
+
+	for retry := 0; ; retry++ {
+		var response entity.VkResponse
+		if err := v.client.GetJSON(url, &response); err != nil {
+			return entity.VkResult{}, err
+		}
+
+		if response.Error == nil {
+			return response.VkResult, nil
+		}
+
+		err := errors.NewVkError(response.Error.ErrorCode, response.Error.ErrorMsg)
+		if !errors.IsVkFlood(err) || retry >= v.floodRetries {
+			return entity.VkResult{}, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return entity.VkResult{}, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func vkPost(group string, message *entity.VkMessage, authors map[int64]string) entity.Post {
+	ownerID := strconv.FormatInt(message.OwnerID, 10)
+	messageID := strconv.FormatUint(message.ID, 10)
+
+	author, ok := authors[message.OwnerID]
+	if !ok {
+		author = group
+	}
+
+	post := entity.Post{
+		ID:          messageID,
+		Seq:         message.ID,
+		Pinned:      message.IsPinned == 1,
+		Author:      author,
+		Date:        time.Unix(message.Date, 0).UTC(),
+		Text:        message.Text,
+		Link:        "https://vk.com/" + group + "?w=wall" + ownerID + "_" + messageID,
+		Attachments: vkAttachments(message.Attachments),
+	}
+
+	// copy_history is the chain of reposts, from the reposted post to the original one
+	repost := &post
+	for i := range message.CopyHistory {
+		repost.Repost = vkRepost(&message.CopyHistory[i], authors)
+		repost = repost.Repost
+	}
+
+	return post
+}
+
+func vkRepost(message *entity.VkMessage, authors map[int64]string) *entity.Post {
+	link := "https://vk.com/wall" + strconv.FormatInt(message.OwnerID, 10) + "_" + strconv.FormatUint(message.ID, 10)
+
+	author, ok := authors[message.OwnerID]
+	if !ok {
+		author = link
+	}
+
+	return &entity.Post{
+		ID:          strconv.FormatUint(message.ID, 10),
+		Author:      author,
+		Date:        time.Unix(message.Date, 0).UTC(),
+		Text:        message.Text,
+		Link:        link,
+		Attachments: vkAttachments(message.Attachments),
+	}
+}
+
+// vkAuthors - names by owner id, groups have negative owner id.
+func vkAuthors(result entity.VkResult) map[int64]string {
+	authors := make(map[int64]string, len(result.Profiles)+len(result.Groups))
+
+	for _, profile := range result.Profiles {
+		authors[profile.ID] = strings.TrimSpace(profile.FirstName + " " + profile.LastName)
+	}
+
+	for _, group := range result.Groups {
+		authors[-group.ID] = group.Name
+	}
+
+	return authors
+}
+
+func vkAttachments(vkAttachments []entity.VkAttachment) []entity.Attachment {
+	var attachments []entity.Attachment
+
+	for i := range vkAttachments {
+		vkAttachment := &vkAttachments[i]
+
+		switch {
+		case vkAttachment.Photo != nil:
+			if url := vkPhotoURL(vkAttachment.Photo); url != "" {
+				attachments = append(attachments, entity.Attachment{
+					Type: entity.AttachmentPhoto, URL: url, Title: vkAttachment.Photo.Text,
+				})
+			}
+		case vkAttachment.Video != nil:
+			video := vkAttachment.Video
+			attachments = append(attachments, entity.Attachment{
+				Type:  entity.AttachmentVideo,
+				URL:   "https://vk.com/video" + strconv.FormatInt(video.OwnerID, 10) + "_" + strconv.FormatUint(video.ID, 10),
+				Title: video.Title,
+			})
+		case vkAttachment.Doc != nil:
+			attachments = append(attachments, entity.Attachment{
+				Type: entity.AttachmentDoc, URL: vkAttachment.Doc.URL, Title: vkAttachment.Doc.Title,
+			})
+		case vkAttachment.Link != nil:
+			attachments = append(attachments, entity.Attachment{
+				Type: entity.AttachmentLink, URL: vkAttachment.Link.URL, Title: vkAttachment.Link.Title,
+			})
+		}
+	}
+
+	return attachments
+}
+
+// vkPhotoURL - url of the biggest size.
+func vkPhotoURL(photo *entity.VkPhoto) string {
+	var (
+		url  string
+		area int
+	)
+
+	for _, size := range photo.Sizes {
+		if size.Width*size.Height >= area {
+			url, area = size.URL, size.Width*size.Height
+		}
+	}
 
-	return response.VkResult, err
+	return url
 }
@@ -1,13 +1,21 @@
 package service_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jokius/news-telegram-bot/internal/entity"
 	"github.com/jokius/news-telegram-bot/internal/usecase/service"
+	"github.com/jokius/news-telegram-bot/pkg/errors"
 	"github.com/jokius/news-telegram-bot/pkg/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func sourceVk(t *testing.T) (*service.VkSource, *mocks.MockInterfaceClient) {
@@ -16,7 +24,7 @@ func sourceVk(t *testing.T) (*service.VkSource, *mocks.MockInterfaceClient) {
 	mockCtl := gomock.NewController(t)
 	client := mocks.NewMockInterfaceClient(mockCtl)
 
-	newSource := service.NewVkSource("token", client)
+	newSource := service.NewVkSource("token", time.Millisecond, 2, client)
 
 	return newSource, client
 }
@@ -33,6 +41,53 @@ func TestName(t *testing.T) {
 	})
 }
 
+func TestVkGroupName(t *testing.T) {
+	t.Parallel()
+
+	source, _ := sourceVk(t)
+
+	t.Run("group urls", func(t *testing.T) {
+		t.Parallel()
+
+		for rawURL, group := range map[string]string{
+			"https://vk.com/test_group":             "test_group",
+			"https://vk.com/Test_Group":             "test_group",
+			"https://m.vk.com/test.group?from=feed": "test.group",
+			"https://vk.com/club1":                  "club1",
+			"https://vk.ru/public1/":                "public1",
+			"https://vk.com/Club1":                  "club1",
+		} {
+			u, err := neturl.Parse(rawURL)
+			require.ErrorIs(t, err, nil)
+
+			name, ok := source.GroupName(u)
+			assert.True(t, ok, rawURL)
+			assert.Equal(t, group, name)
+		}
+	})
+
+	t.Run("not group urls", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rawURL := range []string{
+			"https://vk.com/wall-1_2",
+			"https://vk.com/photo-1_2",
+			"https://vk.com/video1_2",
+			"https://vk.com/id1",
+			"https://vk.com/feed",
+			"https://vk.com/away.php?to=https://example.com",
+			"https://vk.com/",
+			"https://example.com/test_group",
+		} {
+			u, err := neturl.Parse(rawURL)
+			require.ErrorIs(t, err, nil)
+
+			_, ok := source.GroupName(u)
+			assert.False(t, ok, rawURL)
+		}
+	})
+}
+
 func TestGetGroupMessages(t *testing.T) {
 	t.Parallel()
 
@@ -43,9 +98,183 @@ func TestGetGroupMessages(t *testing.T) {
 
 		id := "test_id"
 		offset := 100
-		url := "https://api.vk.com/method/wall.get?v=5.131&count=100&access_token=token&domain=test_id&offset=100"
+		url := "https://api.vk.com/method/wall.get?v=5.131&count=100&extended=1&access_token=token&domain=test_id&offset=100"
 		client.EXPECT().GetJSON(url, &entity.VkResponse{}).Times(1)
-		_, err := source.GetGroupMessages(id, offset)
+		_, err := source.GetGroupMessages(context.Background(), id, offset)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func TestGetPosts(t *testing.T) {
+	t.Parallel()
+
+	source, client := sourceVk(t)
+
+	t.Run("get posts", func(t *testing.T) {
+		t.Parallel()
+
+		url := "https://api.vk.com/method/wall.get?v=5.131&count=100&extended=1&access_token=token&domain=test_group&offset=100"
+		client.EXPECT().GetJSON(url, &entity.VkResponse{}).
+			SetArg(1, entity.VkResponse{VkResult: entity.VkResult{Messages: []entity.VkMessage{
+				{ID: 2, OwnerID: -1, Date: 1637056800},
+			}}}).
+			Return(nil).
+			Times(1)
+
+		page, err := source.GetPosts(context.Background(), "test_group", "100")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "101", page.Next)
+		assert.Equal(t, []entity.Post{{
+			ID:     "2",
+			Seq:    2,
+			Author: "test_group",
+			Date:   time.Unix(1637056800, 0).UTC(),
+			Link:   "https://vk.com/test_group?w=wall-1_2",
+		}}, page.Posts)
+	})
+
+	t.Run("post content", func(t *testing.T) {
+		t.Parallel()
+
+		wall, err := os.ReadFile("testdata/vk/wall.json")
 		assert.ErrorIs(t, err, nil)
+
+		url := "https://api.vk.com/method/wall.get?v=5.131&count=100&extended=1&access_token=token&domain=test_group&offset=0"
+		client.EXPECT().GetJSON(url, &entity.VkResponse{}).
+			DoAndReturn(func(_ string, target interface{}) error { return json.Unmarshal(wall, target) }).
+			Times(1)
+
+		page, err := source.GetPosts(context.Background(), "test_group", "")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "2", page.Next)
+		assert.Equal(t, 2, len(page.Posts))
+
+		post := page.Posts[0]
+		assert.Equal(t, uint64(12), post.Seq)
+		assert.True(t, post.Pinned)
+		assert.False(t, page.Posts[1].Pinned)
+		assert.Equal(t, "Тестовая группа", post.Author)
+		assert.Equal(t, "Новость от [club1|Тестовой группы] & <друзей>", post.Text)
+		assert.Equal(t, []entity.Attachment{
+			{Type: entity.AttachmentPhoto, URL: "https://sun.userapi.com/x.jpg"},
+			{Type: entity.AttachmentLink, URL: "https://example.com/article", Title: "Статья"},
+			{Type: entity.AttachmentVideo, URL: "https://vk.com/video-1_200", Title: "Видео дня"},
+			{Type: entity.AttachmentDoc, URL: "https://vk.com/doc5_300", Title: "report.pdf"},
+		}, post.Attachments)
+		assert.Nil(t, post.Repost)
+
+		repost := page.Posts[1].Repost
+		assert.Equal(t, "Иван Петров", repost.Author)
+		assert.Equal(t, "https://vk.com/wall5_7", repost.Link)
+		assert.Equal(t, "Мой репост", repost.Text)
+		assert.Equal(t, "Другая группа", repost.Repost.Author)
+		assert.Equal(t, "Оригинал", repost.Repost.Text)
+		assert.Nil(t, repost.Repost.Repost)
+	})
+
+	t.Run("empty page", func(t *testing.T) {
+		t.Parallel()
+
+		url := "https://api.vk.com/method/wall.get?v=5.131&count=100&extended=1&access_token=token&domain=empty_group&offset=0"
+		client.EXPECT().GetJSON(url, &entity.VkResponse{}).Return(nil).Times(1)
+
+		page, err := source.GetPosts(context.Background(), "empty_group", "")
+		assert.ErrorIs(t, err, nil)
+		assert.Empty(t, page.Next)
+		assert.Empty(t, page.Posts)
+	})
+}
+
+// vkFixture - response of the client read from the fixture.
+func vkFixture(t *testing.T, name string) func(string, interface{}) error {
+	t.Helper()
+
+	body, err := os.ReadFile("testdata/vk/" + name)
+	require.ErrorIs(t, err, nil)
+
+	return func(_ string, target interface{}) error { return json.Unmarshal(body, target) }
+}
+
+func TestVkErrors(t *testing.T) {
+	t.Parallel()
+
+	url := "https://api.vk.com/method/wall.get?v=5.131&count=100&extended=1&access_token=token&domain=test_group&offset=0"
+
+	for code, tc := range map[int]struct {
+		requests    int
+		flood       bool
+		auth        bool
+		unavailable bool
+	}{
+		errors.VkAuthFailed:      {requests: 1, auth: true},
+		errors.VkTooManyRequests: {requests: 3, flood: true},
+		errors.VkFloodControl:    {requests: 3, flood: true},
+		errors.VkAccessDenied:    {requests: 1, unavailable: true},
+		errors.VkPageDeleted:     {requests: 1, unavailable: true},
+		errors.VkContentBlocked:  {requests: 1, unavailable: true},
+		errors.VkPrivateProfile:  {requests: 1, unavailable: true},
+	} {
+		code, tc := code, tc
+
+		t.Run(fmt.Sprintf("error %d", code), func(t *testing.T) {
+			t.Parallel()
+
+			source, client := sourceVk(t)
+			client.EXPECT().GetJSON(url, &entity.VkResponse{}).
+				DoAndReturn(vkFixture(t, fmt.Sprintf("error_%d.json", code))).
+				Times(tc.requests)
+
+			page, err := source.GetPosts(context.Background(), "test_group", "")
+			assert.Empty(t, page.Posts)
+
+			var vkErr *errors.VkError
+
+			require.ErrorAs(t, err, &vkErr)
+			assert.Equal(t, code, vkErr.Code)
+			assert.NotEmpty(t, vkErr.Message)
+			assert.Equal(t, tc.flood, errors.IsVkFlood(err))
+			assert.Equal(t, tc.auth, errors.IsVkAuth(err))
+			assert.Equal(t, tc.unavailable, errors.Is(err, errors.ErrGroupUnavailable))
+		})
+	}
+
+	t.Run("retry after flood", func(t *testing.T) {
+		t.Parallel()
+
+		source, client := sourceVk(t)
+		gomock.InOrder(
+			client.EXPECT().GetJSON(url, &entity.VkResponse{}).DoAndReturn(vkFixture(t, "error_6.json")),
+			client.EXPECT().GetJSON(url, &entity.VkResponse{}).DoAndReturn(vkFixture(t, "wall.json")),
+		)
+
+		page, err := source.GetPosts(context.Background(), "test_group", "")
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, 2, len(page.Posts))
+	})
+	t.Run("stop waiting for flood control when ctx is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		mockCtl := gomock.NewController(t)
+		client := mocks.NewMockInterfaceClient(mockCtl)
+		source := service.NewVkSource("token", time.Hour, 2, client)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		client.EXPECT().GetJSON(url, &entity.VkResponse{}).DoAndReturn(vkFixture(t, "error_6.json")).Times(1)
+
+		done := make(chan error, 1)
+
+		go func() {
+			_, err := source.GetPosts(ctx, "test_group", "")
+			done <- err
+		}()
+
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("flood control wait ignores ctx")
+		}
 	})
 }
@@ -12,110 +12,577 @@ import (
 
 // UserUseCase -.
 type UserUseCase struct {
-	repo   UserRepo
-	msg    Messenger
-	source Source
+	repo      UserRepo
+	msg       Messenger
+	sources   Sources
+	languages []string
+	router    *commandRouter
 }
 
 const (
-	commandWithParams = 2
+	muteDuration = 24 * time.Hour
 )
 
-// NewUserUseCase - init.
-func NewUserUseCase(r UserRepo, m Messenger, s Source) *UserUseCase {
-	return &UserUseCase{r, m, s}
+// NewUserUseCase - init, languages are available for /lang, commands with @username suffix of other bots
+// are ignored.
+func NewUserUseCase(r UserRepo, m Messenger, s Sources, languages []string, botUsername string) *UserUseCase {
+	uc := &UserUseCase{repo: r, msg: m, sources: s, languages: languages}
+	uc.router = newCommandRouter(botUsername,
+		&command{name: "start", handler: uc.start},
+		&command{name: "help", withArgs: true, handler: uc.help},
+		&command{name: "add_url", aliases: []string{"add"}, withArgs: true, minArgs: 1, handler: uc.addURL},
+		&command{name: "del_group", aliases: []string{"del", "remove"}, withArgs: true, minArgs: 1, handler: uc.removeGroup},
+		&command{name: "list", aliases: []string{"groups"}, handler: uc.groupList},
+		&command{name: "pause", withArgs: true, minArgs: 1, handler: uc.pause},
+		&command{name: "resume", withArgs: true, minArgs: 1, handler: uc.resume},
+		&command{name: "mute", withArgs: true, minArgs: 2, handler: uc.mute},
+		&command{name: "interval", withArgs: true, minArgs: 2, handler: uc.checkInterval},
+		&command{name: "start_date", withArgs: true, minArgs: 1, handler: uc.startDate},
+		&command{name: "filter", withArgs: true, minArgs: 1, handler: uc.filter},
+		&command{name: "digest", withArgs: true, handler: uc.digest},
+		&command{name: "quiet", withArgs: true, handler: uc.quiet},
+		&command{name: "lang", aliases: []string{"language"}, withArgs: true, handler: uc.language},
+	)
+
+	return uc
+}
+
+// RegisterCommands - publish command list to telegram menu in every language,
+// users of other languages get the default one.
+func (uc *UserUseCase) RegisterCommands() error {
+	for _, lang := range append([]string{""}, uc.languages...) {
+		if err := uc.msg.SetCommands(uc.router.botCommands(), lang); err != nil {
+			return fmt.Errorf("`uc.RegisterCommands` %q something wrong: %w", lang, err)
+		}
+	}
+
+	return nil
 }
 
 // TelegramCallback - parse telegram callback.
 func (uc *UserUseCase) TelegramCallback(telegramResult entity.TelegramResult) (err error) {
 	message := telegramResult.Message
-	user := message.User
+	from := message.User
+
+	query := telegramResult.CallbackQuery
+	if query != nil {
+		from = query.User
+	}
 
-	if user.IsBot {
+	if from.IsBot {
 		return fmt.Errorf("%w", errors.ErrBotMessage)
 	}
 
-	id := user.ID
-	text := message.Text
-	textSlice := strings.Split(text, " ")
+	// user who blocked the bot writes again, the user is loaded once for handlers and replies
+	user, err := uc.repo.Activate(from.ID, from.LanguageCode)
+	if err != nil {
+		return fmt.Errorf("`uc.TelegramCallback` something wrong: %w", err)
+	}
+
+	if query != nil {
+		uc.callbackQuery(&user, query)
+
+		return
+	}
+
+	c, args, ok := uc.router.find(message)
 
 	switch {
-	case text == "/list":
-		uc.groupList(id)
-	case len(textSlice) >= commandWithParams:
-		uc.messageWithParams(textSlice, id)
+	case !ok:
+		return
+	case c == nil:
+		uc.msg.UnknownCommand(&user)
+	case len(args) < c.minArgs:
+		uc.msg.IncorrectFormat(&user, c.botCommand())
 	default:
-		uc.msg.IncorrectFormat(id, textSlice[0])
+		c.handler(&user, args)
 	}
 
 	return
 }
 
-func (uc *UserUseCase) groupList(id uint64) {
-	groups, err := uc.repo.Groups(id)
-	if err != nil {
-		uc.msg.UnknownError(id, "`uc.groupList` something wrong: "+err.Error())
+func (uc *UserUseCase) start(user *entity.User, _ []string) {
+	uc.msg.Welcome(user, uc.router.botCommands())
+}
+
+// help - all commands or usage of the command from argument.
+func (uc *UserUseCase) help(user *entity.User, args []string) {
+	if len(args) == 0 {
+		uc.msg.Help(user, uc.router.botCommands())
 
 		return
 	}
 
-	list := make([]string, len(groups))
-	for i := range groups {
-		list[i] = groups[i].Name
+	c, ok := uc.router.names[strings.ToLower(strings.TrimPrefix(args[0], "/"))]
+	if !ok {
+		uc.msg.UnknownCommand(user)
+
+		return
+	}
+
+	uc.msg.Help(user, []entity.BotCommand{c.botCommand()})
+}
+
+// language - /lang without known language shows the current one, "auto" resets it to telegram language.
+func (uc *UserUseCase) language(user *entity.User, args []string) {
+	if len(args) > 0 {
+		language := strings.ToLower(args[0])
+		if language == "auto" {
+			language = ""
+		}
+
+		if language == "" || uc.supports(language) {
+			if err := uc.repo.SetLanguage(user.TelegramID, language); err != nil {
+				uc.errBD(user, err)
+
+				return
+			}
+
+			user.Language = language
+			uc.msg.LanguageUpdated(user)
+
+			return
+		}
 	}
 
-	uc.msg.GroupList(id, list)
+	uc.msg.LanguageList(user, user.PreferredLanguage(), uc.languages)
 }
 
-func (uc *UserUseCase) messageWithParams(textSlice []string, id uint64) {
-	text := textSlice[1]
+// digest - /digest without arguments shows the current mode, "off" is the same as "instant".
+// Daily digest keeps the previous timezone when it is omitted.
+func (uc *UserUseCase) digest(user *entity.User, args []string) {
+	if len(args) == 0 {
+		uc.msg.DigestSettings(user)
 
-	switch textSlice[0] {
-	case "/add_url":
-		uc.addURL(id, text)
-	case "/start_date":
-		uc.startDate(id, text)
-	case "/del_group":
-		uc.removeGroup(id, text)
+		return
+	}
+
+	pending := user.NextDigestAt != nil
+
+	switch mode := strings.ToLower(args[0]); mode {
+	case entity.DigestInstant, "off":
+		user.DigestMode = entity.DigestInstant
+		user.DigestAt = ""
+	case entity.DigestHourly:
+		user.DigestMode = entity.DigestHourly
+		user.DigestAt = ""
+	case entity.DigestDaily:
+		if !uc.setDailyDigest(user, args[1:]) {
+			return
+		}
 	default:
-		uc.msg.IncorrectFormat(id, "unknown")
+		uc.msg.IncorrectFormat(user, uc.router.names["digest"].botCommand())
+
+		return
+	}
+
+	t := time.Now().UTC()
+	user.NextDigestAt = user.NextDigest(t)
+
+	// collected posts are sent right away after switching to instant mode
+	if pending && !user.Digest() {
+		user.NextDigestAt = &t
+	}
+
+	if err := uc.repo.UpdateDigest(user); err != nil {
+		uc.errBD(user, err)
+
+		return
+	}
+
+	uc.msg.DigestUpdated(user)
+}
+
+// setDailyDigest - time and optional timezone of daily digest, user gets a reply about wrong format.
+func (uc *UserUseCase) setDailyDigest(user *entity.User, args []string) bool {
+	if len(args) == 0 {
+		uc.msg.IncorrectFormat(user, uc.router.names["digest"].botCommand())
+
+		return false
+	}
+
+	at, err := entity.ParseDigestAt(args[0])
+	if err != nil {
+		uc.msg.IncorrectFormat(user, uc.router.names["digest"].botCommand())
+
+		return false
+	}
+
+	if len(args) > 1 {
+		timezone, err := entity.ParseTimezone(args[1])
+		if err != nil {
+			uc.msg.IncorrectTimezone(user, args[1])
+
+			return false
+		}
+
+		user.Timezone = timezone
+	}
+
+	user.DigestMode = entity.DigestDaily
+	user.DigestAt = at
+
+	return true
+}
+
+// quiet - /quiet without arguments shows the current quiet hours, "off" removes them.
+// Mode and timezone are optional and keep previous values when omitted.
+func (uc *UserUseCase) quiet(user *entity.User, args []string) {
+	if len(args) == 0 {
+		uc.msg.QuietSettings(user)
+
+		return
+	}
+
+	if strings.ToLower(args[0]) == "off" {
+		user.QuietFrom, user.QuietTo = "", ""
+	} else if !uc.setQuietHours(user, args) {
+		return
+	}
+
+	if err := uc.repo.UpdateQuiet(user); err != nil {
+		uc.errBD(user, err)
+
+		return
+	}
+
+	uc.msg.QuietUpdated(user)
+}
+
+// setQuietHours - window, mode and timezone of quiet hours, user gets a reply about wrong format.
+func (uc *UserUseCase) setQuietHours(user *entity.User, args []string) bool {
+	from, to, err := entity.ParseQuietHours(args[0])
+	if err != nil {
+		uc.msg.IncorrectFormat(user, uc.router.names["quiet"].botCommand())
+
+		return false
+	}
+
+	for _, arg := range args[1:] {
+		switch mode := strings.ToLower(arg); mode {
+		case entity.QuietHold, entity.QuietSilent:
+			user.QuietMode = mode
+		default:
+			timezone, err := entity.ParseTimezone(arg)
+			if err != nil {
+				uc.msg.IncorrectTimezone(user, arg)
+
+				return false
+			}
+
+			user.Timezone = timezone
+		}
+	}
+
+	user.QuietFrom, user.QuietTo = from, to
+
+	return true
+}
+
+func (uc *UserUseCase) supports(language string) bool {
+	for _, l := range uc.languages {
+		if l == language {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (uc *UserUseCase) groupList(user *entity.User, _ []string) {
+	subscriptions, err := uc.repo.Subscriptions(user.TelegramID)
+	if err != nil {
+		uc.msg.UnknownError(user, "`uc.groupList` something wrong: "+err.Error())
+
+		return
+	}
+
+	uc.msg.SubscriptionList(user, subscriptions, 0)
+}
+
+// callbackQuery - press of button under subscription list, the list message is updated after the action.
+// The callback is always answered, failed action is answered with error text.
+func (uc *UserUseCase) callbackQuery(user *entity.User, query *entity.TelegramCallbackQuery) {
+	data, err := entity.ParseCallbackData(query.Data)
+	if err != nil {
+		uc.msg.SubscriptionNotFound(user, query.ID)
+
+		return
+	}
+
+	var subscription entity.Subscription
+
+	if data.Action != entity.CallbackList {
+		subscription, err = uc.repo.Subscription(user.TelegramID, data.SubscriptionID)
+		if err != nil {
+			uc.msg.SubscriptionNotFound(user, query.ID)
+
+			return
+		}
+
+		if err = uc.updateSubscription(&subscription, data.Action); err != nil {
+			uc.msg.CallbackFailed(user, query.ID)
+			uc.errBD(user, err)
+
+			return
+		}
+	}
+
+	uc.msg.SubscriptionUpdated(user, query.ID, data.Action, &subscription)
+
+	// the callback is answered already, only the list isn't updated
+	subscriptions, err := uc.repo.Subscriptions(user.TelegramID)
+	if err != nil {
+		uc.errBD(user, err)
+
+		return
 	}
+
+	uc.msg.EditSubscriptionList(user, query.Message.MessageID, subscriptions, data.Page)
 }
 
-func (uc *UserUseCase) addURL(id uint64, text string) {
-	err := uc.repo.AddGroupByURL(id, uc.source.Name(), text)
+func (uc *UserUseCase) updateSubscription(subscription *entity.Subscription, action string) error {
+	switch action {
+	case entity.CallbackRemove:
+		return uc.repo.RemoveSubscription(subscription)
+	case entity.CallbackPause:
+		if subscription.Paused {
+			subscription.Paused = false
+			_, err := uc.repo.ResumeSubscription(subscription, true)
+
+			return err
+		}
+
+		subscription.Paused = true
+	case entity.CallbackMute:
+		if t := time.Now().UTC(); subscription.Muted(t) {
+			subscription.MutedUntil = nil
+		} else {
+			mutedUntil := t.Add(muteDuration)
+			subscription.MutedUntil = &mutedUntil
+		}
+	}
+
+	return uc.repo.UpdateSubscription(subscription)
+}
+
+func (uc *UserUseCase) addURL(user *entity.User, args []string) {
+	text := args[0]
+	source, name, err := uc.sources.Find(text)
+	if err != nil {
+		uc.msg.UnknownSource(user, text)
+
+		return
+	}
+
+	err = uc.repo.AddGroup(user.TelegramID, source.Name(), name)
 	if err == nil {
-		uc.msg.URLAdded(id)
+		uc.msg.URLAdded(user)
 	} else {
-		uc.errBD(id, err)
+		uc.errBD(user, err)
 	}
 }
 
-func (uc *UserUseCase) removeGroup(id uint64, text string) {
-	err := uc.repo.RemoveGroup(id, uc.source.Name(), text)
+func (uc *UserUseCase) removeGroup(user *entity.User, args []string) {
+	text := args[0]
+	source, name, err := uc.sources.Find(text)
+	if err != nil {
+		uc.msg.UnknownSource(user, text)
+
+		return
+	}
+
+	err = uc.repo.RemoveGroup(user.TelegramID, source.Name(), name)
 	if err == nil {
-		uc.msg.RemovedGroup(id)
+		uc.msg.RemovedGroup(user)
 	} else {
-		uc.errBD(id, err)
+		uc.errBD(user, err)
+	}
+}
+
+// pause - stop delivery of the group, its posts are held until resume.
+func (uc *UserUseCase) pause(user *entity.User, args []string) {
+	subscription, ok := uc.findSubscription(user, args[0])
+	if !ok {
+		return
+	}
+
+	subscription.Paused = true
+	if err := uc.repo.UpdateSubscription(&subscription); err != nil {
+		uc.errBD(user, err)
+
+		return
+	}
+
+	uc.msg.SubscriptionPaused(user, &subscription)
+}
+
+// resume - unpause and unmute the group, posts held during the pause are sent unless "skip" is chosen.
+func (uc *UserUseCase) resume(user *entity.User, args []string) {
+	catchUp := true
+
+	if len(args) > 1 {
+		switch strings.ToLower(args[1]) {
+		case "skip":
+			catchUp = false
+		case "all":
+		default:
+			uc.msg.IncorrectFormat(user, uc.router.names["resume"].botCommand())
+
+			return
+		}
+	}
+
+	subscription, ok := uc.findSubscription(user, args[0])
+	if !ok {
+		return
+	}
+
+	subscription.Paused = false
+	subscription.MutedUntil = nil
+
+	missed, err := uc.repo.ResumeSubscription(&subscription, catchUp)
+	if err != nil {
+		uc.errBD(user, err)
+
+		return
+	}
+
+	uc.msg.SubscriptionResumed(user, &subscription, missed, catchUp)
+}
+
+// mute - skip posts of the group for the duration like 2d.
+func (uc *UserUseCase) mute(user *entity.User, args []string) {
+	duration, err := entity.ParseMuteDuration(strings.ToLower(args[1]))
+	if err != nil {
+		uc.msg.IncorrectFormat(user, uc.router.names["mute"].botCommand())
+
+		return
+	}
+
+	subscription, ok := uc.findSubscription(user, args[0])
+	if !ok {
+		return
+	}
+
+	mutedUntil := time.Now().UTC().Add(duration)
+	subscription.MutedUntil = &mutedUntil
+
+	if err = uc.repo.UpdateSubscription(&subscription); err != nil {
+		uc.errBD(user, err)
+
+		return
+	}
+
+	uc.msg.SubscriptionMuted(user, &subscription)
+}
+
+// checkInterval - check the group every duration like 15m instead of its adaptive schedule, "auto" returns it.
+func (uc *UserUseCase) checkInterval(user *entity.User, args []string) {
+	var interval time.Duration
+
+	if arg := strings.ToLower(args[1]); arg != "auto" {
+		var err error
+
+		interval, err = entity.ParseInterval(arg)
+		if err != nil {
+			uc.msg.IncorrectFormat(user, uc.router.names["interval"].botCommand())
+
+			return
+		}
+	}
+
+	subscription, ok := uc.findSubscription(user, args[0])
+	if !ok {
+		return
+	}
+
+	subscription.CheckInterval = int64(interval / time.Second)
+
+	if err := uc.repo.UpdateCheckInterval(&subscription); err != nil {
+		uc.errBD(user, err)
+
+		return
+	}
+
+	uc.msg.CheckIntervalUpdated(user, &subscription)
+}
+
+// findSubscription - subscription to the group by link, user gets a reply when there is no such subscription.
+func (uc *UserUseCase) findSubscription(user *entity.User, text string) (entity.Subscription, bool) {
+	source, name, err := uc.sources.Find(text)
+	if err != nil {
+		uc.msg.UnknownSource(user, text)
+
+		return entity.Subscription{}, false
+	}
+
+	subscription, err := uc.repo.FindSubscription(user.TelegramID, source.Name(), name)
+
+	switch {
+	case errors.Is(err, errors.ErrNotSubscribed):
+		uc.msg.NotSubscribed(user, text)
+	case err != nil:
+		uc.errBD(user, err)
+	default:
+		return subscription, true
+	}
+
+	return entity.Subscription{}, false
+}
+
+// filter - replace filters of subscription, command without filters removes them.
+func (uc *UserUseCase) filter(user *entity.User, args []string) {
+	text := args[0]
+
+	source, name, err := uc.sources.Find(text)
+	if err != nil {
+		uc.msg.UnknownSource(user, text)
+
+		return
+	}
+
+	filters := make(entity.Filters, 0, len(args)-1)
+
+	for _, filterText := range args[1:] {
+		filter, err := entity.ParseFilter(filterText)
+		if err != nil {
+			uc.msg.IncorrectFilter(user, filterText)
+
+			return
+		}
+
+		filters = append(filters, filter)
+	}
+
+	err = uc.repo.SetFilters(user.TelegramID, source.Name(), name, filters)
+
+	switch {
+	case errors.Is(err, errors.ErrNotSubscribed):
+		uc.msg.NotSubscribed(user, text)
+	case err != nil:
+		uc.errBD(user, err)
+	default:
+		uc.msg.FiltersUpdated(user, filters)
 	}
 }
 
-func (uc *UserUseCase) startDate(id uint64, text string) {
-	t, err := time.Parse("02.01.2006", text)
+func (uc *UserUseCase) startDate(user *entity.User, args []string) {
+	t, err := time.Parse("02.01.2006", args[0])
 	if err != nil {
-		uc.msg.IncorrectFormat(id, "start_date")
+		uc.msg.IncorrectFormat(user, uc.router.names["start_date"].botCommand())
 
 		return
 	}
 
-	err = uc.repo.UpdateStartDate(id, t)
+	err = uc.repo.UpdateStartDate(user.TelegramID, t)
 	if err == nil {
-		uc.msg.StartDateUpdated(id)
+		uc.msg.StartDateUpdated(user)
 	} else {
-		uc.errBD(id, err)
+		uc.errBD(user, err)
 	}
 }
 
-func (uc *UserUseCase) errBD(id uint64, err error) {
-	uc.msg.UnknownError(id, "`uc.errBD` something wrong: "+err.Error())
+func (uc *UserUseCase) errBD(user *entity.User, err error) {
+	uc.msg.UnknownError(user, "`uc.errBD` something wrong: "+err.Error())
 }
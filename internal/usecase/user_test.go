@@ -1,6 +1,8 @@
 package usecase_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,9 +15,12 @@ import (
 	"gorm.io/gorm"
 )
 
+var languages = []string{"en", "ru"}
+
 const (
-	userID   uint64 = 1
-	timeText        = "10.11.2021"
+	userID    uint64 = 1
+	messageID uint64 = 10
+	timeText         = "10.11.2021"
 )
 
 func telegramResult(text string) entity.TelegramResult {
@@ -30,31 +35,78 @@ func telegramResult(text string) entity.TelegramResult {
 	}
 }
 
-func user(t *testing.T) (*usecase.UserUseCase, *mocks.MockMessenger, *mocks.MockUserRepo, *mocks.MockSource) {
+// botCommand - command with arguments, descriptions are catalog keys.
+func botCommand(name string, aliases ...string) entity.BotCommand {
+	slashed := make([]string, len(aliases))
+	for i, alias := range aliases {
+		slashed[i] = "/" + alias
+	}
+
+	return entity.BotCommand{
+		Command:     name,
+		Description: "command." + name,
+		Args:        "command." + name + ".args",
+		Aliases:     slashed,
+	}
+}
+
+// recipient - matches reply to the user with telegram id.
+type recipient uint64
+
+func (r recipient) Matches(x interface{}) bool {
+	user, ok := x.(*entity.User)
+
+	return ok && user.TelegramID == uint64(r)
+}
+
+func (r recipient) String() string {
+	return fmt.Sprintf("is user %d", uint64(r))
+}
+
+func user(t *testing.T) (*usecase.UserUseCase, *mocks.MockMessenger, *mocks.MockUserRepo, *mocks.MockSources) {
+	t.Helper()
+
+	return userWith(t, entity.User{TelegramID: userID, Active: true})
+}
+
+// userWith - use case for updates from the current user.
+func userWith(t *testing.T, current entity.User) (*usecase.UserUseCase, *mocks.MockMessenger, *mocks.MockUserRepo,
+	*mocks.MockSources) {
 	t.Helper()
 
 	mockCtl := gomock.NewController(t)
 	repo := mocks.NewMockUserRepo(mockCtl)
 	messenger := mocks.NewMockMessenger(mockCtl)
-	source := mocks.NewMockSource(mockCtl)
+	sources := mocks.NewMockSources(mockCtl)
+
+	repo.EXPECT().Activate(userID, "").Return(current, nil).AnyTimes()
+
+	newUser := usecase.NewUserUseCase(repo, messenger, sources, languages, "NewsBot")
+
+	return newUser, messenger, repo, sources
+}
+
+func vkSource(t *testing.T) *mocks.MockSource {
+	t.Helper()
 
-	newUser := usecase.NewUserUseCase(repo, messenger, source)
+	source := mocks.NewMockSource(gomock.NewController(t))
+	source.EXPECT().Name().Return("vk").AnyTimes()
 
-	return newUser, messenger, repo, source
+	return source
 }
 
 func TestTelegramCallback_correct(t *testing.T) {
 	t.Parallel()
 
-	userCase, message, repo, source := user(t)
+	userCase, message, repo, sources := user(t)
 
 	t.Run("when add_url", func(t *testing.T) {
 		t.Parallel()
 
-		source.EXPECT().Name().Return("vk").Times(1)
-		repo.EXPECT().AddGroupByURL(userID, "vk", "https://example.com/1").Return(nil).Times(1)
-		message.EXPECT().URLAdded(userID).Return().Times(1)
-		err := userCase.TelegramCallback(telegramResult("/add_url https://example.com/1"))
+		sources.EXPECT().Find("https://vk.com/add").Return(vkSource(t), "add", nil).Times(1)
+		repo.EXPECT().AddGroup(userID, "vk", "add").Return(nil).Times(1)
+		message.EXPECT().URLAdded(recipient(userID)).Return().Times(1)
+		err := userCase.TelegramCallback(telegramResult("/add_url https://vk.com/add"))
 		require.ErrorIs(t, err, nil)
 	})
 
@@ -65,7 +117,7 @@ func TestTelegramCallback_correct(t *testing.T) {
 		require.ErrorIs(t, err, nil)
 
 		repo.EXPECT().UpdateStartDate(userID, timeParse).Return(nil).Times(1)
-		message.EXPECT().StartDateUpdated(userID).Return().Times(1)
+		message.EXPECT().StartDateUpdated(recipient(userID)).Return().Times(1)
 		err = userCase.TelegramCallback(telegramResult("/start_date " + timeText))
 		require.ErrorIs(t, err, nil)
 	})
@@ -73,20 +125,19 @@ func TestTelegramCallback_correct(t *testing.T) {
 	t.Run("when del_group", func(t *testing.T) {
 		t.Parallel()
 
-		source.EXPECT().Name().Return("vk").Times(1)
-		repo.EXPECT().RemoveGroup(userID, "vk", "https://example.com/1").Return(nil).Times(1)
-		message.EXPECT().RemovedGroup(userID).Return().Times(1)
-		err := userCase.TelegramCallback(telegramResult("/del_group https://example.com/1"))
+		sources.EXPECT().Find("https://vk.com/del").Return(vkSource(t), "del", nil).Times(1)
+		repo.EXPECT().RemoveGroup(userID, "vk", "del").Return(nil).Times(1)
+		message.EXPECT().RemovedGroup(recipient(userID)).Return().Times(1)
+		err := userCase.TelegramCallback(telegramResult("/del_group https://vk.com/del"))
 		require.ErrorIs(t, err, nil)
 	})
 
 	t.Run("when list", func(t *testing.T) {
 		t.Parallel()
 
-		listStr := []string{"1"}
-		listGroups := []entity.Group{{Name: "1"}}
-		repo.EXPECT().Groups(userID).Return(listGroups, nil).Times(1)
-		message.EXPECT().GroupList(userID, listStr).Times(1)
+		subscriptions := []entity.Subscription{{ID: 1, Feed: entity.Feed{Name: "1"}}}
+		repo.EXPECT().Subscriptions(userID).Return(subscriptions, nil).Times(1)
+		message.EXPECT().SubscriptionList(recipient(userID), subscriptions, 0).Times(1)
 		err := userCase.TelegramCallback(telegramResult("/list"))
 		require.ErrorIs(t, err, nil)
 	})
@@ -96,15 +147,15 @@ func TestTelegramCallback_with_db_error(t *testing.T) {
 	t.Parallel()
 
 	errBD := gorm.ErrInvalidValue
-	userCase, message, repo, source := user(t)
+	userCase, message, repo, sources := user(t)
 
 	t.Run("when add_url", func(t *testing.T) {
 		t.Parallel()
 
-		source.EXPECT().Name().Return("vk").Times(1)
-		repo.EXPECT().AddGroupByURL(userID, "vk", "https://example.com/1").Return(errBD).Times(1) // any error
-		message.EXPECT().UnknownError(userID, "`uc.errBD` something wrong: "+errBD.Error()).Return().Times(1)
-		err := userCase.TelegramCallback(telegramResult("/add_url https://example.com/1"))
+		sources.EXPECT().Find("https://vk.com/add").Return(vkSource(t), "add", nil).Times(1)
+		repo.EXPECT().AddGroup(userID, "vk", "add").Return(errBD).Times(1) // any error
+		message.EXPECT().UnknownError(recipient(userID), "`uc.errBD` something wrong: "+errBD.Error()).Return().Times(1)
+		err := userCase.TelegramCallback(telegramResult("/add_url https://vk.com/add"))
 		require.ErrorIs(t, err, nil)
 	})
 
@@ -115,7 +166,7 @@ func TestTelegramCallback_with_db_error(t *testing.T) {
 		require.ErrorIs(t, err, nil)
 
 		repo.EXPECT().UpdateStartDate(userID, timeParse).Return(errBD).Times(1)
-		message.EXPECT().UnknownError(userID, "`uc.errBD` something wrong: "+errBD.Error()).Return().Times(1)
+		message.EXPECT().UnknownError(recipient(userID), "`uc.errBD` something wrong: "+errBD.Error()).Return().Times(1)
 		err = userCase.TelegramCallback(telegramResult("/start_date " + timeText))
 		require.ErrorIs(t, err, nil)
 	})
@@ -123,23 +174,678 @@ func TestTelegramCallback_with_db_error(t *testing.T) {
 	t.Run("when del_group", func(t *testing.T) {
 		t.Parallel()
 
-		source.EXPECT().Name().Return("vk").Times(1)
-		repo.EXPECT().RemoveGroup(userID, "vk", "https://example.com/1").Return(errBD).Times(1) // any error
-		message.EXPECT().UnknownError(userID, "`uc.errBD` something wrong: "+errBD.Error()).Return().Times(1)
-		err := userCase.TelegramCallback(telegramResult("/del_group https://example.com/1"))
+		sources.EXPECT().Find("https://vk.com/del").Return(vkSource(t), "del", nil).Times(1)
+		repo.EXPECT().RemoveGroup(userID, "vk", "del").Return(errBD).Times(1) // any error
+		message.EXPECT().UnknownError(recipient(userID), "`uc.errBD` something wrong: "+errBD.Error()).Return().Times(1)
+		err := userCase.TelegramCallback(telegramResult("/del_group https://vk.com/del"))
 		require.ErrorIs(t, err, nil)
 	})
 
 	t.Run("when list", func(t *testing.T) {
 		t.Parallel()
 
-		repo.EXPECT().Groups(userID).Return([]entity.Group{}, errBD).Times(1) // any error
-		message.EXPECT().UnknownError(userID, "`uc.groupList` something wrong: "+errBD.Error()).Return().Times(1)
+		repo.EXPECT().Subscriptions(userID).Return(nil, errBD).Times(1) // any error
+		message.EXPECT().UnknownError(recipient(userID), "`uc.groupList` something wrong: "+errBD.Error()).Return().Times(1)
 		err := userCase.TelegramCallback(telegramResult("/list"))
 		require.ErrorIs(t, err, nil)
 	})
 }
 
+func TestTelegramCallback_router(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when start", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().Welcome(recipient(userID), gomock.Any()).DoAndReturn(func(_ *entity.User,
+			commands []entity.BotCommand) {
+			require.Equal(t, "start", commands[0].Command)
+		}).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/start"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when help", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().Help(recipient(userID), gomock.Any()).DoAndReturn(func(_ *entity.User,
+			commands []entity.BotCommand) {
+			names := make([]string, len(commands))
+			for i := range commands {
+				names[i] = commands[i].Command
+			}
+
+			require.Equal(t, []string{"start", "help", "add_url", "del_group", "list", "pause", "resume", "mute", "interval",
+				"start_date", "filter", "digest", "quiet", "lang"}, names)
+		}).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/help"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when help of command alias", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().Help(recipient(userID), []entity.BotCommand{botCommand("del_group", "del", "remove")}).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/help /remove"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when help of unknown command", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().UnknownCommand(recipient(userID)).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/help unknown"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when command with bot name and extra spaces", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/add").Return(vkSource(t), "add", nil).Times(1)
+		repo.EXPECT().AddGroup(userID, "vk", "add").Return(nil).Times(1)
+		message.EXPECT().URLAdded(recipient(userID)).Times(1)
+
+		result := telegramResult("/add_url@NewsBot   https://vk.com/add  ")
+		result.Message.Entities = []entity.TelegramMessageEntity{{Type: "bot_command", Offset: 0, Length: 16}}
+		err := userCase.TelegramCallback(result)
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when bot name in other case", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().Subscriptions(userID).Return(nil, nil).Times(1)
+		message.EXPECT().SubscriptionList(recipient(userID), nil, 0).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/list@newsbot"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when command of other bot", func(t *testing.T) {
+		t.Parallel()
+
+		// no reply and no action
+		userCase, _, _, _ := user(t)
+		err := userCase.TelegramCallback(telegramResult("/list@OtherBot"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when alias", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().Subscriptions(userID).Return(nil, nil).Times(1)
+		message.EXPECT().SubscriptionList(recipient(userID), nil, 0).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/groups"))
+		require.ErrorIs(t, err, nil)
+	})
+}
+
+func TestTelegramCallback_filter(t *testing.T) {
+	t.Parallel()
+
+	word, err := entity.ParseFilter("go")
+	require.ErrorIs(t, err, nil)
+
+	ads, err := entity.ParseFilter("-/реклама|ad/")
+	require.ErrorIs(t, err, nil)
+
+	t.Run("when filters", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		filters := entity.Filters{word, ads}
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().SetFilters(userID, "vk", "group", filters).Return(nil).Times(1)
+		message.EXPECT().FiltersUpdated(recipient(userID), filters).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/filter https://vk.com/group +Go -/реклама|ad/"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when filters are removed", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().SetFilters(userID, "vk", "group", entity.Filters{}).Return(nil).Times(1)
+		message.EXPECT().FiltersUpdated(recipient(userID), entity.Filters{}).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/filter https://vk.com/group"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when filter is incorrect", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		message.EXPECT().IncorrectFilter(recipient(userID), "-/(/").Times(1)
+		err := userCase.TelegramCallback(telegramResult("/filter https://vk.com/group go -/(/"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when not subscribed", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().SetFilters(userID, "vk", "group", entity.Filters{word}).Return(errors.ErrNotSubscribed).Times(1)
+		message.EXPECT().NotSubscribed(recipient(userID), "https://vk.com/group").Times(1)
+		err := userCase.TelegramCallback(telegramResult("/filter https://vk.com/group go"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when unknown source", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, sources := user(t)
+		sources.EXPECT().Find("https://example.com").Return(nil, "", errors.ErrUnknownSource).Times(1)
+		message.EXPECT().UnknownSource(recipient(userID), "https://example.com").Times(1)
+		err := userCase.TelegramCallback(telegramResult("/filter https://example.com go"))
+		require.ErrorIs(t, err, nil)
+	})
+}
+
+func TestTelegramCallback_language(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when telegram language", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().Activate(userID, "en-US").Return(entity.User{TelegramID: userID, LanguageCode: "en-US"}, nil).
+			Times(1)
+		message.EXPECT().Welcome(recipient(userID), gomock.Any()).Times(1)
+
+		result := telegramResult("/start")
+		result.Message.User.LanguageCode = "en-US"
+		err := userCase.TelegramCallback(result)
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when lang", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := userWith(t, entity.User{TelegramID: userID, Language: "en"})
+		message.EXPECT().LanguageList(recipient(userID), "en", languages).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/lang"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when lang is chosen", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().SetLanguage(userID, "en").Return(nil).Times(1)
+		message.EXPECT().LanguageUpdated(recipient(userID)).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/lang EN"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when lang is reset", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().SetLanguage(userID, "").Return(nil).Times(1)
+		message.EXPECT().LanguageUpdated(recipient(userID)).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/lang auto"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when lang is unknown", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().LanguageList(recipient(userID), "", languages).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/lang de"))
+		require.ErrorIs(t, err, nil)
+	})
+}
+
+func TestTelegramCallback_digest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when digest", func(t *testing.T) {
+		t.Parallel()
+
+		current := entity.User{TelegramID: userID, DigestMode: entity.DigestInstant}
+		userCase, message, _, _ := userWith(t, current)
+		message.EXPECT().DigestSettings(&current).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/digest"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when daily digest", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := userWith(t, entity.User{TelegramID: userID, Timezone: "UTC"})
+		repo.EXPECT().UpdateDigest(gomock.Any()).DoAndReturn(func(updated *entity.User) error {
+			require.Equal(t, entity.DigestDaily, updated.DigestMode)
+			require.Equal(t, "08:30", updated.DigestAt)
+			require.Equal(t, "Europe/Moscow", updated.Timezone)
+			require.NotNil(t, updated.NextDigestAt)
+
+			return nil
+		}).Times(1)
+		message.EXPECT().DigestUpdated(recipient(userID)).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/digest daily 08:30 Europe/Moscow"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when hourly digest", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := userWith(t, entity.User{TelegramID: userID})
+		repo.EXPECT().UpdateDigest(gomock.Any()).DoAndReturn(func(updated *entity.User) error {
+			require.Equal(t, entity.DigestHourly, updated.DigestMode)
+			require.True(t, updated.NextDigestAt.After(time.Now()))
+			require.Zero(t, updated.NextDigestAt.Minute())
+
+			return nil
+		}).Times(1)
+		message.EXPECT().DigestUpdated(recipient(userID)).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/digest Hourly"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when digest is off", func(t *testing.T) {
+		t.Parallel()
+
+		next := time.Now().Add(time.Hour)
+		userCase, message, repo, _ := userWith(t, entity.User{TelegramID: userID, DigestMode: entity.DigestHourly,
+			NextDigestAt: &next})
+		repo.EXPECT().UpdateDigest(gomock.Any()).DoAndReturn(func(updated *entity.User) error {
+			require.Equal(t, entity.DigestInstant, updated.DigestMode)
+			require.True(t, updated.NextDigestAt.Before(next), "collected posts are sent now")
+
+			return nil
+		}).Times(1)
+		message.EXPECT().DigestUpdated(recipient(userID)).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/digest off"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when incorrect time", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := userWith(t, entity.User{TelegramID: userID})
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("digest")).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/digest daily 25:00"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when incorrect timezone", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := userWith(t, entity.User{TelegramID: userID})
+		message.EXPECT().IncorrectTimezone(recipient(userID), "Mars/Olympus").Times(1)
+		err := userCase.TelegramCallback(telegramResult("/digest daily 08:00 Mars/Olympus"))
+		require.ErrorIs(t, err, nil)
+	})
+}
+
+func TestTelegramCallback_quiet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when quiet", func(t *testing.T) {
+		t.Parallel()
+
+		current := entity.User{TelegramID: userID, QuietMode: entity.QuietHold}
+		userCase, message, _, _ := userWith(t, current)
+		message.EXPECT().QuietSettings(&current).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/quiet"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when quiet hours", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := userWith(t, entity.User{TelegramID: userID, Timezone: "UTC",
+			QuietMode: entity.QuietHold})
+		expected := entity.User{TelegramID: userID, Timezone: "Europe/Moscow", QuietFrom: "23:00", QuietTo: "08:00",
+			QuietMode: entity.QuietSilent}
+		repo.EXPECT().UpdateQuiet(&expected).Return(nil).Times(1)
+		message.EXPECT().QuietUpdated(&expected).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/quiet 23:00-08:00 Silent Europe/Moscow"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when quiet hours are off", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := userWith(t, entity.User{TelegramID: userID, QuietFrom: "23:00", QuietTo: "08:00"})
+		expected := entity.User{TelegramID: userID}
+		repo.EXPECT().UpdateQuiet(&expected).Return(nil).Times(1)
+		message.EXPECT().QuietUpdated(&expected).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/quiet off"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when incorrect hours", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := userWith(t, entity.User{TelegramID: userID})
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("quiet")).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/quiet 23:00"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when incorrect timezone", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := userWith(t, entity.User{TelegramID: userID})
+		message.EXPECT().IncorrectTimezone(recipient(userID), "loud").Times(1)
+		err := userCase.TelegramCallback(telegramResult("/quiet 23:00-08:00 loud"))
+		require.ErrorIs(t, err, nil)
+	})
+}
+
+func TestTelegramCallback_pause(t *testing.T) {
+	t.Parallel()
+
+	subscription := entity.Subscription{ID: 2, Feed: entity.Feed{Name: "group"}}
+
+	t.Run("when pause", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		paused := subscription
+		paused.Paused = true
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().FindSubscription(userID, "vk", "group").Return(subscription, nil).Times(1)
+		repo.EXPECT().UpdateSubscription(&paused).Return(nil).Times(1)
+		message.EXPECT().SubscriptionPaused(recipient(userID), &paused).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/pause https://vk.com/group"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when resume", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		mutedUntil := time.Now().Add(time.Hour)
+		current := subscription
+		current.Paused = true
+		current.MutedUntil = &mutedUntil
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().FindSubscription(userID, "vk", "group").Return(current, nil).Times(1)
+		repo.EXPECT().ResumeSubscription(&subscription, true).Return(int64(2), nil).Times(1)
+		message.EXPECT().SubscriptionResumed(recipient(userID), &subscription, int64(2), true).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/resume https://vk.com/group"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when resume skips missed posts", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().FindSubscription(userID, "vk", "group").Return(subscription, nil).Times(1)
+		repo.EXPECT().ResumeSubscription(&subscription, false).Return(int64(2), nil).Times(1)
+		message.EXPECT().SubscriptionResumed(recipient(userID), &subscription, int64(2), false).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/resume https://vk.com/group SKIP"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when resume with unknown choice", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("resume")).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/resume https://vk.com/group later"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when mute", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().FindSubscription(userID, "vk", "group").Return(subscription, nil).Times(1)
+		repo.EXPECT().UpdateSubscription(gomock.Any()).DoAndReturn(func(muted *entity.Subscription) error {
+			require.True(t, muted.Muted(time.Now().Add(47*time.Hour)))
+			require.False(t, muted.Muted(time.Now().Add(49*time.Hour)))
+
+			return nil
+		}).Times(1)
+		message.EXPECT().SubscriptionMuted(recipient(userID), gomock.Any()).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/mute https://vk.com/group 2D"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when mute with incorrect duration", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("mute")).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/mute https://vk.com/group forever"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when interval", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().FindSubscription(userID, "vk", "group").Return(subscription, nil).Times(1)
+		repo.EXPECT().UpdateCheckInterval(gomock.Any()).DoAndReturn(func(updated *entity.Subscription) error {
+			require.Equal(t, int64(15*60), updated.CheckInterval)
+
+			return nil
+		}).Times(1)
+		message.EXPECT().CheckIntervalUpdated(recipient(userID), gomock.Any()).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/interval https://vk.com/group 15M"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when interval auto", func(t *testing.T) {
+		t.Parallel()
+
+		current := subscription
+		current.CheckInterval = 900
+
+		userCase, message, repo, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().FindSubscription(userID, "vk", "group").Return(current, nil).Times(1)
+		repo.EXPECT().UpdateCheckInterval(gomock.Any()).DoAndReturn(func(updated *entity.Subscription) error {
+			require.Zero(t, updated.CheckInterval)
+
+			return nil
+		}).Times(1)
+		message.EXPECT().CheckIntervalUpdated(recipient(userID), gomock.Any()).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/interval https://vk.com/group auto"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when interval is incorrect", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("interval")).Times(1)
+		err := userCase.TelegramCallback(telegramResult("/interval https://vk.com/group 30d"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when not subscribed", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, sources := user(t)
+		sources.EXPECT().Find("https://vk.com/group").Return(vkSource(t), "group", nil).Times(1)
+		repo.EXPECT().FindSubscription(userID, "vk", "group").Return(entity.Subscription{}, errors.ErrNotSubscribed)
+		message.EXPECT().NotSubscribed(recipient(userID), "https://vk.com/group").Times(1)
+		err := userCase.TelegramCallback(telegramResult("/pause https://vk.com/group"))
+		require.ErrorIs(t, err, nil)
+	})
+}
+
+func TestRegisterCommands(t *testing.T) {
+	t.Parallel()
+
+	userCase, message, _, _ := user(t)
+	for _, lang := range []string{"", "en", "ru"} {
+		message.EXPECT().SetCommands(gomock.Len(14), lang).Return(nil).Times(1)
+	}
+
+	err := userCase.RegisterCommands()
+	require.ErrorIs(t, err, nil)
+}
+
+func callbackQuery(data string) entity.TelegramResult {
+	return entity.TelegramResult{
+		CallbackQuery: &entity.TelegramCallbackQuery{
+			ID:      "query",
+			User:    entity.TelegramUser{ID: userID},
+			Message: entity.TelegramMessage{MessageID: messageID},
+			Data:    data,
+		},
+	}
+}
+
+func TestTelegramCallback_callback_query(t *testing.T) {
+	t.Parallel()
+
+	subscriptions := []entity.Subscription{{ID: 1, Feed: entity.Feed{Name: "1"}}}
+	errBD := gorm.ErrInvalidValue
+
+	t.Run("when page", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		message.EXPECT().SubscriptionUpdated(recipient(userID), "query", entity.CallbackList, &entity.Subscription{}).Times(1)
+		repo.EXPECT().Subscriptions(userID).Return(subscriptions, nil).Times(1)
+		message.EXPECT().EditSubscriptionList(recipient(userID), messageID, subscriptions, 1).Times(1)
+		err := userCase.TelegramCallback(callbackQuery("l:0:1"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when remove", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		subscription := entity.Subscription{ID: 2}
+		repo.EXPECT().Subscription(userID, uint64(2)).Return(subscription, nil).Times(1)
+		repo.EXPECT().RemoveSubscription(&subscription).Return(nil).Times(1)
+		message.EXPECT().SubscriptionUpdated(recipient(userID), "query", entity.CallbackRemove, &subscription).Times(1)
+		repo.EXPECT().Subscriptions(userID).Return(subscriptions, nil).Times(1)
+		message.EXPECT().EditSubscriptionList(recipient(userID), messageID, subscriptions, 0).Times(1)
+		err := userCase.TelegramCallback(callbackQuery("r:2:0"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when pause", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().Subscription(userID, uint64(2)).Return(entity.Subscription{ID: 2}, nil).Times(1)
+		repo.EXPECT().UpdateSubscription(&entity.Subscription{ID: 2, Paused: true}).Return(nil).Times(1)
+		message.EXPECT().SubscriptionUpdated(recipient(userID), "query", entity.CallbackPause, gomock.Any()).Times(1)
+		repo.EXPECT().Subscriptions(userID).Return(subscriptions, nil).Times(1)
+		message.EXPECT().EditSubscriptionList(recipient(userID), messageID, subscriptions, 0).Times(1)
+		err := userCase.TelegramCallback(callbackQuery("p:2:0"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when resume catches up", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().Subscription(userID, uint64(2)).Return(entity.Subscription{ID: 2, Paused: true}, nil).Times(1)
+		repo.EXPECT().ResumeSubscription(&entity.Subscription{ID: 2}, true).Return(int64(3), nil).Times(1)
+		message.EXPECT().SubscriptionUpdated(recipient(userID), "query", entity.CallbackPause, gomock.Any()).Times(1)
+		repo.EXPECT().Subscriptions(userID).Return(subscriptions, nil).Times(1)
+		message.EXPECT().EditSubscriptionList(recipient(userID), messageID, subscriptions, 0).Times(1)
+		err := userCase.TelegramCallback(callbackQuery("p:2:0"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when mute", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().Subscription(userID, uint64(2)).Return(entity.Subscription{ID: 2}, nil).Times(1)
+		repo.EXPECT().UpdateSubscription(gomock.Any()).DoAndReturn(func(subscription *entity.Subscription) error {
+			require.NotNil(t, subscription.MutedUntil)
+			require.True(t, subscription.Muted(time.Now().Add(23*time.Hour)))
+
+			return nil
+		}).Times(1)
+		message.EXPECT().SubscriptionUpdated(recipient(userID), "query", entity.CallbackMute, gomock.Any()).Times(1)
+		repo.EXPECT().Subscriptions(userID).Return(subscriptions, nil).Times(1)
+		message.EXPECT().EditSubscriptionList(recipient(userID), messageID, subscriptions, 0).Times(1)
+		err := userCase.TelegramCallback(callbackQuery("m:2:0"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when unmute", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		mutedUntil := time.Now().Add(time.Hour)
+		repo.EXPECT().Subscription(userID, uint64(2)).Return(entity.Subscription{ID: 2, MutedUntil: &mutedUntil}, nil)
+		repo.EXPECT().UpdateSubscription(&entity.Subscription{ID: 2}).Return(nil).Times(1)
+		message.EXPECT().SubscriptionUpdated(recipient(userID), "query", entity.CallbackMute, gomock.Any()).Times(1)
+		repo.EXPECT().Subscriptions(userID).Return(subscriptions, nil).Times(1)
+		message.EXPECT().EditSubscriptionList(recipient(userID), messageID, subscriptions, 0).Times(1)
+		err := userCase.TelegramCallback(callbackQuery("m:2:0"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when update fails", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().Subscription(userID, uint64(2)).Return(entity.Subscription{ID: 2}, nil).Times(1)
+		repo.EXPECT().UpdateSubscription(gomock.Any()).Return(errBD).Times(1)
+		message.EXPECT().CallbackFailed(recipient(userID), "query").Times(1)
+		message.EXPECT().UnknownError(recipient(userID), "`uc.errBD` something wrong: "+errBD.Error()).Times(1)
+		err := userCase.TelegramCallback(callbackQuery("p:2:0"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when list fails", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		message.EXPECT().SubscriptionUpdated(recipient(userID), "query", entity.CallbackList, &entity.Subscription{}).Times(1)
+		repo.EXPECT().Subscriptions(userID).Return(nil, errBD).Times(1)
+		message.EXPECT().UnknownError(recipient(userID), "`uc.errBD` something wrong: "+errBD.Error()).Times(1)
+		err := userCase.TelegramCallback(callbackQuery("l:0:1"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when subscription of other user", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, repo, _ := user(t)
+		repo.EXPECT().Subscription(userID, uint64(3)).Return(entity.Subscription{}, gorm.ErrRecordNotFound).Times(1)
+		message.EXPECT().SubscriptionNotFound(recipient(userID), "query").Times(1)
+		err := userCase.TelegramCallback(callbackQuery("r:3:0"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when wrong data", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().SubscriptionNotFound(recipient(userID), "query").Times(1)
+		err := userCase.TelegramCallback(callbackQuery("x:1:0"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when data is too long", func(t *testing.T) {
+		t.Parallel()
+
+		userCase, message, _, _ := user(t)
+		message.EXPECT().SubscriptionNotFound(recipient(userID), "query").Times(1)
+		err := userCase.TelegramCallback(callbackQuery("r:1:" + strings.Repeat("0", entity.CallbackDataLimit)))
+		require.ErrorIs(t, err, nil)
+	})
+}
+
 func TestTelegramCallback_with_error_noParams(t *testing.T) {
 	t.Parallel()
 
@@ -148,7 +854,7 @@ func TestTelegramCallback_with_error_noParams(t *testing.T) {
 	t.Run("when add_url", func(t *testing.T) {
 		t.Parallel()
 
-		message.EXPECT().IncorrectFormat(userID, "/add_url").Return().Times(1)
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("add_url", "add")).Return().Times(1)
 		err := userCase.TelegramCallback(telegramResult("/add_url"))
 		require.ErrorIs(t, err, nil)
 	})
@@ -156,7 +862,7 @@ func TestTelegramCallback_with_error_noParams(t *testing.T) {
 	t.Run("when start_date", func(t *testing.T) {
 		t.Parallel()
 
-		message.EXPECT().IncorrectFormat(userID, "/start_date").Return().Times(1)
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("start_date")).Return().Times(1)
 		err := userCase.TelegramCallback(telegramResult("/start_date"))
 		require.ErrorIs(t, err, nil)
 	})
@@ -164,12 +870,36 @@ func TestTelegramCallback_with_error_noParams(t *testing.T) {
 	t.Run("when del_group", func(t *testing.T) {
 		t.Parallel()
 
-		message.EXPECT().IncorrectFormat(userID, "/del_group").Return().Times(1)
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("del_group", "del", "remove")).Return().Times(1)
 		err := userCase.TelegramCallback(telegramResult("/del_group"))
 		require.ErrorIs(t, err, nil)
 	})
 }
 
+func TestTelegramCallback_with_unknown_source(t *testing.T) {
+	t.Parallel()
+
+	userCase, message, _, sources := user(t)
+
+	t.Run("when add_url", func(t *testing.T) {
+		t.Parallel()
+
+		sources.EXPECT().Find("https://example.com/1").Return(nil, "", errors.ErrUnknownSource).Times(1)
+		message.EXPECT().UnknownSource(recipient(userID), "https://example.com/1").Return().Times(1)
+		err := userCase.TelegramCallback(telegramResult("/add_url https://example.com/1"))
+		require.ErrorIs(t, err, nil)
+	})
+
+	t.Run("when del_group", func(t *testing.T) {
+		t.Parallel()
+
+		sources.EXPECT().Find("https://example.com/2").Return(nil, "", errors.ErrUnknownSource).Times(1)
+		message.EXPECT().UnknownSource(recipient(userID), "https://example.com/2").Return().Times(1)
+		err := userCase.TelegramCallback(telegramResult("/del_group https://example.com/2"))
+		require.ErrorIs(t, err, nil)
+	})
+}
+
 func TestTelegramCallback_with_error_other(t *testing.T) {
 	t.Parallel()
 
@@ -187,7 +917,7 @@ func TestTelegramCallback_with_error_other(t *testing.T) {
 	t.Run("when start_date", func(t *testing.T) {
 		t.Parallel()
 
-		message.EXPECT().IncorrectFormat(userID, "start_date").Return().Times(1)
+		message.EXPECT().IncorrectFormat(recipient(userID), botCommand("start_date")).Return().Times(1)
 		err := userCase.TelegramCallback(telegramResult("/start_date no_time"))
 		require.ErrorIs(t, err, nil)
 	})
@@ -195,8 +925,16 @@ func TestTelegramCallback_with_error_other(t *testing.T) {
 	t.Run("when incorrect_command params", func(t *testing.T) {
 		t.Parallel()
 
-		message.EXPECT().IncorrectFormat(userID, "unknown").Return().Times(1)
+		message.EXPECT().UnknownCommand(recipient(userID)).Return().Times(1)
 		err := userCase.TelegramCallback(telegramResult("/incorrect_command 123"))
 		require.ErrorIs(t, err, nil)
 	})
+
+	t.Run("when text without command", func(t *testing.T) {
+		t.Parallel()
+
+		message.EXPECT().UnknownCommand(recipient(userID)).Return().Times(1)
+		err := userCase.TelegramCallback(telegramResult("hello"))
+		require.ErrorIs(t, err, nil)
+	})
 }
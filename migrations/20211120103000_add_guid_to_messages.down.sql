@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS messages_group_id_guid_index;
+ALTER TABLE messages DROP COLUMN IF EXISTS guid;
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table messages
+    add guid varchar default '' not null;
+
+create index messages_group_id_guid_index ON messages (group_id, guid);
@@ -0,0 +1 @@
+ALTER TABLE messages ALTER COLUMN message_id DROP DEFAULT;
\ No newline at end of file
@@ -0,0 +1,6 @@
+update messages
+set guid = message_id::varchar
+where guid = '';
+
+alter table messages
+    alter column message_id set default 0;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "deliveries";
\ No newline at end of file
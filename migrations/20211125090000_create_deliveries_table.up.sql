@@ -0,0 +1,16 @@
+create table deliveries
+(
+    id bigserial
+        constraint delivery_pk
+            primary key,
+    chat_id bigint not null,
+    text text not null,
+    status varchar not null,
+    attempts integer default 0 not null,
+    next_attempt_at timestamp not null,
+    last_error text default '' not null,
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create index deliveries_status_next_attempt_at_index ON deliveries (status, next_attempt_at);
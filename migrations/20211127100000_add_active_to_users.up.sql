@@ -0,0 +1,2 @@
+alter table users
+    add active boolean default true not null;
@@ -0,0 +1,44 @@
+create table groups
+(
+    id bigserial
+        constraint group_pk
+            primary key,
+    user_id bigint not null,
+    source_name varchar not null,
+    name varchar not null,
+    last_update_at timestamp not null,
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create index groups_user_id_index ON groups (user_id);
+
+insert into groups (user_id, source_name, name, last_update_at, created_at, updated_at)
+select s.user_id, f.source_name, f.name, s.start_at, s.created_at, s.updated_at
+from subscriptions s
+         join feeds f on f.id = s.feed_id;
+
+alter table messages
+    add group_id bigint;
+
+insert into messages (group_id, feed_id, message_id, guid, source, message_at, created_at, updated_at)
+select g.id, m.feed_id, m.message_id, m.guid, m.source, m.message_at, m.created_at, m.updated_at
+from messages m
+         join feeds f on f.id = m.feed_id
+         join groups g on g.source_name = f.source_name and g.name = f.name;
+
+delete from messages where group_id is null;
+
+alter table messages
+    alter column group_id set not null;
+
+drop index if exists messages_feed_id_guid_index;
+
+alter table messages
+    drop column feed_id;
+
+create index messages_group_id_index ON messages (group_id);
+create index messages_group_id_guid_index ON messages (group_id, guid);
+
+drop table subscriptions;
+drop table feeds;
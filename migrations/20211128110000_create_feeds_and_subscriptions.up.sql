@@ -0,0 +1,73 @@
+create table feeds
+(
+    id bigserial
+        constraint feed_pk
+            primary key,
+    source_name varchar not null,
+    name varchar not null,
+    last_update_at timestamp not null,
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create unique index feeds_source_name_name_uindex
+    on feeds (source_name, name);
+
+create table subscriptions
+(
+    id bigserial
+        constraint subscription_pk
+            primary key,
+    user_id bigint not null,
+    feed_id bigint not null,
+    start_at timestamp not null,
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+
+create unique index subscriptions_user_id_feed_id_uindex
+    on subscriptions (user_id, feed_id);
+
+create index subscriptions_feed_id_index ON subscriptions (feed_id);
+
+insert into feeds (source_name, name, last_update_at, created_at, updated_at)
+select source_name, name, max(last_update_at), min(created_at), max(updated_at)
+from groups
+group by source_name, name;
+
+insert into subscriptions (user_id, feed_id, start_at, created_at, updated_at)
+select distinct on (g.user_id, f.id) g.user_id, f.id, g.last_update_at, g.created_at, g.updated_at
+from groups g
+         join feeds f on f.source_name = g.source_name and f.name = g.name
+order by g.user_id, f.id, g.id;
+
+alter table messages
+    add feed_id bigint;
+
+update messages m
+set feed_id = f.id
+from groups g
+         join feeds f on f.source_name = g.source_name and f.name = g.name
+where m.group_id = g.id;
+
+delete from messages where feed_id is null;
+
+-- the same post was saved for every user of the group
+delete from messages m
+    using messages d
+where m.feed_id = d.feed_id
+  and m.guid = d.guid
+  and m.id > d.id;
+
+alter table messages
+    alter column feed_id set not null;
+
+drop index if exists messages_group_id_guid_index;
+drop index if exists messages_group_id_index;
+
+alter table messages
+    drop column group_id;
+
+create index messages_feed_id_guid_index ON messages (feed_id, guid);
+
+drop table groups;
@@ -0,0 +1 @@
+ALTER TABLE deliveries DROP COLUMN IF EXISTS parse_mode;
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table deliveries
+    add parse_mode varchar default '' not null;
@@ -0,0 +1 @@
+ALTER TABLE deliveries DROP COLUMN IF EXISTS media;
\ No newline at end of file
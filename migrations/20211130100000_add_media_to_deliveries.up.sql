@@ -0,0 +1,2 @@
+alter table deliveries
+    add media jsonb default '[]' not null;
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS muted_until;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS paused;
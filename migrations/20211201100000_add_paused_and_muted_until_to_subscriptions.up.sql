@@ -0,0 +1,5 @@
+alter table subscriptions
+    add paused boolean default false not null;
+
+alter table subscriptions
+    add muted_until timestamp;
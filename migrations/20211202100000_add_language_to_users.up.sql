@@ -0,0 +1,5 @@
+alter table users
+    add language_code varchar(35) default '' not null;
+
+alter table users
+    add language varchar(35) default '' not null;
@@ -0,0 +1,2 @@
+alter table subscriptions
+    add filters jsonb default '[]' not null;
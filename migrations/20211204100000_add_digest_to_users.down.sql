@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "digest_items";
+DROP INDEX IF EXISTS users_next_digest_at_index;
+ALTER TABLE users DROP COLUMN IF EXISTS next_digest_at;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_at;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_mode;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
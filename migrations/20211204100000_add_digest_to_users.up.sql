@@ -0,0 +1,28 @@
+alter table users
+    add timezone varchar default 'UTC' not null;
+
+alter table users
+    add digest_mode varchar default 'instant' not null;
+
+alter table users
+    add digest_at varchar(5) default '' not null;
+
+alter table users
+    add next_digest_at timestamp;
+
+create index users_next_digest_at_index ON users (next_digest_at);
+
+create table digest_items
+(
+    id bigserial
+        constraint digest_item_pk
+            primary key,
+    user_id bigint not null,
+    feed_id bigint not null,
+    title text not null,
+    link text not null,
+    post_at timestamp not null,
+    created_at timestamp not null
+);
+
+create index digest_items_user_id_index ON digest_items (user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE deliveries DROP COLUMN IF EXISTS disable_notification;
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_mode;
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_to;
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_from;
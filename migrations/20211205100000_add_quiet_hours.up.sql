@@ -0,0 +1,11 @@
+alter table users
+    add quiet_from varchar(5) default '' not null;
+
+alter table users
+    add quiet_to varchar(5) default '' not null;
+
+alter table users
+    add quiet_mode varchar default 'hold' not null;
+
+alter table deliveries
+    add disable_notification boolean default false not null;
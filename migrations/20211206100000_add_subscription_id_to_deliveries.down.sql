@@ -0,0 +1,3 @@
+DELETE FROM deliveries WHERE status = 'held';
+DROP INDEX IF EXISTS deliveries_subscription_id_status_index;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS subscription_id;
@@ -0,0 +1,4 @@
+alter table deliveries
+    add subscription_id bigint default 0 not null;
+
+create index deliveries_subscription_id_status_index ON deliveries (subscription_id, status);
@@ -0,0 +1,3 @@
+ALTER TABLE feeds DROP COLUMN IF EXISTS last_error_at;
+ALTER TABLE feeds DROP COLUMN IF EXISTS last_error;
+ALTER TABLE feeds DROP COLUMN IF EXISTS error_count;
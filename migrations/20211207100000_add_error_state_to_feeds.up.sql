@@ -0,0 +1,8 @@
+alter table feeds
+    add error_count integer default 0 not null;
+
+alter table feeds
+    add last_error text default '' not null;
+
+alter table feeds
+    add last_error_at timestamp;
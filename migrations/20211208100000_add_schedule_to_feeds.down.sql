@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS check_interval;
+DROP INDEX IF EXISTS feeds_source_name_next_check_at_index;
+ALTER TABLE feeds DROP COLUMN IF EXISTS next_check_at;
+ALTER TABLE feeds DROP COLUMN IF EXISTS post_interval;
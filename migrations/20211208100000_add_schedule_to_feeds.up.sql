@@ -0,0 +1,10 @@
+alter table feeds
+    add post_interval bigint default 0 not null;
+
+alter table feeds
+    add next_check_at timestamp default now() not null;
+
+create index feeds_source_name_next_check_at_index ON feeds (source_name, next_check_at);
+
+alter table subscriptions
+    add check_interval bigint default 0 not null;
@@ -0,0 +1,2 @@
+alter table feeds
+    add last_seq bigint default 0 not null;
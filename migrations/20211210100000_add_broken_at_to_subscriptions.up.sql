@@ -0,0 +1,2 @@
+alter table subscriptions
+    add broken_at timestamp;
@@ -0,0 +1,3 @@
+ALTER TABLE feeds DROP COLUMN IF EXISTS backlog_since;
+ALTER TABLE feeds DROP COLUMN IF EXISTS backlog_seq;
+ALTER TABLE feeds DROP COLUMN IF EXISTS backlog_cursor;
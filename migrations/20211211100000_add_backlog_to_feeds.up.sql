@@ -0,0 +1,8 @@
+alter table feeds
+    add backlog_cursor text default '' not null;
+
+alter table feeds
+    add backlog_seq bigint default 0 not null;
+
+alter table feeds
+    add backlog_since timestamp;
@@ -1,5 +1,141 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
 
-var ErrBotMessage = errors.New("message form bot")
+var (
+	ErrBotMessage       = errors.New("message form bot")
+	ErrUnknownSource    = errors.New("unknown source")
+	ErrShutdownTimeout  = errors.New("shutdown timeout")
+	ErrNotSubscribed    = errors.New("user is not subscribed")
+	ErrGroupUnavailable = errors.New("group is unavailable")
+)
+
+// Codes of vk api errors, https://dev.vk.com/reference/errors.
+const (
+	VkAuthFailed      = 5
+	VkTooManyRequests = 6
+	VkFloodControl    = 9
+	VkAccessDenied    = 15
+	VkPageDeleted     = 18
+	VkContentBlocked  = 19
+	VkPrivateProfile  = 30
+)
+
+// Is - errors.Is of standard library, for packages which use these errors instead of it.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// TelegramError - error response of telegram bot api.
+type TelegramError struct {
+	Code        int
+	Description string
+	RetryAfter  time.Duration
+}
+
+func NewTelegramError(code int, description string, retryAfter time.Duration) error {
+	return &TelegramError{Code: code, Description: description, RetryAfter: retryAfter}
+}
+
+func (e *TelegramError) Error() string {
+	return fmt.Sprintf("telegram error %d: %s", e.Code, e.Description)
+}
+
+// IsTelegramFatal - wrong token or conflict with other getUpdates/webhook, retry doesn't help.
+func IsTelegramFatal(err error) bool {
+	var telegramErr *TelegramError
+	if !errors.As(err, &telegramErr) {
+		return false
+	}
+
+	switch telegramErr.Code {
+	case http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTelegramBlocked - user blocked the bot or deleted the account, retry helps only after user writes again.
+func IsTelegramBlocked(err error) bool {
+	var telegramErr *TelegramError
+	if !errors.As(err, &telegramErr) || telegramErr.Code != http.StatusForbidden {
+		return false
+	}
+
+	description := strings.ToLower(telegramErr.Description)
+
+	return strings.Contains(description, "bot was blocked") || strings.Contains(description, "user is deactivated")
+}
+
+// TelegramRetryAfter - flood control delay of 429 Too Many Requests.
+func TelegramRetryAfter(err error) (time.Duration, bool) {
+	var telegramErr *TelegramError
+	if !errors.As(err, &telegramErr) || telegramErr.RetryAfter == 0 {
+		return 0, false
+	}
+
+	return telegramErr.RetryAfter, true
+}
+
+// IsTelegramBadRequest - 400 Bad Request, telegram rejected params of request, e.g. file by url.
+func IsTelegramBadRequest(err error) bool {
+	var telegramErr *TelegramError
+
+	return errors.As(err, &telegramErr) && telegramErr.Code == http.StatusBadRequest
+}
+
+// IsTelegramPermanent - request was rejected by telegram, retry with the same params doesn't help.
+func IsTelegramPermanent(err error) bool {
+	var telegramErr *TelegramError
+	if !errors.As(err, &telegramErr) {
+		return false
+	}
+
+	return telegramErr.Code >= http.StatusBadRequest && telegramErr.Code < http.StatusInternalServerError &&
+		telegramErr.Code != http.StatusTooManyRequests
+}
+
+// VkError - error object of vk api response.
+type VkError struct {
+	Code    int
+	Message string
+}
+
+func NewVkError(code int, message string) error {
+	return &VkError{Code: code, Message: message}
+}
+
+func (e *VkError) Error() string {
+	return fmt.Sprintf("vk error %d: %s", e.Code, e.Message)
+}
+
+// Unwrap - deleted, blocked or closed group is ErrGroupUnavailable like in other sources.
+func (e *VkError) Unwrap() error {
+	switch e.Code {
+	case VkAccessDenied, VkPageDeleted, VkContentBlocked, VkPrivateProfile:
+		return ErrGroupUnavailable
+	default:
+		return nil
+	}
+}
+
+// IsVkFlood - too many requests, retry after a pause helps.
+func IsVkFlood(err error) bool {
+	var vkErr *VkError
+
+	return errors.As(err, &vkErr) && (vkErr.Code == VkTooManyRequests || vkErr.Code == VkFloodControl)
+}
+
+// IsVkAuth - token is wrong or expired, retry doesn't help until it is changed.
+func IsVkAuth(err error) bool {
+	var vkErr *VkError
+
+	return errors.As(err, &vkErr) && vkErr.Code == VkAuthFailed
+}
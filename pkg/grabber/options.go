@@ -0,0 +1,15 @@
+package grabber
+
+import (
+	"time"
+)
+
+// Option -.
+type Option func(*Server)
+
+// ShutdownTimeout - time to wait for current cycles of grabbers. Default: 10 seconds.
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
@@ -1,33 +1,92 @@
 // Package grabber implements start and stop grabbers.
 package grabber
 
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+)
+
+const (
+	_defaultShutdownTimeout = 10 * time.Second
+)
+
+// Grabber - runs its cycles until ctx is canceled, Start returns after the last cycle is finished.
 type Grabber interface {
-	Start(chan bool)
+	Start(ctx context.Context)
 }
 
-// Server -.
+// Server - runs every grabber in own goroutine.
 type Server struct {
-	shutdown    chan bool
-	apiGrabbers []Grabber
+	apiGrabbers     []Grabber
+	shutdownTimeout time.Duration
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
 }
 
-// New -.
-func New(apiGrabbers []Grabber) *Server {
-	s := &Server{shutdown: make(chan bool, 1), apiGrabbers: apiGrabbers}
+// New - init and start grabbers.
+func New(apiGrabbers []Grabber, opts ...Option) *Server {
+	s := &Server{apiGrabbers: apiGrabbers, shutdownTimeout: _defaultShutdownTimeout}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(s)
+	}
+
 	s.start()
 
 	return s
 }
 
 func (s *Server) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, grabber := range s.apiGrabbers {
+		s.wg.Add(1)
+
+		go func(grabber Grabber) {
+			defer s.wg.Done()
+
+			grabber.Start(ctx)
+		}(grabber)
+	}
+}
+
+// Shutdown - stop all grabbers and wait for their current cycles.
+func (s *Server) Shutdown() error {
+	s.cancel()
+
+	done := make(chan struct{})
+
 	go func() {
-		for _, grabber := range s.apiGrabbers {
-			grabber.Start(s.shutdown)
-		}
+		s.wg.Wait()
+		close(done)
 	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(s.shutdownTimeout):
+		return errors.ErrShutdownTimeout
+	}
 }
 
-// Shutdown -.
-func (s *Server) Shutdown() {
-	s.shutdown <- true
+// Run - call cycle right away and then every interval until ctx is canceled. Cycles never overlap,
+// the next one starts not earlier than interval after the previous start or right after the long one.
+func Run(ctx context.Context, interval time.Duration, cycle func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		cycle(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
@@ -0,0 +1,98 @@
+package grabber_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/pkg/errors"
+	"github.com/jokius/news-telegram-bot/pkg/grabber"
+	"github.com/stretchr/testify/assert"
+)
+
+// testGrabber - runs cycles of the given duration and counts finished ones.
+type testGrabber struct {
+	cycle    time.Duration
+	started  chan struct{}
+	finished int32
+	stopped  int32
+}
+
+func newTestGrabber(cycle time.Duration) *testGrabber {
+	return &testGrabber{cycle: cycle, started: make(chan struct{}, 1)}
+}
+
+func (g *testGrabber) Start(ctx context.Context) {
+	grabber.Run(ctx, time.Millisecond, func(context.Context) {
+		select {
+		case g.started <- struct{}{}:
+		default:
+		}
+
+		time.Sleep(g.cycle)
+		atomic.AddInt32(&g.finished, 1)
+	})
+
+	atomic.StoreInt32(&g.stopped, 1)
+}
+
+// blockedGrabber - ignores ctx.
+type blockedGrabber struct{}
+
+func (blockedGrabber) Start(context.Context) {
+	time.Sleep(time.Second)
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("waits for current cycles of all grabbers", func(t *testing.T) {
+		t.Parallel()
+
+		grabbers := []*testGrabber{newTestGrabber(50 * time.Millisecond), newTestGrabber(50 * time.Millisecond)}
+		server := grabber.New([]grabber.Grabber{grabbers[0], grabbers[1]})
+
+		for _, g := range grabbers {
+			<-g.started
+		}
+
+		assert.ErrorIs(t, server.Shutdown(), nil)
+
+		for _, g := range grabbers {
+			assert.Equal(t, int32(1), atomic.LoadInt32(&g.stopped))
+			assert.Equal(t, int32(1), atomic.LoadInt32(&g.finished), "cycle isn't interrupted")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		server := grabber.New([]grabber.Grabber{blockedGrabber{}}, grabber.ShutdownTimeout(10*time.Millisecond))
+
+		assert.ErrorIs(t, server.Shutdown(), errors.ErrShutdownTimeout)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var running, overlaps, cycles int32
+
+	grabber.Run(ctx, time.Millisecond, func(context.Context) {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&cycles, 1)
+		atomic.AddInt32(&running, -1)
+	})
+
+	assert.Zero(t, overlaps)
+	assert.Greater(t, cycles, int32(1))
+	assert.Zero(t, running, "Run returns after the last cycle")
+}
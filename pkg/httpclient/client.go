@@ -16,6 +16,7 @@ type InterfaceClient interface {
 	Get(url string) (*http.Response, error)
 	GetJSON(url string, target interface{}) error
 	Post(url string, body []byte) (*http.Response, error)
+	PostContext(ctx context.Context, url string, body []byte) (*http.Response, error)
 }
 
 // Client - simple web client.
@@ -80,13 +81,17 @@ func (s *Client) GetJSON(url string, target interface{}) error {
 
 // Post - POST request with timeout.
 func (s *Client) Post(url string, body []byte) (*http.Response, error) {
-	ctx := context.Background()
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
-	req.Header.Add("Content-Type", "application/json")
+	return s.PostContext(context.Background(), url, body)
+}
 
+// PostContext - POST request with timeout, which can be canceled by context.
+func (s *Client) PostContext(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
 
+	req.Header.Add("Content-Type", "application/json")
+
 	return s.client.Do(req)
 }
@@ -0,0 +1,145 @@
+// Package i18n implements message catalogs with plural forms.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Translator - text of catalog key in language, args are formatted by fmt verbs of the text.
+type Translator interface {
+	Translate(lang, key string, args ...interface{}) string
+	Plural(lang, key string, count int, args ...interface{}) string
+}
+
+// Message - text of key, plural forms are used by Plural. Text of catalog file is a string
+// or an object with forms: {"one": "%d group", "other": "%d groups"}.
+type Message struct {
+	One   string `json:"one"`
+	Few   string `json:"few"`
+	Many  string `json:"many"`
+	Other string `json:"other"`
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = Message{Other: text}
+
+		return nil
+	}
+
+	type forms Message
+
+	return json.Unmarshal(data, (*forms)(m))
+}
+
+// Catalog - messages of one language by key.
+type Catalog map[string]Message
+
+func ParseCatalog(data []byte) (catalog Catalog, err error) {
+	if err = json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("i18n - ParseCatalog - json.Unmarshal: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// Bundle - catalogs of languages, missing language or key is taken from fallback language.
+type Bundle struct {
+	fallback string
+	catalogs map[string]Catalog
+}
+
+func New(fallback string, opts ...Option) *Bundle {
+	b := &Bundle{fallback: fallback, catalogs: make(map[string]Catalog)}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Languages - sorted languages with catalogs.
+func (b *Bundle) Languages() []string {
+	languages := make([]string, 0, len(b.catalogs))
+	for lang := range b.catalogs {
+		languages = append(languages, lang)
+	}
+
+	sort.Strings(languages)
+
+	return languages
+}
+
+// Supports - there is catalog for the language, region like en-US is ignored.
+func (b *Bundle) Supports(lang string) bool {
+	_, ok := b.catalogs[baseLanguage(lang)]
+
+	return ok
+}
+
+func (b *Bundle) Translate(lang, key string, args ...interface{}) string {
+	lang, message, ok := b.message(lang, key)
+	if !ok {
+		return key
+	}
+
+	return format(message.Other, args)
+}
+
+// Plural - form of text for count, count is the first argument of the text.
+func (b *Bundle) Plural(lang, key string, count int, args ...interface{}) string {
+	lang, message, ok := b.message(lang, key)
+	if !ok {
+		return key
+	}
+
+	var text string
+
+	switch pluralForm(lang, count) {
+	case formOne:
+		text = message.One
+	case formFew:
+		text = message.Few
+	case formMany:
+		text = message.Many
+	}
+
+	if text == "" {
+		text = message.Other
+	}
+
+	return format(text, append([]interface{}{count}, args...))
+}
+
+func (b *Bundle) message(lang, key string) (string, Message, bool) {
+	for _, l := range []string{baseLanguage(lang), b.fallback} {
+		if message, ok := b.catalogs[l][key]; ok {
+			return l, message, true
+		}
+	}
+
+	return "", Message{}, false
+}
+
+func format(text string, args []interface{}) string {
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// baseLanguage - language without region: en-US is en.
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	return lang
+}
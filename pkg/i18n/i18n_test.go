@@ -0,0 +1,69 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/jokius/news-telegram-bot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bundle(t *testing.T) *i18n.Bundle {
+	t.Helper()
+
+	ru, err := i18n.ParseCatalog([]byte(`{
+		"hello": "Привет, %s",
+		"groups": {"one": "%d группа", "few": "%d группы", "many": "%d групп"},
+		"only_ru": "только русский"
+	}`))
+	require.ErrorIs(t, err, nil)
+
+	en, err := i18n.ParseCatalog([]byte(`{
+		"hello": "Hello, %s",
+		"groups": {"one": "%d group", "other": "%d groups"}
+	}`))
+	require.ErrorIs(t, err, nil)
+
+	return i18n.New("ru", i18n.WithCatalog("ru", ru), i18n.WithCatalog("en", en))
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	b := bundle(t)
+
+	assert.Equal(t, "Hello, Ann", b.Translate("en", "hello", "Ann"))
+	assert.Equal(t, "Hello, Ann", b.Translate("en-US", "hello", "Ann"))
+	assert.Equal(t, "Привет, Ann", b.Translate("de", "hello", "Ann"))
+	assert.Equal(t, "Привет, Ann", b.Translate("", "hello", "Ann"))
+	assert.Equal(t, "только русский", b.Translate("en", "only_ru"))
+	assert.Equal(t, "missing", b.Translate("en", "missing"))
+	assert.True(t, b.Supports("EN-gb"))
+	assert.False(t, b.Supports("de"))
+	assert.Equal(t, []string{"en", "ru"}, b.Languages())
+}
+
+func TestPlural(t *testing.T) {
+	t.Parallel()
+
+	b := bundle(t)
+
+	for count, text := range map[int]string{
+		1: "1 группа", 2: "2 группы", 5: "5 групп", 11: "11 групп", 12: "12 групп", 21: "21 группа", 22: "22 группы",
+		0: "0 групп", 111: "111 групп",
+	} {
+		assert.Equal(t, text, b.Plural("ru", "groups", count))
+	}
+
+	assert.Equal(t, "1 group", b.Plural("en", "groups", 1))
+	assert.Equal(t, "0 groups", b.Plural("en", "groups", 0))
+	assert.Equal(t, "3 groups", b.Plural("en", "groups", 3))
+}
+
+func TestKeys(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "hello", i18n.Keys{}.Translate("en", "hello"))
+	assert.Equal(t, "list.page(2, 3)", i18n.Keys{}.Translate("en", "list.page", 2, 3))
+	assert.Equal(t, "groups(5)", i18n.Keys{}.Plural("ru", "groups", 5))
+}
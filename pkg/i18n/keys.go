@@ -0,0 +1,30 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keys - translator which returns keys with arguments like "list.page(2, 3)", for tests.
+type Keys struct{}
+
+func (Keys) Translate(_, key string, args ...interface{}) string {
+	return withArgs(key, args)
+}
+
+func (Keys) Plural(_, key string, count int, args ...interface{}) string {
+	return withArgs(key, append([]interface{}{count}, args...))
+}
+
+func withArgs(key string, args []interface{}) string {
+	if len(args) == 0 {
+		return key
+	}
+
+	texts := make([]string, len(args))
+	for i, arg := range args {
+		texts[i] = fmt.Sprint(arg)
+	}
+
+	return key + "(" + strings.Join(texts, ", ") + ")"
+}
@@ -0,0 +1,11 @@
+package i18n
+
+// Option -.
+type Option func(*Bundle)
+
+// WithCatalog - messages of the language.
+func WithCatalog(lang string, catalog Catalog) Option {
+	return func(b *Bundle) {
+		b.catalogs[baseLanguage(lang)] = catalog
+	}
+}
@@ -0,0 +1,42 @@
+package i18n
+
+type form int
+
+const (
+	formOther form = iota
+	formOne
+	formFew
+	formMany
+)
+
+const (
+	_ten     = 10
+	_hundred = 100
+)
+
+// pluralForm - CLDR plural rules of cardinal numbers for supported languages, English rules by default.
+func pluralForm(lang string, n int) form {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case "ru", "uk", "be":
+		mod10, mod100 := n%_ten, n%_hundred
+
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return formOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return formFew
+		default:
+			return formMany
+		}
+	default:
+		if n == 1 {
+			return formOne
+		}
+
+		return formOther
+	}
+}
@@ -0,0 +1,44 @@
+package markup
+
+import (
+	"strings"
+)
+
+// HTML - telegram HTML parse mode.
+var HTML Renderer = htmlRenderer{}
+
+var (
+	htmlText      = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	htmlAttribute = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+type htmlRenderer struct{}
+
+func (htmlRenderer) ParseMode() string {
+	return "HTML"
+}
+
+func (htmlRenderer) Render(parts []Part) string {
+	var builder strings.Builder
+
+	for _, part := range parts {
+		text := htmlText.Replace(part.Text)
+
+		switch part.Style {
+		case Bold:
+			builder.WriteString("<b>" + text + "</b>")
+		case Italic:
+			builder.WriteString("<i>" + text + "</i>")
+		case Link:
+			builder.WriteString(`<a href="` + htmlAttribute.Replace(part.URL) + `">` + text + "</a>")
+		case Code:
+			builder.WriteString("<code>" + text + "</code>")
+		case Blockquote:
+			builder.WriteString("<blockquote>" + text + "</blockquote>")
+		case Plain:
+			builder.WriteString(text)
+		}
+	}
+
+	return strings.TrimRight(builder.String(), " \n")
+}
@@ -0,0 +1,58 @@
+package markup
+
+import (
+	"strings"
+)
+
+// MarkdownV2 - telegram MarkdownV2 parse mode.
+var MarkdownV2 Renderer = markdownRenderer{}
+
+var (
+	markdownText = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+		">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+	markdownCode = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	markdownURL  = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+type markdownRenderer struct{}
+
+func (markdownRenderer) ParseMode() string {
+	return "MarkdownV2"
+}
+
+func (markdownRenderer) Render(parts []Part) string {
+	var builder strings.Builder
+
+	for _, part := range parts {
+		switch part.Style {
+		case Bold:
+			builder.WriteString("*" + markdownText.Replace(part.Text) + "*")
+		case Italic:
+			builder.WriteString("_" + markdownText.Replace(part.Text) + "_")
+		case Link:
+			builder.WriteString("[" + markdownText.Replace(part.Text) + "](" + markdownURL.Replace(part.URL) + ")")
+		case Code:
+			builder.WriteString("`" + markdownCode.Replace(part.Text) + "`")
+		case Blockquote:
+			builder.WriteString(markdownQuote(part.Text))
+		case Plain:
+			builder.WriteString(markdownText.Replace(part.Text))
+		}
+	}
+
+	return strings.TrimRight(builder.String(), " \n")
+}
+
+// markdownQuote - every line of quote starts with >, quote ends with the line.
+func markdownQuote(text string) string {
+	text = strings.TrimRight(text, "\n")
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		lines[i] = ">" + markdownText.Replace(line)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
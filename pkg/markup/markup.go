@@ -0,0 +1,68 @@
+// Package markup builds telegram messages from styled parts and renders them to HTML or MarkdownV2.
+package markup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Style - formatting of part.
+type Style int
+
+const (
+	Plain Style = iota
+	Bold
+	Italic
+	Link
+	Code
+	Blockquote
+)
+
+// Part - piece of message text with one style, text is user content and is always escaped.
+type Part struct {
+	Text  string
+	Style Style
+	URL   string
+}
+
+// Renderer - telegram parse mode.
+type Renderer interface {
+	ParseMode() string
+	Render(parts []Part) string
+}
+
+func Text(text string) Part {
+	return Part{Text: text}
+}
+
+func BoldText(text string) Part {
+	return Part{Text: text, Style: Bold}
+}
+
+func ItalicText(text string) Part {
+	return Part{Text: text, Style: Italic}
+}
+
+func LinkText(text, url string) Part {
+	return Part{Text: text, Style: Link, URL: url}
+}
+
+func CodeText(text string) Part {
+	return Part{Text: text, Style: Code}
+}
+
+func BlockquoteText(text string) Part {
+	return Part{Text: text, Style: Blockquote}
+}
+
+// New - renderer by telegram parse mode name.
+func New(parseMode string) (Renderer, error) {
+	switch strings.ToLower(parseMode) {
+	case strings.ToLower(HTML.ParseMode()):
+		return HTML, nil
+	case strings.ToLower(MarkdownV2.ParseMode()):
+		return MarkdownV2, nil
+	default:
+		return nil, fmt.Errorf("unknown parse mode %q", parseMode)
+	}
+}
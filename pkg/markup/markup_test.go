@@ -0,0 +1,99 @@
+package markup_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jokius/news-telegram-bot/pkg/markup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parts() []markup.Part {
+	return []markup.Part{
+		markup.BoldText("Группа <1> & *2*"),
+		markup.Text("\nтекст (с) [скобками] 1.5-2!\n"),
+		markup.ItalicText("курсив_"),
+		markup.Text(" "),
+		markup.LinkText("ссылка", `https://example.com/?a=1&b=(2)"`),
+		markup.Text("\n"),
+		markup.CodeText("/add_url `code` \\"),
+		markup.Text("\n"),
+		markup.BlockquoteText("цитата\n> вторая"),
+	}
+}
+
+func TestHTML(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "HTML", markup.HTML.ParseMode())
+	assert.Equal(t,
+		"<b>Группа &lt;1&gt; &amp; *2*</b>\nтекст (с) [скобками] 1.5-2!\n<i>курсив_</i> "+
+			`<a href="https://example.com/?a=1&amp;b=(2)&quot;">ссылка</a>`+"\n"+
+			"<code>/add_url `code` \\</code>\n<blockquote>цитата\n&gt; вторая</blockquote>",
+		markup.HTML.Render(parts()))
+}
+
+func TestMarkdownV2(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "MarkdownV2", markup.MarkdownV2.ParseMode())
+	assert.Equal(t,
+		"*Группа <1\\> & \\*2\\**\nтекст \\(с\\) \\[скобками\\] 1\\.5\\-2\\!\n_курсив\\__ "+
+			"[ссылка](https://example.com/?a=1&b=(2\\)\")\n"+
+			"`/add_url \\`code\\` \\\\`\n>цитата\n>\\> вторая",
+		markup.MarkdownV2.Render(parts()))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	renderer, err := markup.New("html")
+	require.ErrorIs(t, err, nil)
+	assert.Equal(t, markup.HTML, renderer)
+
+	renderer, err = markup.New("MarkdownV2")
+	require.ErrorIs(t, err, nil)
+	assert.Equal(t, markup.MarkdownV2, renderer)
+
+	_, err = markup.New("Markdown")
+	assert.NotNil(t, err)
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("short", func(t *testing.T) {
+		t.Parallel()
+
+		messages := markup.Split(markup.HTML, []markup.Part{markup.BoldText("bold"), markup.Text(" text")}, 10, 10)
+		assert.Equal(t, []string{"<b>bold</b> text"}, messages)
+	})
+
+	t.Run("style is kept on the border", func(t *testing.T) {
+		t.Parallel()
+
+		messages := markup.Split(markup.HTML, []markup.Part{
+			markup.Text("one "),
+			markup.LinkText("two three four", "https://example.com"),
+		}, 10, 20)
+		assert.Equal(t, []string{
+			`one <a href="https://example.com">two</a>`,
+			`<a href="https://example.com">three four</a>`,
+		}, messages)
+	})
+
+	t.Run("markdown is counted without escaping", func(t *testing.T) {
+		t.Parallel()
+
+		messages := markup.Split(markup.MarkdownV2, []markup.Part{markup.Text(strings.Repeat(".", 10))}, 10, 10)
+		assert.Equal(t, []string{strings.Repeat(`\.`, 10)}, messages)
+	})
+
+	t.Run("surrogate pairs", func(t *testing.T) {
+		t.Parallel()
+
+		messages := markup.Split(markup.HTML, []markup.Part{markup.Text(strings.Repeat("😀", 3))}, 4, 4)
+		assert.Equal(t, []string{"😀😀", "😀"}, messages)
+	})
+}
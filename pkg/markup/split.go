@@ -0,0 +1,103 @@
+package markup
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Telegram limits of text after entities parsing, in utf-16 code units.
+const (
+	MessageLimit = 4096
+	CaptionLimit = 1024
+)
+
+// Split - renders parts to messages no longer than limit (firstLimit for the first one), a part
+// on the border is split into two parts with the same style, so formatting is never broken.
+func Split(renderer Renderer, parts []Part, firstLimit, limit int) []string {
+	var (
+		messages []string
+		current  []Part
+		size     int
+	)
+
+	messageLimit := func() int {
+		if len(messages) == 0 {
+			return firstLimit
+		}
+
+		return limit
+	}
+
+	flush := func() {
+		if size > 0 {
+			messages = append(messages, renderer.Render(current))
+		}
+
+		current, size = nil, 0
+	}
+
+	for _, part := range parts {
+		for {
+			if size == 0 {
+				part.Text = strings.TrimLeft(part.Text, " \n")
+			}
+
+			if part.Text == "" {
+				break
+			}
+
+			if length := Len(part.Text); size+length <= messageLimit() {
+				current = append(current, part)
+				size += length
+
+				break
+			}
+
+			head, tail := cutText(part.Text, messageLimit()-size, size == 0)
+			if head != "" {
+				current = append(current, Part{Text: head, Style: part.Style, URL: part.URL})
+				size += Len(head)
+			}
+
+			flush()
+
+			part.Text = tail
+		}
+	}
+
+	flush()
+
+	return messages
+}
+
+// Len - length of text for telegram limits.
+func Len(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// cutText - head fits the limit and ends on a line or word border, text is cut by rune when force
+// and there is no border.
+func cutText(text string, limit int, force bool) (head, tail string) {
+	end, size := 0, 0
+
+	for i, r := range text {
+		if size += len(utf16.Encode([]rune{r})); size > limit {
+			break
+		}
+
+		end = i + utf8.RuneLen(r)
+	}
+
+	for _, border := range []string{"\n", " "} {
+		if i := strings.LastIndex(text[:end], border); i > 0 {
+			return text[:i], text[i:]
+		}
+	}
+
+	if !force {
+		return "", text
+	}
+
+	return text[:end], text[end:]
+}
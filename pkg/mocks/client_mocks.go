@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	http "net/http"
 	reflect "reflect"
 
@@ -77,3 +78,18 @@ func (mr *MockInterfaceClientMockRecorder) Post(url, body interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockInterfaceClient)(nil).Post), url, body)
 }
+
+// PostContext mocks base method.
+func (m *MockInterfaceClient) PostContext(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostContext", ctx, url, body)
+	ret0, _ := ret[0].(*http.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostContext indicates an expected call of PostContext.
+func (mr *MockInterfaceClientMockRecorder) PostContext(ctx, url, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostContext", reflect.TypeOf((*MockInterfaceClient)(nil).PostContext), ctx, url, body)
+}
@@ -5,11 +5,14 @@
 package mocks
 
 import (
+	context "context"
+	url "net/url"
 	reflect "reflect"
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/jokius/news-telegram-bot/internal/entity"
+	usecase "github.com/jokius/news-telegram-bot/internal/usecase"
 )
 
 // MockUser is a mock of User interface.
@@ -72,100 +75,382 @@ func (m *MockMessenger) EXPECT() *MockMessengerMockRecorder {
 	return m.recorder
 }
 
-// GroupList mocks base method.
-func (m *MockMessenger) GroupList(id uint64, groups []string) {
+// CallbackFailed mocks base method.
+func (m *MockMessenger) CallbackFailed(user *entity.User, callbackID string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "GroupList", id, groups)
+	m.ctrl.Call(m, "CallbackFailed", user, callbackID)
 }
 
-// GroupList indicates an expected call of GroupList.
-func (mr *MockMessengerMockRecorder) GroupList(id, groups interface{}) *gomock.Call {
+// CallbackFailed indicates an expected call of CallbackFailed.
+func (mr *MockMessengerMockRecorder) CallbackFailed(user, callbackID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupList", reflect.TypeOf((*MockMessenger)(nil).GroupList), id, groups)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallbackFailed", reflect.TypeOf((*MockMessenger)(nil).CallbackFailed), user, callbackID)
+}
+
+// CheckIntervalUpdated mocks base method.
+func (m *MockMessenger) CheckIntervalUpdated(user *entity.User, subscription *entity.Subscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CheckIntervalUpdated", user, subscription)
+}
+
+// CheckIntervalUpdated indicates an expected call of CheckIntervalUpdated.
+func (mr *MockMessengerMockRecorder) CheckIntervalUpdated(user, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIntervalUpdated", reflect.TypeOf((*MockMessenger)(nil).CheckIntervalUpdated), user, subscription)
+}
+
+// DigestSettings mocks base method.
+func (m *MockMessenger) DigestSettings(user *entity.User) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DigestSettings", user)
+}
+
+// DigestSettings indicates an expected call of DigestSettings.
+func (mr *MockMessengerMockRecorder) DigestSettings(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DigestSettings", reflect.TypeOf((*MockMessenger)(nil).DigestSettings), user)
+}
+
+// DigestUpdated mocks base method.
+func (m *MockMessenger) DigestUpdated(user *entity.User) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DigestUpdated", user)
+}
+
+// DigestUpdated indicates an expected call of DigestUpdated.
+func (mr *MockMessengerMockRecorder) DigestUpdated(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DigestUpdated", reflect.TypeOf((*MockMessenger)(nil).DigestUpdated), user)
+}
+
+// EditSubscriptionList mocks base method.
+func (m *MockMessenger) EditSubscriptionList(user *entity.User, messageID uint64, subscriptions []entity.Subscription, page int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EditSubscriptionList", user, messageID, subscriptions, page)
+}
+
+// EditSubscriptionList indicates an expected call of EditSubscriptionList.
+func (mr *MockMessengerMockRecorder) EditSubscriptionList(user, messageID, subscriptions, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditSubscriptionList", reflect.TypeOf((*MockMessenger)(nil).EditSubscriptionList), user, messageID, subscriptions, page)
+}
+
+// FiltersUpdated mocks base method.
+func (m *MockMessenger) FiltersUpdated(user *entity.User, filters entity.Filters) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FiltersUpdated", user, filters)
+}
+
+// FiltersUpdated indicates an expected call of FiltersUpdated.
+func (mr *MockMessengerMockRecorder) FiltersUpdated(user, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FiltersUpdated", reflect.TypeOf((*MockMessenger)(nil).FiltersUpdated), user, filters)
+}
+
+// Help mocks base method.
+func (m *MockMessenger) Help(user *entity.User, commands []entity.BotCommand) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Help", user, commands)
+}
+
+// Help indicates an expected call of Help.
+func (mr *MockMessengerMockRecorder) Help(user, commands interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Help", reflect.TypeOf((*MockMessenger)(nil).Help), user, commands)
+}
+
+// IncorrectFilter mocks base method.
+func (m *MockMessenger) IncorrectFilter(user *entity.User, filter string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncorrectFilter", user, filter)
+}
+
+// IncorrectFilter indicates an expected call of IncorrectFilter.
+func (mr *MockMessengerMockRecorder) IncorrectFilter(user, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncorrectFilter", reflect.TypeOf((*MockMessenger)(nil).IncorrectFilter), user, filter)
 }
 
 // IncorrectFormat mocks base method.
-func (m *MockMessenger) IncorrectFormat(id uint64, command string) {
+func (m *MockMessenger) IncorrectFormat(user *entity.User, command entity.BotCommand) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "IncorrectFormat", id, command)
+	m.ctrl.Call(m, "IncorrectFormat", user, command)
 }
 
 // IncorrectFormat indicates an expected call of IncorrectFormat.
-func (mr *MockMessengerMockRecorder) IncorrectFormat(id, command interface{}) *gomock.Call {
+func (mr *MockMessengerMockRecorder) IncorrectFormat(user, command interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncorrectFormat", reflect.TypeOf((*MockMessenger)(nil).IncorrectFormat), id, command)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncorrectFormat", reflect.TypeOf((*MockMessenger)(nil).IncorrectFormat), user, command)
 }
 
-// Message mocks base method.
-func (m *MockMessenger) Message(id uint64, text string) {
+// IncorrectTimezone mocks base method.
+func (m *MockMessenger) IncorrectTimezone(user *entity.User, timezone string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Message", id, text)
+	m.ctrl.Call(m, "IncorrectTimezone", user, timezone)
 }
 
-// Message indicates an expected call of Message.
-func (mr *MockMessengerMockRecorder) Message(id, text interface{}) *gomock.Call {
+// IncorrectTimezone indicates an expected call of IncorrectTimezone.
+func (mr *MockMessengerMockRecorder) IncorrectTimezone(user, timezone interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Message", reflect.TypeOf((*MockMessenger)(nil).Message), id, text)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncorrectTimezone", reflect.TypeOf((*MockMessenger)(nil).IncorrectTimezone), user, timezone)
+}
+
+// LanguageList mocks base method.
+func (m *MockMessenger) LanguageList(user *entity.User, current string, languages []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "LanguageList", user, current, languages)
+}
+
+// LanguageList indicates an expected call of LanguageList.
+func (mr *MockMessengerMockRecorder) LanguageList(user, current, languages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LanguageList", reflect.TypeOf((*MockMessenger)(nil).LanguageList), user, current, languages)
+}
+
+// LanguageUpdated mocks base method.
+func (m *MockMessenger) LanguageUpdated(user *entity.User) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "LanguageUpdated", user)
+}
+
+// LanguageUpdated indicates an expected call of LanguageUpdated.
+func (mr *MockMessengerMockRecorder) LanguageUpdated(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LanguageUpdated", reflect.TypeOf((*MockMessenger)(nil).LanguageUpdated), user)
+}
+
+// NotSubscribed mocks base method.
+func (m *MockMessenger) NotSubscribed(user *entity.User, url string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotSubscribed", user, url)
+}
+
+// NotSubscribed indicates an expected call of NotSubscribed.
+func (mr *MockMessengerMockRecorder) NotSubscribed(user, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotSubscribed", reflect.TypeOf((*MockMessenger)(nil).NotSubscribed), user, url)
+}
+
+// QuietSettings mocks base method.
+func (m *MockMessenger) QuietSettings(user *entity.User) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "QuietSettings", user)
+}
+
+// QuietSettings indicates an expected call of QuietSettings.
+func (mr *MockMessengerMockRecorder) QuietSettings(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuietSettings", reflect.TypeOf((*MockMessenger)(nil).QuietSettings), user)
+}
+
+// QuietUpdated mocks base method.
+func (m *MockMessenger) QuietUpdated(user *entity.User) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "QuietUpdated", user)
+}
+
+// QuietUpdated indicates an expected call of QuietUpdated.
+func (mr *MockMessengerMockRecorder) QuietUpdated(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuietUpdated", reflect.TypeOf((*MockMessenger)(nil).QuietUpdated), user)
 }
 
 // RemovedGroup mocks base method.
-func (m *MockMessenger) RemovedGroup(id uint64) {
+func (m *MockMessenger) RemovedGroup(user *entity.User) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "RemovedGroup", id)
+	m.ctrl.Call(m, "RemovedGroup", user)
 }
 
 // RemovedGroup indicates an expected call of RemovedGroup.
-func (mr *MockMessengerMockRecorder) RemovedGroup(id interface{}) *gomock.Call {
+func (mr *MockMessengerMockRecorder) RemovedGroup(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovedGroup", reflect.TypeOf((*MockMessenger)(nil).RemovedGroup), user)
+}
+
+// Send mocks base method.
+func (m *MockMessenger) Send(id uint64, text, parseMode string, silent bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", id, text, parseMode, silent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMessengerMockRecorder) Send(id, text, parseMode, silent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMessenger)(nil).Send), id, text, parseMode, silent)
+}
+
+// SendMedia mocks base method.
+func (m *MockMessenger) SendMedia(id uint64, media []entity.Media, caption, parseMode string, silent bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMedia", id, media, caption, parseMode, silent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMedia indicates an expected call of SendMedia.
+func (mr *MockMessengerMockRecorder) SendMedia(id, media, caption, parseMode, silent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMedia", reflect.TypeOf((*MockMessenger)(nil).SendMedia), id, media, caption, parseMode, silent)
+}
+
+// SetCommands mocks base method.
+func (m *MockMessenger) SetCommands(commands []entity.BotCommand, languageCode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCommands", commands, languageCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCommands indicates an expected call of SetCommands.
+func (mr *MockMessengerMockRecorder) SetCommands(commands, languageCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovedGroup", reflect.TypeOf((*MockMessenger)(nil).RemovedGroup), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommands", reflect.TypeOf((*MockMessenger)(nil).SetCommands), commands, languageCode)
 }
 
 // StartDateUpdated mocks base method.
-func (m *MockMessenger) StartDateUpdated(id uint64) {
+func (m *MockMessenger) StartDateUpdated(user *entity.User) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "StartDateUpdated", id)
+	m.ctrl.Call(m, "StartDateUpdated", user)
 }
 
 // StartDateUpdated indicates an expected call of StartDateUpdated.
-func (mr *MockMessengerMockRecorder) StartDateUpdated(id interface{}) *gomock.Call {
+func (mr *MockMessengerMockRecorder) StartDateUpdated(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartDateUpdated", reflect.TypeOf((*MockMessenger)(nil).StartDateUpdated), user)
+}
+
+// SubscriptionList mocks base method.
+func (m *MockMessenger) SubscriptionList(user *entity.User, subscriptions []entity.Subscription, page int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscriptionList", user, subscriptions, page)
+}
+
+// SubscriptionList indicates an expected call of SubscriptionList.
+func (mr *MockMessengerMockRecorder) SubscriptionList(user, subscriptions, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionList", reflect.TypeOf((*MockMessenger)(nil).SubscriptionList), user, subscriptions, page)
+}
+
+// SubscriptionMuted mocks base method.
+func (m *MockMessenger) SubscriptionMuted(user *entity.User, subscription *entity.Subscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscriptionMuted", user, subscription)
+}
+
+// SubscriptionMuted indicates an expected call of SubscriptionMuted.
+func (mr *MockMessengerMockRecorder) SubscriptionMuted(user, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionMuted", reflect.TypeOf((*MockMessenger)(nil).SubscriptionMuted), user, subscription)
+}
+
+// SubscriptionNotFound mocks base method.
+func (m *MockMessenger) SubscriptionNotFound(user *entity.User, callbackID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscriptionNotFound", user, callbackID)
+}
+
+// SubscriptionNotFound indicates an expected call of SubscriptionNotFound.
+func (mr *MockMessengerMockRecorder) SubscriptionNotFound(user, callbackID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartDateUpdated", reflect.TypeOf((*MockMessenger)(nil).StartDateUpdated), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionNotFound", reflect.TypeOf((*MockMessenger)(nil).SubscriptionNotFound), user, callbackID)
+}
+
+// SubscriptionPaused mocks base method.
+func (m *MockMessenger) SubscriptionPaused(user *entity.User, subscription *entity.Subscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscriptionPaused", user, subscription)
+}
+
+// SubscriptionPaused indicates an expected call of SubscriptionPaused.
+func (mr *MockMessengerMockRecorder) SubscriptionPaused(user, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionPaused", reflect.TypeOf((*MockMessenger)(nil).SubscriptionPaused), user, subscription)
+}
+
+// SubscriptionResumed mocks base method.
+func (m *MockMessenger) SubscriptionResumed(user *entity.User, subscription *entity.Subscription, missed int64, catchUp bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscriptionResumed", user, subscription, missed, catchUp)
+}
+
+// SubscriptionResumed indicates an expected call of SubscriptionResumed.
+func (mr *MockMessengerMockRecorder) SubscriptionResumed(user, subscription, missed, catchUp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionResumed", reflect.TypeOf((*MockMessenger)(nil).SubscriptionResumed), user, subscription, missed, catchUp)
+}
+
+// SubscriptionUpdated mocks base method.
+func (m *MockMessenger) SubscriptionUpdated(user *entity.User, callbackID, action string, subscription *entity.Subscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscriptionUpdated", user, callbackID, action, subscription)
+}
+
+// SubscriptionUpdated indicates an expected call of SubscriptionUpdated.
+func (mr *MockMessengerMockRecorder) SubscriptionUpdated(user, callbackID, action, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionUpdated", reflect.TypeOf((*MockMessenger)(nil).SubscriptionUpdated), user, callbackID, action, subscription)
 }
 
 // URLAdded mocks base method.
-func (m *MockMessenger) URLAdded(id uint64) {
+func (m *MockMessenger) URLAdded(user *entity.User) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "URLAdded", id)
+	m.ctrl.Call(m, "URLAdded", user)
 }
 
 // URLAdded indicates an expected call of URLAdded.
-func (mr *MockMessengerMockRecorder) URLAdded(id interface{}) *gomock.Call {
+func (mr *MockMessengerMockRecorder) URLAdded(user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URLAdded", reflect.TypeOf((*MockMessenger)(nil).URLAdded), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URLAdded", reflect.TypeOf((*MockMessenger)(nil).URLAdded), user)
+}
+
+// UnknownCommand mocks base method.
+func (m *MockMessenger) UnknownCommand(user *entity.User) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UnknownCommand", user)
+}
+
+// UnknownCommand indicates an expected call of UnknownCommand.
+func (mr *MockMessengerMockRecorder) UnknownCommand(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnknownCommand", reflect.TypeOf((*MockMessenger)(nil).UnknownCommand), user)
 }
 
 // UnknownError mocks base method.
-func (m *MockMessenger) UnknownError(id uint64, text string) {
+func (m *MockMessenger) UnknownError(user *entity.User, text string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "UnknownError", id, text)
+	m.ctrl.Call(m, "UnknownError", user, text)
 }
 
 // UnknownError indicates an expected call of UnknownError.
-func (mr *MockMessengerMockRecorder) UnknownError(id, text interface{}) *gomock.Call {
+func (mr *MockMessengerMockRecorder) UnknownError(user, text interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnknownError", reflect.TypeOf((*MockMessenger)(nil).UnknownError), id, text)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnknownError", reflect.TypeOf((*MockMessenger)(nil).UnknownError), user, text)
 }
 
 // UnknownSource mocks base method.
-func (m *MockMessenger) UnknownSource(id uint64, url string) {
+func (m *MockMessenger) UnknownSource(user *entity.User, url string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "UnknownSource", id, url)
+	m.ctrl.Call(m, "UnknownSource", user, url)
 }
 
 // UnknownSource indicates an expected call of UnknownSource.
-func (mr *MockMessengerMockRecorder) UnknownSource(id, url interface{}) *gomock.Call {
+func (mr *MockMessengerMockRecorder) UnknownSource(user, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnknownSource", reflect.TypeOf((*MockMessenger)(nil).UnknownSource), user, url)
+}
+
+// Welcome mocks base method.
+func (m *MockMessenger) Welcome(user *entity.User, commands []entity.BotCommand) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Welcome", user, commands)
+}
+
+// Welcome indicates an expected call of Welcome.
+func (mr *MockMessengerMockRecorder) Welcome(user, commands interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnknownSource", reflect.TypeOf((*MockMessenger)(nil).UnknownSource), id, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Welcome", reflect.TypeOf((*MockMessenger)(nil).Welcome), user, commands)
 }
 
 // MockSource is a mock of Source interface.
@@ -191,19 +476,34 @@ func (m *MockSource) EXPECT() *MockSourceMockRecorder {
 	return m.recorder
 }
 
-// GetGroupMessages mocks base method.
-func (m *MockSource) GetGroupMessages(id string, offset int) (entity.VkResult, error) {
+// GetPosts mocks base method.
+func (m *MockSource) GetPosts(ctx context.Context, group, cursor string) (entity.PostsPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetGroupMessages", id, offset)
-	ret0, _ := ret[0].(entity.VkResult)
+	ret := m.ctrl.Call(m, "GetPosts", ctx, group, cursor)
+	ret0, _ := ret[0].(entity.PostsPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetGroupMessages indicates an expected call of GetGroupMessages.
-func (mr *MockSourceMockRecorder) GetGroupMessages(id, offset interface{}) *gomock.Call {
+// GetPosts indicates an expected call of GetPosts.
+func (mr *MockSourceMockRecorder) GetPosts(ctx, group, cursor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupMessages", reflect.TypeOf((*MockSource)(nil).GetGroupMessages), id, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockSource)(nil).GetPosts), ctx, group, cursor)
+}
+
+// GroupName mocks base method.
+func (m *MockSource) GroupName(u *url.URL) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GroupName", u)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GroupName indicates an expected call of GroupName.
+func (mr *MockSourceMockRecorder) GroupName(u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupName", reflect.TypeOf((*MockSource)(nil).GroupName), u)
 }
 
 // Name mocks base method.
@@ -220,6 +520,59 @@ func (mr *MockSourceMockRecorder) Name() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockSource)(nil).Name))
 }
 
+// MockSources is a mock of Sources interface.
+type MockSources struct {
+	ctrl     *gomock.Controller
+	recorder *MockSourcesMockRecorder
+}
+
+// MockSourcesMockRecorder is the mock recorder for MockSources.
+type MockSourcesMockRecorder struct {
+	mock *MockSources
+}
+
+// NewMockSources creates a new mock instance.
+func NewMockSources(ctrl *gomock.Controller) *MockSources {
+	mock := &MockSources{ctrl: ctrl}
+	mock.recorder = &MockSourcesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSources) EXPECT() *MockSourcesMockRecorder {
+	return m.recorder
+}
+
+// All mocks base method.
+func (m *MockSources) All() []usecase.Source {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "All")
+	ret0, _ := ret[0].([]usecase.Source)
+	return ret0
+}
+
+// All indicates an expected call of All.
+func (mr *MockSourcesMockRecorder) All() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "All", reflect.TypeOf((*MockSources)(nil).All))
+}
+
+// Find mocks base method.
+func (m *MockSources) Find(rawURL string) (usecase.Source, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", rawURL)
+	ret0, _ := ret[0].(usecase.Source)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockSourcesMockRecorder) Find(rawURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockSources)(nil).Find), rawURL)
+}
+
 // MockUserRepo is a mock of UserRepo interface.
 type MockUserRepo struct {
 	ctrl     *gomock.Controller
@@ -243,47 +596,205 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 	return m.recorder
 }
 
-// AddGroupByURL mocks base method.
-func (m *MockUserRepo) AddGroupByURL(id uint64, source, url string) error {
+// Activate mocks base method.
+func (m *MockUserRepo) Activate(id uint64, languageCode string) (entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddGroupByURL", id, source, url)
+	ret := m.ctrl.Call(m, "Activate", id, languageCode)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Activate indicates an expected call of Activate.
+func (mr *MockUserRepoMockRecorder) Activate(id, languageCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Activate", reflect.TypeOf((*MockUserRepo)(nil).Activate), id, languageCode)
+}
+
+// AddGroup mocks base method.
+func (m *MockUserRepo) AddGroup(id uint64, source, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddGroup", id, source, name)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AddGroupByURL indicates an expected call of AddGroupByURL.
-func (mr *MockUserRepoMockRecorder) AddGroupByURL(id, source, url interface{}) *gomock.Call {
+// AddGroup indicates an expected call of AddGroup.
+func (mr *MockUserRepoMockRecorder) AddGroup(id, source, name interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddGroupByURL", reflect.TypeOf((*MockUserRepo)(nil).AddGroupByURL), id, source, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddGroup", reflect.TypeOf((*MockUserRepo)(nil).AddGroup), id, source, name)
 }
 
-// Groups mocks base method.
-func (m *MockUserRepo) Groups(id uint64) ([]entity.Group, error) {
+// FindSubscription mocks base method.
+func (m *MockUserRepo) FindSubscription(id uint64, source, name string) (entity.Subscription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Groups", id)
-	ret0, _ := ret[0].([]entity.Group)
+	ret := m.ctrl.Call(m, "FindSubscription", id, source, name)
+	ret0, _ := ret[0].(entity.Subscription)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Groups indicates an expected call of Groups.
-func (mr *MockUserRepoMockRecorder) Groups(id interface{}) *gomock.Call {
+// FindSubscription indicates an expected call of FindSubscription.
+func (mr *MockUserRepoMockRecorder) FindSubscription(id, source, name interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Groups", reflect.TypeOf((*MockUserRepo)(nil).Groups), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscription", reflect.TypeOf((*MockUserRepo)(nil).FindSubscription), id, source, name)
 }
 
 // RemoveGroup mocks base method.
-func (m *MockUserRepo) RemoveGroup(id uint64, source, url string) error {
+func (m *MockUserRepo) RemoveGroup(id uint64, source, name string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveGroup", id, source, url)
+	ret := m.ctrl.Call(m, "RemoveGroup", id, source, name)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RemoveGroup indicates an expected call of RemoveGroup.
-func (mr *MockUserRepoMockRecorder) RemoveGroup(id, source, url interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) RemoveGroup(id, source, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveGroup", reflect.TypeOf((*MockUserRepo)(nil).RemoveGroup), id, source, name)
+}
+
+// RemoveSubscription mocks base method.
+func (m *MockUserRepo) RemoveSubscription(subscription *entity.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSubscription", subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveSubscription indicates an expected call of RemoveSubscription.
+func (mr *MockUserRepoMockRecorder) RemoveSubscription(subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSubscription", reflect.TypeOf((*MockUserRepo)(nil).RemoveSubscription), subscription)
+}
+
+// ResumeSubscription mocks base method.
+func (m *MockUserRepo) ResumeSubscription(subscription *entity.Subscription, catchUp bool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeSubscription", subscription, catchUp)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeSubscription indicates an expected call of ResumeSubscription.
+func (mr *MockUserRepoMockRecorder) ResumeSubscription(subscription, catchUp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSubscription", reflect.TypeOf((*MockUserRepo)(nil).ResumeSubscription), subscription, catchUp)
+}
+
+// SetActive mocks base method.
+func (m *MockUserRepo) SetActive(id uint64, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", id, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockUserRepoMockRecorder) SetActive(id, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockUserRepo)(nil).SetActive), id, active)
+}
+
+// SetFilters mocks base method.
+func (m *MockUserRepo) SetFilters(id uint64, source, name string, filters entity.Filters) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFilters", id, source, name, filters)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFilters indicates an expected call of SetFilters.
+func (mr *MockUserRepoMockRecorder) SetFilters(id, source, name, filters interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveGroup", reflect.TypeOf((*MockUserRepo)(nil).RemoveGroup), id, source, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFilters", reflect.TypeOf((*MockUserRepo)(nil).SetFilters), id, source, name, filters)
+}
+
+// SetLanguage mocks base method.
+func (m *MockUserRepo) SetLanguage(id uint64, language string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLanguage", id, language)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLanguage indicates an expected call of SetLanguage.
+func (mr *MockUserRepoMockRecorder) SetLanguage(id, language interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLanguage", reflect.TypeOf((*MockUserRepo)(nil).SetLanguage), id, language)
+}
+
+// Subscription mocks base method.
+func (m *MockUserRepo) Subscription(id, subscriptionID uint64) (entity.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscription", id, subscriptionID)
+	ret0, _ := ret[0].(entity.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscription indicates an expected call of Subscription.
+func (mr *MockUserRepoMockRecorder) Subscription(id, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscription", reflect.TypeOf((*MockUserRepo)(nil).Subscription), id, subscriptionID)
+}
+
+// Subscriptions mocks base method.
+func (m *MockUserRepo) Subscriptions(id uint64) ([]entity.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscriptions", id)
+	ret0, _ := ret[0].([]entity.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscriptions indicates an expected call of Subscriptions.
+func (mr *MockUserRepoMockRecorder) Subscriptions(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscriptions", reflect.TypeOf((*MockUserRepo)(nil).Subscriptions), id)
+}
+
+// UpdateCheckInterval mocks base method.
+func (m *MockUserRepo) UpdateCheckInterval(subscription *entity.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCheckInterval", subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCheckInterval indicates an expected call of UpdateCheckInterval.
+func (mr *MockUserRepoMockRecorder) UpdateCheckInterval(subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCheckInterval", reflect.TypeOf((*MockUserRepo)(nil).UpdateCheckInterval), subscription)
+}
+
+// UpdateDigest mocks base method.
+func (m *MockUserRepo) UpdateDigest(user *entity.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDigest", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDigest indicates an expected call of UpdateDigest.
+func (mr *MockUserRepoMockRecorder) UpdateDigest(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDigest", reflect.TypeOf((*MockUserRepo)(nil).UpdateDigest), user)
+}
+
+// UpdateQuiet mocks base method.
+func (m *MockUserRepo) UpdateQuiet(user *entity.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQuiet", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateQuiet indicates an expected call of UpdateQuiet.
+func (mr *MockUserRepoMockRecorder) UpdateQuiet(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQuiet", reflect.TypeOf((*MockUserRepo)(nil).UpdateQuiet), user)
 }
 
 // UpdateStartDate mocks base method.
@@ -300,56 +811,84 @@ func (mr *MockUserRepoMockRecorder) UpdateStartDate(id, date interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStartDate", reflect.TypeOf((*MockUserRepo)(nil).UpdateStartDate), id, date)
 }
 
-// MockGroupRepo is a mock of GroupRepo interface.
-type MockGroupRepo struct {
+// UpdateSubscription mocks base method.
+func (m *MockUserRepo) UpdateSubscription(subscription *entity.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscription", subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscription indicates an expected call of UpdateSubscription.
+func (mr *MockUserRepoMockRecorder) UpdateSubscription(subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockUserRepo)(nil).UpdateSubscription), subscription)
+}
+
+// MockFeedRepo is a mock of FeedRepo interface.
+type MockFeedRepo struct {
 	ctrl     *gomock.Controller
-	recorder *MockGroupRepoMockRecorder
+	recorder *MockFeedRepoMockRecorder
 }
 
-// MockGroupRepoMockRecorder is the mock recorder for MockGroupRepo.
-type MockGroupRepoMockRecorder struct {
-	mock *MockGroupRepo
+// MockFeedRepoMockRecorder is the mock recorder for MockFeedRepo.
+type MockFeedRepoMockRecorder struct {
+	mock *MockFeedRepo
 }
 
-// NewMockGroupRepo creates a new mock instance.
-func NewMockGroupRepo(ctrl *gomock.Controller) *MockGroupRepo {
-	mock := &MockGroupRepo{ctrl: ctrl}
-	mock.recorder = &MockGroupRepoMockRecorder{mock}
+// NewMockFeedRepo creates a new mock instance.
+func NewMockFeedRepo(ctrl *gomock.Controller) *MockFeedRepo {
+	mock := &MockFeedRepo{ctrl: ctrl}
+	mock.recorder = &MockFeedRepoMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockGroupRepo) EXPECT() *MockGroupRepoMockRecorder {
+func (m *MockFeedRepo) EXPECT() *MockFeedRepoMockRecorder {
 	return m.recorder
 }
 
-// AllBySource mocks base method.
-func (m *MockGroupRepo) AllBySource(source string) ([]entity.Group, error) {
+// Due mocks base method.
+func (m *MockFeedRepo) Due(source string, now time.Time) ([]entity.Feed, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AllBySource", source)
-	ret0, _ := ret[0].([]entity.Group)
+	ret := m.ctrl.Call(m, "Due", source, now)
+	ret0, _ := ret[0].([]entity.Feed)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AllBySource indicates an expected call of AllBySource.
-func (mr *MockGroupRepoMockRecorder) AllBySource(source interface{}) *gomock.Call {
+// Due indicates an expected call of Due.
+func (mr *MockFeedRepoMockRecorder) Due(source, now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllBySource", reflect.TypeOf((*MockGroupRepo)(nil).AllBySource), source)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Due", reflect.TypeOf((*MockFeedRepo)(nil).Due), source, now)
+}
+
+// SetBroken mocks base method.
+func (m *MockFeedRepo) SetBroken(feedID uint64, brokenAt *time.Time, deliveries []entity.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBroken", feedID, brokenAt, deliveries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBroken indicates an expected call of SetBroken.
+func (mr *MockFeedRepoMockRecorder) SetBroken(feedID, brokenAt, deliveries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBroken", reflect.TypeOf((*MockFeedRepo)(nil).SetBroken), feedID, brokenAt, deliveries)
 }
 
 // Update mocks base method.
-func (m *MockGroupRepo) Update(group *entity.Group) error {
+func (m *MockFeedRepo) Update(feed *entity.Feed) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", group)
+	ret := m.ctrl.Call(m, "Update", feed)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockGroupRepoMockRecorder) Update(group interface{}) *gomock.Call {
+func (mr *MockFeedRepoMockRecorder) Update(feed interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockGroupRepo)(nil).Update), group)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFeedRepo)(nil).Update), feed)
 }
 
 // MockMessageRepo is a mock of MessageRepo interface.
@@ -376,29 +915,177 @@ func (m *MockMessageRepo) EXPECT() *MockMessageRepoMockRecorder {
 }
 
 // Add mocks base method.
-func (m *MockMessageRepo) Add(groupID, messageID uint64, source string, messageAt time.Time) error {
+func (m *MockMessageRepo) Add(feedID uint64, guid, source string, messageAt time.Time, deliveries []entity.Delivery, digestItems []entity.DigestItem) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", groupID, messageID, source, messageAt)
+	ret := m.ctrl.Call(m, "Add", feedID, guid, source, messageAt, deliveries, digestItems)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Add indicates an expected call of Add.
-func (mr *MockMessageRepoMockRecorder) Add(groupID, messageID, source, messageAt interface{}) *gomock.Call {
+func (mr *MockMessageRepoMockRecorder) Add(feedID, guid, source, messageAt, deliveries, digestItems interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockMessageRepo)(nil).Add), groupID, messageID, source, messageAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockMessageRepo)(nil).Add), feedID, guid, source, messageAt, deliveries, digestItems)
+}
+
+// Exists mocks base method.
+func (m *MockMessageRepo) Exists(feedID uint64, guid string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", feedID, guid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockMessageRepoMockRecorder) Exists(feedID, guid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockMessageRepo)(nil).Exists), feedID, guid)
 }
 
 // Last mocks base method.
-func (m *MockMessageRepo) Last(groupID uint64) entity.Message {
+func (m *MockMessageRepo) Last(feedID uint64) entity.Message {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Last", groupID)
+	ret := m.ctrl.Call(m, "Last", feedID)
 	ret0, _ := ret[0].(entity.Message)
 	return ret0
 }
 
 // Last indicates an expected call of Last.
-func (mr *MockMessageRepoMockRecorder) Last(groupID interface{}) *gomock.Call {
+func (mr *MockMessageRepoMockRecorder) Last(feedID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Last", reflect.TypeOf((*MockMessageRepo)(nil).Last), feedID)
+}
+
+// MockDeliveryRepo is a mock of DeliveryRepo interface.
+type MockDeliveryRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryRepoMockRecorder
+}
+
+// MockDeliveryRepoMockRecorder is the mock recorder for MockDeliveryRepo.
+type MockDeliveryRepoMockRecorder struct {
+	mock *MockDeliveryRepo
+}
+
+// NewMockDeliveryRepo creates a new mock instance.
+func NewMockDeliveryRepo(ctrl *gomock.Controller) *MockDeliveryRepo {
+	mock := &MockDeliveryRepo{ctrl: ctrl}
+	mock.recorder = &MockDeliveryRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryRepo) EXPECT() *MockDeliveryRepoMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockDeliveryRepo) Delete(delivery *entity.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDeliveryRepoMockRecorder) Delete(delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDeliveryRepo)(nil).Delete), delivery)
+}
+
+// Due mocks base method.
+func (m *MockDeliveryRepo) Due(now time.Time, limit int) ([]entity.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Due", now, limit)
+	ret0, _ := ret[0].([]entity.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Due indicates an expected call of Due.
+func (mr *MockDeliveryRepoMockRecorder) Due(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Due", reflect.TypeOf((*MockDeliveryRepo)(nil).Due), now, limit)
+}
+
+// Update mocks base method.
+func (m *MockDeliveryRepo) Update(delivery *entity.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDeliveryRepoMockRecorder) Update(delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDeliveryRepo)(nil).Update), delivery)
+}
+
+// MockDigestRepo is a mock of DigestRepo interface.
+type MockDigestRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockDigestRepoMockRecorder
+}
+
+// MockDigestRepoMockRecorder is the mock recorder for MockDigestRepo.
+type MockDigestRepoMockRecorder struct {
+	mock *MockDigestRepo
+}
+
+// NewMockDigestRepo creates a new mock instance.
+func NewMockDigestRepo(ctrl *gomock.Controller) *MockDigestRepo {
+	mock := &MockDigestRepo{ctrl: ctrl}
+	mock.recorder = &MockDigestRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDigestRepo) EXPECT() *MockDigestRepoMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockDigestRepo) Complete(user *entity.User, items []entity.DigestItem, deliveries []entity.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", user, items, deliveries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockDigestRepoMockRecorder) Complete(user, items, deliveries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockDigestRepo)(nil).Complete), user, items, deliveries)
+}
+
+// Due mocks base method.
+func (m *MockDigestRepo) Due(now time.Time, limit int) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Due", now, limit)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Due indicates an expected call of Due.
+func (mr *MockDigestRepoMockRecorder) Due(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Due", reflect.TypeOf((*MockDigestRepo)(nil).Due), now, limit)
+}
+
+// Items mocks base method.
+func (m *MockDigestRepo) Items(userID uint64) ([]entity.DigestItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Items", userID)
+	ret0, _ := ret[0].([]entity.DigestItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Items indicates an expected call of Items.
+func (mr *MockDigestRepoMockRecorder) Items(userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Last", reflect.TypeOf((*MockMessageRepo)(nil).Last), groupID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Items", reflect.TypeOf((*MockDigestRepo)(nil).Items), userID)
 }
@@ -0,0 +1,228 @@
+// Package ratelimit implements ordered waiting for global and per key sliding window limits.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultGlobalCount   = 30
+	_defaultGlobalPeriod  = time.Second
+	_defaultKeyCount      = 1
+	_defaultKeyPeriod     = time.Second
+	_defaultGroupCount    = 20
+	_defaultGroupPeriod   = time.Minute
+	_defaultPruneKeysSize = 1000
+)
+
+type rule struct {
+	count  int
+	period time.Duration
+}
+
+// window - times of the last events, no more than the biggest rule count.
+type window []time.Time
+
+type waiter struct {
+	key   int64
+	group bool
+}
+
+// Limiter - waiters are served in order of arrival, but a waiter doesn't block other keys.
+type Limiter struct {
+	mu       sync.Mutex
+	clock    Clock
+	global   []rule
+	perKey   []rule
+	perGroup []rule
+	events   window
+	keys     map[int64]window
+	queue    []*waiter
+	wake     chan struct{}
+}
+
+// New -.
+func New(opts ...Option) *Limiter {
+	l := &Limiter{
+		clock:    realClock{},
+		global:   []rule{{_defaultGlobalCount, _defaultGlobalPeriod}},
+		perKey:   []rule{{_defaultKeyCount, _defaultKeyPeriod}},
+		perGroup: []rule{{_defaultGroupCount, _defaultGroupPeriod}},
+		keys:     make(map[int64]window),
+		wake:     make(chan struct{}),
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Wait - blocks until event with key is allowed, group keys have additional per group limit.
+func (l *Limiter) Wait(ctx context.Context, key int64, group bool) error {
+	w := &waiter{key, group}
+
+	l.mu.Lock()
+	l.queue = append(l.queue, w)
+	l.mu.Unlock()
+
+	for {
+		l.mu.Lock()
+		delay, wake := l.reserve(w), l.wake
+		l.mu.Unlock()
+
+		if delay == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			l.mu.Lock()
+			l.remove(w)
+			l.mu.Unlock()
+
+			return ctx.Err()
+		case <-wake:
+		case <-l.clock.After(delay):
+		}
+	}
+}
+
+// Depth - count of waiters.
+func (l *Limiter) Depth() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.queue)
+}
+
+// reserve - returns 0 when event is allowed and recorded, or delay before the next try.
+func (l *Limiter) reserve(w *waiter) time.Duration {
+	now := l.clock.Now()
+	delay := wait(l.events, l.global, now)
+
+	if keyDelay := wait(l.keys[w.key], l.rules(w), now); keyDelay > delay {
+		delay = keyDelay
+	}
+
+	// Earlier waiters of the same key or not limited by their key go first, the granted one wakes the rest.
+	for _, queued := range l.queue {
+		if queued == w || delay > 0 {
+			break
+		}
+
+		if queued.key == w.key || wait(l.keys[queued.key], l.rules(queued), now) == 0 {
+			delay = minPeriod(l.rules(queued))
+		}
+	}
+
+	if delay > 0 {
+		return delay
+	}
+
+	l.events = record(l.events, l.global, now)
+	l.keys[w.key] = record(l.keys[w.key], l.rules(w), now)
+	l.remove(w)
+	l.prune(now)
+
+	close(l.wake)
+	l.wake = make(chan struct{})
+
+	return 0
+}
+
+func (l *Limiter) rules(w *waiter) []rule {
+	if !w.group {
+		return l.perKey
+	}
+
+	return append(append([]rule{}, l.perKey...), l.perGroup...)
+}
+
+func (l *Limiter) remove(w *waiter) {
+	for i, queued := range l.queue {
+		if queued == w {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// prune - forget keys without events in the longest period.
+func (l *Limiter) prune(now time.Time) {
+	if len(l.keys) < _defaultPruneKeysSize {
+		return
+	}
+
+	longest := maxPeriod(append(append([]rule{}, l.perKey...), l.perGroup...))
+	for key, events := range l.keys {
+		if len(events) == 0 || now.Sub(events[len(events)-1]) >= longest {
+			delete(l.keys, key)
+		}
+	}
+}
+
+// wait - delay until every rule allows one more event.
+func wait(events window, rules []rule, now time.Time) time.Duration {
+	var delay time.Duration
+
+	for _, r := range rules {
+		if len(events) < r.count {
+			continue
+		}
+
+		// the oldest event of the last r.count ones must leave the period
+		oldest := events[len(events)-r.count]
+		if d := oldest.Add(r.period).Sub(now); d > delay {
+			delay = d
+		}
+	}
+
+	return delay
+}
+
+func record(events window, rules []rule, now time.Time) window {
+	events = append(events, now)
+
+	limit := 0
+	for _, r := range rules {
+		if r.count > limit {
+			limit = r.count
+		}
+	}
+
+	if len(events) > limit {
+		events = append(window{}, events[len(events)-limit:]...)
+	}
+
+	return events
+}
+
+func minPeriod(rules []rule) time.Duration {
+	period := rules[0].period
+
+	for _, r := range rules[1:] {
+		if r.period < period {
+			period = r.period
+		}
+	}
+
+	return period
+}
+
+func maxPeriod(rules []rule) time.Duration {
+	var period time.Duration
+
+	for _, r := range rules {
+		if r.period > period {
+			period = r.period
+		}
+	}
+
+	return period
+}
@@ -0,0 +1,174 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jokius/news-telegram-bot/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []timer
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2021, 11, 26, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, timer{c.now.Add(d), ch})
+
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	var timers []timer
+
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			timers = append(timers, t)
+
+			continue
+		}
+
+		t.ch <- c.now
+	}
+
+	c.timers = timers
+}
+
+func (c *fakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+func waitAsync(limiter *ratelimit.Limiter, key int64, group bool) chan error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- limiter.Wait(context.Background(), key, group)
+	}()
+
+	return done
+}
+
+func waitTimers(t *testing.T, clock *fakeClock) {
+	t.Helper()
+
+	require.Eventually(t, func() bool { return clock.Timers() > 0 }, time.Second, time.Millisecond)
+}
+
+func TestWait_global(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	limiter := ratelimit.New(ratelimit.Global(2, time.Second), ratelimit.UseClock(clock))
+
+	assert.ErrorIs(t, limiter.Wait(context.Background(), 1, false), nil)
+	assert.ErrorIs(t, limiter.Wait(context.Background(), 2, false), nil)
+
+	done := waitAsync(limiter, 3, false)
+
+	waitTimers(t, clock)
+	assert.Equal(t, 1, limiter.Depth())
+
+	clock.Advance(time.Second)
+
+	assert.ErrorIs(t, <-done, nil)
+	assert.Equal(t, 0, limiter.Depth())
+}
+
+func TestWait_per_key(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	limiter := ratelimit.New(ratelimit.UseClock(clock))
+
+	assert.ErrorIs(t, limiter.Wait(context.Background(), 1, false), nil)
+
+	done := waitAsync(limiter, 1, false)
+
+	waitTimers(t, clock)
+
+	// other keys are not blocked by the waiting one
+	assert.ErrorIs(t, limiter.Wait(context.Background(), 2, false), nil)
+	assert.Equal(t, 1, limiter.Depth())
+
+	clock.Advance(time.Second)
+
+	assert.ErrorIs(t, <-done, nil)
+}
+
+func TestWait_per_group(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	limiter := ratelimit.New(
+		ratelimit.PerKey(10, time.Second),
+		ratelimit.PerGroup(2, time.Minute),
+		ratelimit.UseClock(clock),
+	)
+
+	assert.ErrorIs(t, limiter.Wait(context.Background(), -1, true), nil)
+	assert.ErrorIs(t, limiter.Wait(context.Background(), -1, true), nil)
+
+	done := waitAsync(limiter, -1, true)
+
+	waitTimers(t, clock)
+	clock.Advance(time.Second)
+	waitTimers(t, clock)
+
+	select {
+	case <-done:
+		t.Fatal("group limit is ignored")
+	default:
+	}
+
+	clock.Advance(time.Minute)
+
+	assert.ErrorIs(t, <-done, nil)
+}
+
+func TestWait_canceled(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	limiter := ratelimit.New(ratelimit.UseClock(clock))
+
+	assert.ErrorIs(t, limiter.Wait(context.Background(), 1, false), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, limiter.Wait(ctx, 1, false), context.Canceled)
+	assert.Equal(t, 0, limiter.Depth())
+}
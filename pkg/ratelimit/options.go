@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"time"
+)
+
+// Option -.
+type Option func(*Limiter)
+
+// Global - limit of all events. Default: 30 per second.
+func Global(count int, period time.Duration) Option {
+	return func(l *Limiter) {
+		l.global = []rule{{count, period}}
+	}
+}
+
+// PerKey - limit of events with the same key. Default: 1 per second.
+func PerKey(count int, period time.Duration) Option {
+	return func(l *Limiter) {
+		l.perKey = []rule{{count, period}}
+	}
+}
+
+// PerGroup - additional limit of events with group key. Default: 20 per minute.
+func PerGroup(count int, period time.Duration) Option {
+	return func(l *Limiter) {
+		l.perGroup = []rule{{count, period}}
+	}
+}
+
+// UseClock - time source. Default: real time.
+func UseClock(clock Clock) Option {
+	return func(l *Limiter) {
+		l.clock = clock
+	}
+}